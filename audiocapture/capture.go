@@ -1,25 +1,125 @@
-// Package audiocapture provides system audio capture.
+// Package audiocapture provides system audio and microphone capture.
 //
-// On macOS, it uses ScreenCaptureKit to capture system audio.
+// On macOS, it uses ScreenCaptureKit to capture system audio and
+// AVAudioEngine to capture the microphone.
 // Other platforms return ErrUnsupported.
 package audiocapture
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MinOSVersion is the oldest macOS release with ScreenCaptureKit audio.
+const MinOSVersion = "12.3"
 
 // Sentinel errors.
 var (
-	ErrUnsupported = errors.New("audiocapture: unsupported platform")
-	ErrRunning     = errors.New("audiocapture: already running")
-	ErrStopped     = errors.New("audiocapture: not running")
+	ErrUnsupported          = errors.New("audiocapture: unsupported platform")
+	ErrUnsupportedOSVersion = errors.New("audiocapture: macOS " + MinOSVersion + " or later required")
+	ErrRunning              = errors.New("audiocapture: already running")
+	ErrStopped              = errors.New("audiocapture: not running")
+	ErrDeviceNotFound       = errors.New("audiocapture: input device not found")
+)
+
+// Result codes of the native start routines.
+const (
+	// codeUnsupportedOSVersion means ScreenCaptureKit is unavailable on
+	// the running system.
+	codeUnsupportedOSVersion = -100
+
+	// codeDeviceNotFound means Config.DeviceID names no connected device.
+	codeDeviceNotFound = -101
 )
 
+// startError maps a native start result code and message to an error.
+func startError(code int, msg string) error {
+	switch {
+	case code == 0:
+		return nil
+	case code == codeUnsupportedOSVersion:
+		return ErrUnsupportedOSVersion
+	case code == codeDeviceNotFound:
+		return ErrDeviceNotFound
+	case msg != "":
+		return errors.New(msg)
+	default:
+		return errors.New("audiocapture: unknown error")
+	}
+}
+
+// Source selects the audio a Capturer records.
+type Source string
+
+const (
+	SourceSystem     Source = "system"     // Audio played by other apps
+	SourceMicrophone Source = "microphone" // The default input device
+	SourceBoth       Source = "both"       // System audio and microphone mixed to mono
+)
+
+// UsesMicrophone reports whether s records a microphone, and so whether
+// Config.DeviceID applies. The empty source is SourceSystem.
+func (s Source) UsesMicrophone() bool {
+	return s == SourceMicrophone || s == SourceBoth
+}
+
+// Config configures a Capturer.
+type Config struct {
+	SampleRate int    // Output sample rate in Hz; default 16000
+	Source     Source // Default SourceSystem
+
+	// DeviceID is the ID from ListDevices of the microphone to record;
+	// empty uses the default input. System audio is always the mix of
+	// every output, as ScreenCaptureKit can't bind to one device.
+	DeviceID string
+}
+
+// Device is an audio input device.
+type Device struct {
+	ID   string `json:"id"` // Stable across reconnects and restarts
+	Name string `json:"name"`
+}
+
+// parseDeviceList parses the native device list: one "id\tname" line per
+// device. Lines without an ID are skipped.
+func parseDeviceList(s string) []Device {
+	devices := []Device{}
+	for line := range strings.Lines(s) {
+		id, name, _ := strings.Cut(strings.TrimRight(line, "\n"), "\t")
+		if id == "" {
+			continue
+		}
+		if name == "" {
+			name = id
+		}
+		devices = append(devices, Device{ID: id, Name: name})
+	}
+	return devices
+}
+
+// withDefaults fills zero fields and validates the source.
+func (c Config) withDefaults() (Config, error) {
+	if c.SampleRate <= 0 {
+		c.SampleRate = 16000
+	}
+	switch c.Source {
+	case "":
+		c.Source = SourceSystem
+	case SourceSystem, SourceMicrophone, SourceBoth:
+	default:
+		return c, fmt.Errorf("audiocapture: invalid source %q", c.Source)
+	}
+	return c, nil
+}
+
 // AudioHandler processes captured audio samples.
 // Samples are float32 in range [-1, 1] at the configured sample rate.
 // The handler is called from a platform-specific audio thread;
 // implementations should avoid blocking.
 type AudioHandler func(samples []float32)
 
-// Capturer captures system audio.
+// Capturer captures audio from its configured Source.
 type Capturer interface {
 	// Start begins audio capture. The handler receives audio samples
 	// until Stop is called. Returns ErrRunning if already capturing.
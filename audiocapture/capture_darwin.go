@@ -4,12 +4,16 @@ package audiocapture
 
 /*
 #cgo CFLAGS: -x objective-c -fobjc-arc -mmacosx-version-min=13.0
-#cgo LDFLAGS: -framework ScreenCaptureKit -framework CoreMedia -framework CoreAudio -framework Foundation -framework AVFoundation
+#cgo LDFLAGS: -framework ScreenCaptureKit -framework CoreMedia -framework CoreAudio -framework AudioToolbox -framework Foundation -framework AVFoundation
 
 #include <stdlib.h>
 
+extern int audioCaptureSupported(void);
 extern int startAudioCapture(int targetSampleRate, char** errOut);
 extern void stopAudioCapture(void);
+extern int startMicCapture(int targetSampleRate, const char* deviceID, char** errOut);
+extern void stopMicCapture(void);
+extern char* listInputDevices(char** errOut);
 */
 import "C"
 
@@ -19,46 +23,91 @@ import (
 	"unsafe"
 )
 
-// Global handler for CGO callback. Only one capture at a time.
+// Global sink for CGO callbacks. Only one capture at a time.
 var (
-	globalHandler   AudioHandler
-	globalHandlerMu sync.RWMutex
+	globalSink   func(src Source, samples []float32)
+	globalSinkMu sync.RWMutex
 )
 
+func setSink(sink func(Source, []float32)) {
+	globalSinkMu.Lock()
+	globalSink = sink
+	globalSinkMu.Unlock()
+}
+
 //export goAudioCallback
 func goAudioCallback(samples *C.float, count C.int) {
+	deliver(SourceSystem, samples, count)
+}
+
+//export goMicCallback
+func goMicCallback(samples *C.float, count C.int) {
+	deliver(SourceMicrophone, samples, count)
+}
+
+func deliver(src Source, samples *C.float, count C.int) {
 	n := int(count)
 	if n <= 0 {
 		return
 	}
 
-	globalHandlerMu.RLock()
-	h := globalHandler
-	globalHandlerMu.RUnlock()
+	globalSinkMu.RLock()
+	sink := globalSink
+	globalSinkMu.RUnlock()
 
-	if h == nil {
+	if sink == nil {
 		return
 	}
 
 	// Convert C array to Go slice without extra allocation.
 	// Safe because we process samples before this function returns.
 	goSamples := unsafe.Slice((*float32)(unsafe.Pointer(samples)), n)
-	h(goSamples)
+	sink(src, goSamples)
 }
 
-// capturer is the macOS implementation using ScreenCaptureKit.
+// capturer is the macOS implementation using ScreenCaptureKit for system
+// audio and AVAudioEngine for the microphone.
 type capturer struct {
 	sampleRate int
+	source     Source
+	deviceID   string
 	mu         sync.Mutex
 	running    bool
 }
 
-// New creates a Capturer for macOS.
+// New creates a system audio Capturer for macOS. It returns
+// ErrUnsupportedOSVersion on systems older than MinOSVersion.
 func New(sampleRate int) (Capturer, error) {
-	if sampleRate <= 0 {
-		sampleRate = 16000
+	return NewWithConfig(Config{SampleRate: sampleRate})
+}
+
+// NewWithConfig creates a Capturer for macOS recording cfg.Source. It
+// returns ErrUnsupportedOSVersion on systems older than MinOSVersion.
+func NewWithConfig(cfg Config) (Capturer, error) {
+	cfg, err := cfg.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	if C.audioCaptureSupported() == 0 {
+		return nil, ErrUnsupportedOSVersion
 	}
-	return &capturer{sampleRate: sampleRate}, nil
+	return &capturer{sampleRate: cfg.SampleRate, source: cfg.Source, deviceID: cfg.DeviceID}, nil
+}
+
+// ListDevices returns the connected audio input devices.
+func ListDevices() ([]Device, error) {
+	var errStr *C.char
+	list := C.listInputDevices(&errStr)
+	if list == nil {
+		msg := "audiocapture: list devices failed"
+		if errStr != nil {
+			msg = C.GoString(errStr)
+			C.free(unsafe.Pointer(errStr))
+		}
+		return nil, errors.New(msg)
+	}
+	defer C.free(unsafe.Pointer(list))
+	return parseDeviceList(C.GoString(list)), nil
 }
 
 func (c *capturer) Start(handler AudioHandler) error {
@@ -73,30 +122,59 @@ func (c *capturer) Start(handler AudioHandler) error {
 		return ErrRunning
 	}
 
-	// Set global handler before starting capture.
-	globalHandlerMu.Lock()
-	globalHandler = handler
-	globalHandlerMu.Unlock()
-
-	var errStr *C.char
-	result := C.startAudioCapture(C.int(c.sampleRate), &errStr)
-	if result != 0 {
-		globalHandlerMu.Lock()
-		globalHandler = nil
-		globalHandlerMu.Unlock()
+	// Set global sink before starting capture.
+	if c.source == SourceBoth {
+		setSink(newMixer(handler, c.sampleRate/4).push)
+	} else {
+		setSink(func(_ Source, samples []float32) { handler(samples) })
+	}
 
-		if errStr != nil {
-			err := errors.New(C.GoString(errStr))
-			C.free(unsafe.Pointer(errStr))
+	if c.source != SourceMicrophone {
+		err := startNative(func(errOut **C.char) C.int {
+			return C.startAudioCapture(C.int(c.sampleRate), errOut)
+		})
+		if err != nil {
+			setSink(nil)
+			return err
+		}
+	}
+	if c.source != SourceSystem {
+		var deviceID *C.char
+		if c.deviceID != "" {
+			deviceID = C.CString(c.deviceID)
+			defer C.free(unsafe.Pointer(deviceID))
+		}
+		err := startNative(func(errOut **C.char) C.int {
+			return C.startMicCapture(C.int(c.sampleRate), deviceID, errOut)
+		})
+		if err != nil {
+			if c.source == SourceBoth {
+				C.stopAudioCapture()
+			}
+			setSink(nil)
 			return err
 		}
-		return errors.New("audiocapture: unknown error")
 	}
 
 	c.running = true
 	return nil
 }
 
+// startNative calls a native start routine and converts its result.
+func startNative(start func(errOut **C.char) C.int) error {
+	var errStr *C.char
+	result := start(&errStr)
+	if result == 0 {
+		return nil
+	}
+	var msg string
+	if errStr != nil {
+		msg = C.GoString(errStr)
+		C.free(unsafe.Pointer(errStr))
+	}
+	return startError(int(result), msg)
+}
+
 func (c *capturer) Stop() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -105,11 +183,13 @@ func (c *capturer) Stop() error {
 		return nil
 	}
 
-	C.stopAudioCapture()
-
-	globalHandlerMu.Lock()
-	globalHandler = nil
-	globalHandlerMu.Unlock()
+	if c.source != SourceMicrophone {
+		C.stopAudioCapture()
+	}
+	if c.source != SourceSystem {
+		C.stopMicCapture()
+	}
+	setSink(nil)
 
 	c.running = false
 	return nil
@@ -1,13 +1,18 @@
 // capture_darwin.m - Objective-C implementation for ScreenCaptureKit audio capture
+// and AVAudioEngine microphone capture
 
 #import <ScreenCaptureKit/ScreenCaptureKit.h>
+#import <AVFoundation/AVFoundation.h>
+#import <AudioToolbox/AudioToolbox.h>
+#import <CoreAudio/CoreAudio.h>
 #import <CoreMedia/CoreMedia.h>
 #import <Foundation/Foundation.h>
 #include <stdlib.h>
 #include <string.h>
 
-// Forward declaration of Go callback
+// Forward declaration of Go callbacks
 extern void goAudioCallback(float* samples, int count);
+extern void goMicCallback(float* samples, int count);
 
 // Audio capture delegate
 API_AVAILABLE(macos(12.3))
@@ -93,6 +98,14 @@ static void setError(char** errOut, NSString* msg) {
     }
 }
 
+// Report whether ScreenCaptureKit audio capture is available
+int audioCaptureSupported(void) {
+    if (@available(macOS 12.3, *)) {
+        return 1;
+    }
+    return 0;
+}
+
 // Start audio capture
 int startAudioCapture(int targetSampleRate, char** errOut) {
     if (@available(macOS 12.3, *)) {
@@ -159,7 +172,7 @@ int startAudioCapture(int targetSampleRate, char** errOut) {
         return result;
     }
     setError(errOut, @"macOS 12.3 or later required");
-    return -1;
+    return -100;
 }
 
 // Stop audio capture
@@ -176,3 +189,200 @@ void stopAudioCapture(void) {
         }
     }
 }
+
+// Microphone state
+static AVAudioEngine* micEngine = nil;
+
+// Request microphone access, waiting for the user on first use.
+static BOOL micAccessGranted(void) {
+    switch ([AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio]) {
+    case AVAuthorizationStatusAuthorized:
+        return YES;
+    case AVAuthorizationStatusNotDetermined: {
+        dispatch_semaphore_t sem = dispatch_semaphore_create(0);
+        __block BOOL granted = NO;
+        [AVCaptureDevice requestAccessForMediaType:AVMediaTypeAudio completionHandler:^(BOOL ok) {
+            granted = ok;
+            dispatch_semaphore_signal(sem);
+        }];
+        dispatch_semaphore_wait(sem, DISPATCH_TIME_FOREVER);
+        return granted;
+    }
+    default:
+        return NO;
+    }
+}
+
+// Look up the device with the given UID, or kAudioObjectUnknown
+static AudioDeviceID deviceForUID(NSString* uid) {
+    AudioObjectPropertyAddress addr = {
+        kAudioHardwarePropertyTranslateUIDToDevice,
+        kAudioObjectPropertyScopeGlobal,
+        kAudioObjectPropertyElementMain,
+    };
+    CFStringRef cfUID = (__bridge CFStringRef)uid;
+    AudioDeviceID device = kAudioObjectUnknown;
+    UInt32 size = sizeof(device);
+    if (AudioObjectGetPropertyData(kAudioObjectSystemObject, &addr, sizeof(cfUID), &cfUID, &size, &device) != noErr) {
+        return kAudioObjectUnknown;
+    }
+    return device;
+}
+
+// Report whether device has input channels
+static BOOL deviceHasInput(AudioDeviceID device) {
+    AudioObjectPropertyAddress addr = {
+        kAudioDevicePropertyStreamConfiguration,
+        kAudioObjectPropertyScopeInput,
+        kAudioObjectPropertyElementMain,
+    };
+    UInt32 size = 0;
+    if (AudioObjectGetPropertyDataSize(device, &addr, 0, NULL, &size) != noErr || size == 0) {
+        return NO;
+    }
+    AudioBufferList* buffers = (AudioBufferList*)malloc(size);
+    BOOL hasInput = NO;
+    if (AudioObjectGetPropertyData(device, &addr, 0, NULL, &size, buffers) == noErr) {
+        for (UInt32 i = 0; i < buffers->mNumberBuffers; i++) {
+            if (buffers->mBuffers[i].mNumberChannels > 0) {
+                hasInput = YES;
+                break;
+            }
+        }
+    }
+    free(buffers);
+    return hasInput;
+}
+
+// Read a string property of device, or nil
+static NSString* deviceString(AudioDeviceID device, AudioObjectPropertySelector selector) {
+    AudioObjectPropertyAddress addr = {
+        selector,
+        kAudioObjectPropertyScopeGlobal,
+        kAudioObjectPropertyElementMain,
+    };
+    CFStringRef value = NULL;
+    UInt32 size = sizeof(value);
+    if (AudioObjectGetPropertyData(device, &addr, 0, NULL, &size, &value) != noErr || value == NULL) {
+        return nil;
+    }
+    return (__bridge_transfer NSString*)value;
+}
+
+// List input devices as "uid\tname" lines. The caller frees the result.
+char* listInputDevices(char** errOut) {
+    AudioObjectPropertyAddress addr = {
+        kAudioHardwarePropertyDevices,
+        kAudioObjectPropertyScopeGlobal,
+        kAudioObjectPropertyElementMain,
+    };
+    UInt32 size = 0;
+    if (AudioObjectGetPropertyDataSize(kAudioObjectSystemObject, &addr, 0, NULL, &size) != noErr) {
+        setError(errOut, @"failed to query audio devices");
+        return NULL;
+    }
+    UInt32 count = size / sizeof(AudioDeviceID);
+    AudioDeviceID* devices = (AudioDeviceID*)malloc(size);
+    if (AudioObjectGetPropertyData(kAudioObjectSystemObject, &addr, 0, NULL, &size, devices) != noErr) {
+        free(devices);
+        setError(errOut, @"failed to query audio devices");
+        return NULL;
+    }
+
+    NSMutableString* list = [NSMutableString string];
+    for (UInt32 i = 0; i < count; i++) {
+        if (!deviceHasInput(devices[i])) {
+            continue;
+        }
+        NSString* uid = deviceString(devices[i], kAudioDevicePropertyDeviceUID);
+        if (uid == nil) {
+            continue;
+        }
+        NSString* name = deviceString(devices[i], kAudioObjectPropertyName) ?: uid;
+        // Tabs and newlines separate fields and devices
+        name = [[name componentsSeparatedByCharactersInSet:[NSCharacterSet controlCharacterSet]] componentsJoinedByString:@" "];
+        [list appendFormat:@"%@\t%@\n", uid, name];
+    }
+    free(devices);
+    return strdup([list UTF8String]);
+}
+
+// Start microphone capture, delivering mono float32 at targetSampleRate.
+// deviceID is a device UID from listInputDevices, or NULL for the default.
+int startMicCapture(int targetSampleRate, const char* deviceID, char** errOut) {
+    if (!micAccessGranted()) {
+        setError(errOut, @"microphone permission required");
+        return -1;
+    }
+
+    AVAudioEngine* engine = [[AVAudioEngine alloc] init];
+    AVAudioInputNode* input = engine.inputNode;
+    if (deviceID != NULL) {
+        AudioDeviceID device = deviceForUID([NSString stringWithUTF8String:deviceID]);
+        if (device == kAudioObjectUnknown) {
+            setError(errOut, @"input device not found");
+            return -101;
+        }
+        OSStatus status = AudioUnitSetProperty(input.audioUnit, kAudioOutputUnitProperty_CurrentDevice,
+                                               kAudioUnitScope_Global, 0, &device, sizeof(device));
+        if (status != noErr) {
+            setError(errOut, [NSString stringWithFormat:@"failed to select input device: %d", (int)status]);
+            return -1;
+        }
+    }
+    AVAudioFormat* inFormat = [input outputFormatForBus:0];
+    if (inFormat.sampleRate == 0 || inFormat.channelCount == 0) {
+        setError(errOut, @"no microphone available");
+        return -1;
+    }
+
+    AVAudioFormat* outFormat = [[AVAudioFormat alloc] initWithCommonFormat:AVAudioPCMFormatFloat32
+                                                                sampleRate:targetSampleRate
+                                                                  channels:1
+                                                               interleaved:NO];
+    AVAudioConverter* converter = [[AVAudioConverter alloc] initFromFormat:inFormat toFormat:outFormat];
+    if (converter == nil) {
+        setError(errOut, @"unsupported microphone format");
+        return -1;
+    }
+    converter.downmix = YES;
+
+    double ratio = targetSampleRate / inFormat.sampleRate;
+    [input installTapOnBus:0 bufferSize:4096 format:inFormat block:^(AVAudioPCMBuffer* buffer, AVAudioTime* when) {
+        AVAudioFrameCount capacity = (AVAudioFrameCount)(buffer.frameLength * ratio) + 1;
+        AVAudioPCMBuffer* out = [[AVAudioPCMBuffer alloc] initWithPCMFormat:outFormat frameCapacity:capacity];
+        __block BOOL supplied = NO;
+        NSError* convErr = nil;
+        [converter convertToBuffer:out error:&convErr withInputFromBlock:^AVAudioBuffer*(AVAudioPacketCount count, AVAudioConverterInputStatus* status) {
+            if (supplied) {
+                *status = AVAudioConverterInputStatus_NoDataNow;
+                return nil;
+            }
+            supplied = YES;
+            *status = AVAudioConverterInputStatus_HaveData;
+            return buffer;
+        }];
+        if (convErr == nil && out.frameLength > 0) {
+            goMicCallback(out.floatChannelData[0], (int)out.frameLength);
+        }
+    }];
+
+    [engine prepare];
+    NSError* startErr = nil;
+    if (![engine startAndReturnError:&startErr]) {
+        [input removeTapOnBus:0];
+        setError(errOut, [NSString stringWithFormat:@"failed to start microphone: %@", startErr.localizedDescription]);
+        return -1;
+    }
+    micEngine = engine;
+    return 0;
+}
+
+// Stop microphone capture
+void stopMicCapture(void) {
+    if (micEngine != nil) {
+        [micEngine.inputNode removeTapOnBus:0];
+        [micEngine stop];
+        micEngine = nil;
+    }
+}
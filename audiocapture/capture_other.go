@@ -6,3 +6,16 @@ package audiocapture
 func New(sampleRate int) (Capturer, error) {
 	return nil, ErrUnsupported
 }
+
+// NewWithConfig returns ErrUnsupported on non-macOS platforms.
+func NewWithConfig(cfg Config) (Capturer, error) {
+	if _, err := cfg.withDefaults(); err != nil {
+		return nil, err
+	}
+	return nil, ErrUnsupported
+}
+
+// ListDevices returns ErrUnsupported on non-macOS platforms.
+func ListDevices() ([]Device, error) {
+	return nil, ErrUnsupported
+}
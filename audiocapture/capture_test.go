@@ -3,6 +3,7 @@ package audiocapture
 import (
 	"errors"
 	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -99,3 +100,102 @@ func TestStopIdempotent(t *testing.T) {
 		t.Fatalf("double Stop: %v", err)
 	}
 }
+
+func TestStartError(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		msg     string
+		wantNil bool
+		wantIs  error
+		wantMsg string
+	}{
+		{"ok", 0, "", true, nil, ""},
+		{"unsupported_os", codeUnsupportedOSVersion, "macOS 12.3 or later required", false, ErrUnsupportedOSVersion, ""},
+		{"device_not_found", codeDeviceNotFound, "input device not found", false, ErrDeviceNotFound, ""},
+		{"native_message", -1, "no displays available", false, nil, "no displays available"},
+		{"unknown", -1, "", false, nil, "audiocapture: unknown error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := startError(tt.code, tt.msg)
+			if tt.wantNil {
+				if err != nil {
+					t.Fatalf("startError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("startError() = nil, want error")
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("startError() = %v, want %v", err, tt.wantIs)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("startError() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestListDevices(t *testing.T) {
+	if runtime.GOOS != "darwin" {
+		t.Skip("skipping on non-darwin")
+	}
+
+	devices, err := ListDevices()
+	if err != nil {
+		t.Fatalf("ListDevices: %v", err)
+	}
+	if devices == nil {
+		t.Fatal("expected non-nil device list")
+	}
+	for _, d := range devices {
+		if d.ID == "" || d.Name == "" {
+			t.Errorf("device %+v missing ID or name", d)
+		}
+	}
+}
+
+func TestParseDeviceList(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want []Device
+	}{
+		{"empty", "", []Device{}},
+		{
+			"devices",
+			"BuiltInMicrophoneDevice\tMacBook Pro Microphone\nAppleUSBAudioEngine:1\tUSB Headset\n",
+			[]Device{{"BuiltInMicrophoneDevice", "MacBook Pro Microphone"}, {"AppleUSBAudioEngine:1", "USB Headset"}},
+		},
+		{"missing name", "uid-1\n", []Device{{"uid-1", "uid-1"}}},
+		{"blank line", "\nuid-1\tMic", []Device{{"uid-1", "Mic"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDeviceList(tt.list); !slices.Equal(got, tt.want) {
+				t.Errorf("parseDeviceList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceUsesMicrophone(t *testing.T) {
+	tests := []struct {
+		source Source
+		want   bool
+	}{
+		{"", false},
+		{SourceSystem, false},
+		{SourceMicrophone, true},
+		{SourceBoth, true},
+	}
+	for _, tt := range tests {
+		if got := tt.source.UsesMicrophone(); got != tt.want {
+			t.Errorf("Source(%q).UsesMicrophone() = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package audiocapture
+
+import "sync"
+
+// mixer combines the system and microphone streams of SourceBoth. The two
+// arrive on different threads in differently sized chunks, so each is
+// buffered until the other catches up. A stream more than maxLag samples
+// behind, e.g. system audio while nothing plays, is treated as silence so
+// it can't hold back the other.
+type mixer struct {
+	mu      sync.Mutex
+	handler AudioHandler
+	maxLag  int
+	pending [2][]float32 // System, microphone
+	out     []float32
+}
+
+func newMixer(handler AudioHandler, maxLag int) *mixer {
+	return &mixer{handler: handler, maxLag: maxLag}
+}
+
+// push adds samples from src and passes any mixed samples to the handler.
+func (m *mixer) push(src Source, samples []float32) {
+	i := 0
+	if src == SourceMicrophone {
+		i = 1
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pending[i] = append(m.pending[i], samples...)
+	sys, mic := m.pending[0], m.pending[1]
+	n := min(len(sys), len(mic))
+	if lead := max(len(sys), len(mic)); lead-n > m.maxLag {
+		n = lead - m.maxLag
+	}
+	if n == 0 {
+		return
+	}
+
+	m.out = mixSamples(m.out, sys[:min(n, len(sys))], mic[:min(n, len(mic))])
+	m.pending[0] = consume(sys, n)
+	m.pending[1] = consume(mic, n)
+	m.handler(m.out)
+}
+
+// consume drops the first n samples of buf, reusing its storage.
+func consume(buf []float32, n int) []float32 {
+	if n >= len(buf) {
+		return buf[:0]
+	}
+	return buf[:copy(buf, buf[n:])]
+}
+
+// mixSamples sums a and b into dst, reallocating it as needed, and returns
+// it. The shorter slice is padded with silence; sums are clipped to [-1, 1].
+func mixSamples(dst, a, b []float32) []float32 {
+	n := max(len(a), len(b))
+	if cap(dst) < n {
+		dst = make([]float32, n)
+	}
+	dst = dst[:n]
+	for i := range dst {
+		var v float32
+		if i < len(a) {
+			v += a[i]
+		}
+		if i < len(b) {
+			v += b[i]
+		}
+		dst[i] = min(max(v, -1), 1)
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+package audiocapture
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMixSamples(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want []float32
+	}{
+		{"equal length", []float32{0.1, -0.2, 0.3}, []float32{0.2, 0.2, -0.1}, []float32{0.3, 0, 0.2}},
+		{"b shorter", []float32{0.5, 0.5}, []float32{0.25}, []float32{0.75, 0.5}},
+		{"a empty", nil, []float32{0.1, 0.2}, []float32{0.1, 0.2}},
+		{"clipped", []float32{0.8, -0.9}, []float32{0.7, -0.6}, []float32{1, -1}},
+		{"both empty", nil, nil, []float32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mixSamples(nil, tt.a, tt.b); !approxEqual(got, tt.want) {
+				t.Errorf("mixSamples() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMixer(t *testing.T) {
+	var got []float32
+	m := newMixer(func(samples []float32) { got = append(got, samples...) }, 4)
+
+	// Nothing is mixed until both streams have samples.
+	m.push(SourceSystem, []float32{0.1, 0.1, 0.1})
+	if len(got) != 0 {
+		t.Fatalf("mixed %v with only system audio", got)
+	}
+	m.push(SourceMicrophone, []float32{0.2, 0.2})
+	if want := []float32{0.3, 0.3}; !approxEqual(got, want) {
+		t.Fatalf("after both streams = %v, want %v", got, want)
+	}
+
+	// A silent system stream can't hold back the microphone beyond maxLag:
+	// one system sample is pending, so 6 mic samples mix it and pass 1.
+	got = nil
+	m.push(SourceMicrophone, []float32{0.5, 0.5, 0.5, 0.5, 0.5, 0.5})
+	if want := []float32{0.6, 0.5}; !approxEqual(got, want) {
+		t.Fatalf("lagging system stream = %v, want %v", got, want)
+	}
+}
+
+func approxEqual(a, b []float32) bool {
+	return slices.EqualFunc(a, b, func(x, y float32) bool {
+		d := x - y
+		return d < 1e-6 && d > -1e-6
+	})
+}
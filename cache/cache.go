@@ -2,12 +2,17 @@
 package cache
 
 import (
+	"cmp"
+	"container/list"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -32,10 +37,23 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
-// Stats holds cache statistics.
+// DefaultMaxBytes is a size limit suitable for the app's translation cache.
+const DefaultMaxBytes = 64 << 20 // 64 MiB
+
+// Options limits the cache size. When a Set exceeds a limit, the least
+// recently used entries are evicted. Zero values mean unlimited.
+type Options struct {
+	MaxEntries int
+	MaxBytes   int64 // Keys plus encoded entries
+}
+
+// Stats holds cache statistics. Entries and Bytes may include entries
+// that expired since they were last read.
 type Stats struct {
-	Hits   uint64 `json:"hits"`
-	Misses uint64 `json:"misses"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
 }
 
 // HitRate returns the cache hit rate as a percentage.
@@ -50,21 +68,44 @@ func (s Stats) HitRate() float64 {
 // Cache wraps BadgerDB for LLM response caching.
 type Cache struct {
 	db     *badger.DB
+	opts   Options
 	hits   atomic.Uint64
 	misses atomic.Uint64
+
+	// mu guards the recency index and serializes Set, so an eviction
+	// can't delete an entry a concurrent Set just wrote.
+	mu    sync.Mutex
+	order *list.List // *lruItem, most recently used first
+	items map[string]*list.Element
+	bytes int64
 }
 
-// New creates a new cache at the given path.
-func New(path string) (*Cache, error) {
-	opts := badger.DefaultOptions(path)
-	opts.Logger = nil // Disable BadgerDB internal logging
+// lruItem is a cached key and the bytes it occupies.
+type lruItem struct {
+	key  string
+	size int64
+}
+
+// New creates a new cache at the given path, limited by opts.
+func New(path string, opts Options) (*Cache, error) {
+	bopts := badger.DefaultOptions(path)
+	bopts.Logger = nil // Disable BadgerDB internal logging
 
-	db, err := badger.Open(opts)
+	db, err := badger.Open(bopts)
 	if err != nil {
 		return nil, fmt.Errorf("open badger: %w", err)
 	}
 
-	c := &Cache{db: db}
+	c := &Cache{
+		db:    db,
+		opts:  opts,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+	if err := c.loadIndex(); err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	// Start background GC goroutine
 	go c.runGC()
@@ -85,6 +126,74 @@ func (c *Cache) runGC() {
 	}
 }
 
+// loadIndex builds the recency index from the stored entries. Access
+// times aren't persisted, so entries are ordered by when they were written.
+func (c *Cache) loadIndex() error {
+	type stored struct {
+		item    lruItem
+		version uint64
+	}
+	var all []stored
+	err := c.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.IteratorOptions{})
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			all = append(all, stored{
+				item:    lruItem{key: string(item.KeyCopy(nil)), size: item.KeySize() + item.ValueSize()},
+				version: item.Version(),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("load cache index: %w", err)
+	}
+
+	slices.SortFunc(all, func(a, b stored) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	for _, s := range all {
+		it := s.item
+		c.items[it.key] = c.order.PushFront(&it)
+		c.bytes += it.size
+	}
+	return c.evict()
+}
+
+// overLimit reports whether the cache holds more than its options allow.
+func (c *Cache) overLimit() bool {
+	return (c.opts.MaxEntries > 0 && c.order.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes)
+}
+
+// evict deletes least recently used entries until the cache is within its
+// limits. The most recently used entry is always kept. c.mu must be held.
+func (c *Cache) evict() error {
+	var victims []string
+	for c.overLimit() && c.order.Len() > 1 {
+		it := c.order.Remove(c.order.Back()).(*lruItem)
+		delete(c.items, it.key)
+		c.bytes -= it.size
+		victims = append(victims, it.key)
+	}
+	if len(victims) == 0 {
+		return nil
+	}
+
+	wb := c.db.NewWriteBatch()
+	defer wb.Cancel()
+	for _, key := range victims {
+		if err := wb.Delete([]byte(key)); err != nil {
+			return fmt.Errorf("evict cache entry: %w", err)
+		}
+	}
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("evict cache entries: %w", err)
+	}
+	return nil
+}
+
 // whitespaceRe matches one or more whitespace characters.
 var whitespaceRe = regexp.MustCompile(`\s+`)
 
@@ -137,13 +246,41 @@ func (c *Cache) Get(key string) (*Entry, bool) {
 
 	if err != nil {
 		c.misses.Add(1)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			c.forget(key) // Expired
+		}
 		return nil, false
 	}
 
 	c.hits.Add(1)
+	c.touch(key)
 	return &entry, true
 }
 
+// touch marks key as the most recently used entry.
+func (c *Cache) touch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+	}
+}
+
+// forget drops key from the recency index.
+func (c *Cache) forget(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unindex(key)
+}
+
+// unindex removes key from the recency index. c.mu must be held.
+func (c *Cache) unindex(key string) {
+	if el, ok := c.items[key]; ok {
+		c.bytes -= c.order.Remove(el).(*lruItem).size
+		delete(c.items, key)
+	}
+}
+
 // Set stores an entry in the cache with the given TTL.
 func (c *Cache) Set(key string, entry *Entry, ttl time.Duration) error {
 	if ttl == 0 {
@@ -155,17 +292,70 @@ func (c *Cache) Set(key string, entry *Entry, ttl time.Duration) error {
 		return fmt.Errorf("marshal entry: %w", err)
 	}
 
-	return c.db.Update(func(txn *badger.Txn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err = c.db.Update(func(txn *badger.Txn) error {
 		e := badger.NewEntry([]byte(key), data).WithTTL(ttl)
 		return txn.SetEntry(e)
 	})
+	if err != nil {
+		return err
+	}
+
+	size := int64(len(key) + len(data))
+	if el, ok := c.items[key]; ok {
+		it := el.Value.(*lruItem)
+		c.bytes += size - it.size
+		it.size = size
+		c.order.MoveToFront(el)
+	} else {
+		c.items[key] = c.order.PushFront(&lruItem{key: key, size: size})
+		c.bytes += size
+	}
+	return c.evict()
+}
+
+// Delete removes the entry for key. Deleting a missing key is not an error.
+func (c *Cache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("delete cache entry: %w", err)
+	}
+	c.unindex(key)
+	return nil
+}
+
+// Clear removes every entry from disk and returns how many there were.
+// Hit and miss counts are kept.
+func (c *Cache) Clear() (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.db.DropAll(); err != nil {
+		return 0, fmt.Errorf("clear cache: %w", err)
+	}
+	n := c.order.Len()
+	c.order.Init()
+	clear(c.items)
+	c.bytes = 0
+	return n, nil
 }
 
 // Stats returns current cache statistics.
 func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return Stats{
-		Hits:   c.hits.Load(),
-		Misses: c.misses.Load(),
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: c.order.Len(),
+		Bytes:   c.bytes,
 	}
 }
 
@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -15,7 +18,7 @@ func TestCache(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	c, err := New(filepath.Join(tmpDir, "cache"))
+	c, err := New(filepath.Join(tmpDir, "cache"), Options{})
 	if err != nil {
 		t.Fatalf("new cache: %v", err)
 	}
@@ -182,7 +185,7 @@ func TestStats(t *testing.T) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	c, err := New(filepath.Join(tmpDir, "cache"))
+	c, err := New(filepath.Join(tmpDir, "cache"), Options{})
 	if err != nil {
 		t.Fatalf("new cache: %v", err)
 	}
@@ -220,3 +223,177 @@ func TestStats(t *testing.T) {
 		t.Errorf("hit rate = %.2f%%, want %.2f%%", rate, expected)
 	}
 }
+
+func TestEviction(t *testing.T) {
+	entry := &Entry{Text: "translation"}
+	size := func(key string) int64 {
+		data, _ := json.Marshal(entry)
+		return int64(len(key) + len(data))
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "max entries", opts: Options{MaxEntries: 3}},
+		{name: "max bytes", opts: Options{MaxBytes: 3 * size("k1")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(t.TempDir(), tt.opts)
+			if err != nil {
+				t.Fatalf("new cache: %v", err)
+			}
+			defer c.Close()
+
+			for _, key := range []string{"k1", "k2", "k3"} {
+				if err := c.Set(key, entry, DefaultTTL); err != nil {
+					t.Fatalf("Set(%q) error = %v", key, err)
+				}
+			}
+			// Reading k1 makes k2 the least recently used.
+			if _, ok := c.Get("k1"); !ok {
+				t.Fatal("Get(k1) missed before eviction")
+			}
+			if err := c.Set("k4", entry, DefaultTTL); err != nil {
+				t.Fatalf("Set(k4) error = %v", err)
+			}
+
+			if _, ok := c.Get("k2"); ok {
+				t.Error("least recently used k2 was not evicted")
+			}
+			for _, key := range []string{"k1", "k3", "k4"} {
+				if _, ok := c.Get(key); !ok {
+					t.Errorf("Get(%q) missed, want it kept", key)
+				}
+			}
+			stats := c.Stats()
+			if stats.Entries != 3 || stats.Bytes != 3*size("k1") {
+				t.Errorf("Stats() = %+v, want 3 entries of %d bytes", stats, 3*size("k1"))
+			}
+		})
+	}
+}
+
+func TestEvictionOnReopen(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, Options{})
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	for _, key := range []string{"old", "mid", "new"} {
+		if err := c.Set(key, &Entry{Text: key}, DefaultTTL); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+	c.Close()
+
+	// Reopening with a lower limit drops the oldest writes.
+	c, err = New(dir, Options{MaxEntries: 2})
+	if err != nil {
+		t.Fatalf("reopen cache: %v", err)
+	}
+	defer c.Close()
+	if _, ok := c.Get("old"); ok {
+		t.Error("oldest entry survived reopening with a lower limit")
+	}
+	if got := c.Stats().Entries; got != 2 {
+		t.Errorf("Stats().Entries = %d, want 2", got)
+	}
+}
+
+func TestConcurrentGetSet(t *testing.T) {
+	c, err := New(t.TempDir(), Options{MaxEntries: 8})
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				key := fmt.Sprintf("k%d", (w*50+i)%20)
+				if err := c.Set(key, &Entry{Text: key}, DefaultTTL); err != nil {
+					t.Errorf("Set(%q) error = %v", key, err)
+				}
+				c.Get(fmt.Sprintf("k%d", i%20))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := c.Stats().Entries; got > 8 {
+		t.Errorf("Stats().Entries = %d, want at most 8", got)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c, err := New(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	defer c.Close()
+
+	for _, key := range []string{"a", "b"} {
+		if err := c.Set(key, &Entry{Text: key}, DefaultTTL); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+	if err := c.Delete("a"); err != nil {
+		t.Fatalf("Delete(a) error = %v", err)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) hit after Delete")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("Get(b) missed, want it kept")
+	}
+	if err := c.Delete("missing"); err != nil {
+		t.Errorf("Delete(missing) error = %v", err)
+	}
+	if got := c.Stats().Entries; got != 1 {
+		t.Errorf("Stats().Entries = %d, want 1", got)
+	}
+}
+
+func TestClear(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, Options{})
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(key, &Entry{Text: key}, DefaultTTL); err != nil {
+			t.Fatalf("Set(%q) error = %v", key, err)
+		}
+	}
+
+	n, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Clear() = %d, want 3", n)
+	}
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats() after Clear = %+v, want no entries", stats)
+	}
+	c.Close()
+
+	// Entries are gone from disk, not just from memory.
+	c, err = New(dir, Options{})
+	if err != nil {
+		t.Fatalf("reopen cache: %v", err)
+	}
+	defer c.Close()
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) hit after Clear and reopen")
+	}
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("Stats().Entries after reopen = %d, want 0", got)
+	}
+}
@@ -16,3 +16,13 @@ func GetText(app *application.App) (string, error) {
 	}
 	return text, nil
 }
+
+func SetText(app *application.App, text string) error {
+	if app == nil {
+		return errors.New("app is nil")
+	}
+	if !app.Clipboard.SetText(text) {
+		return errors.New("failed to set clipboard content")
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"go.aimuz.me/transy/internal/types"
@@ -28,9 +29,60 @@ type Config struct {
 	Credentials         []types.APICredential      `json:"credentials,omitempty"`
 	TranslationProfiles []types.TranslationProfile `json:"translation_profiles,omitempty"`
 	SpeechConfig        *types.SpeechConfig        `json:"speech_config,omitempty"`
+	LocalServer         *types.LocalServerConfig   `json:"local_server,omitempty"`
+	Presets             []Preset                   `json:"presets,omitempty"`
+	Glossaries          []Glossary                 `json:"glossaries,omitempty"`
+	ActivePresetID      string                     `json:"active_preset_id,omitempty"`
+
+	// LanguagePresets hold named sets of default targets; the active one
+	// is mirrored in DefaultLanguages.
+	LanguagePresets        []LanguagePreset `json:"language_presets,omitempty"`
+	ActiveLanguagePresetID string           `json:"active_language_preset_id,omitempty"`
 
 	// Shared settings
-	DefaultLanguages map[string]string `json:"default_languages"`
+	DefaultLanguages map[string]string   `json:"default_languages"`
+	QuickLanguages   []string            `json:"quick_languages,omitempty"` // Shortlist shown atop language pickers
+	IncrementalOCR   bool                `json:"incremental_ocr,omitempty"` // Only emit lines not seen in recent captures
+	OCRHotkeyMode    string              `json:"ocr_hotkey_mode,omitempty"` // OCR hotkey presses during a capture: "ignore" (default) or "queue"
+	OCRLanguages     []string            `json:"ocr_languages,omitempty"`   // OCR recognition languages, most preferred first; empty uses the system language and English
+	AutoCopyStyle    string              `json:"auto_copy_style,omitempty"` // Copy source+translation after translating; empty disables
+	SkipStartupCheck bool                `json:"skip_startup_check,omitempty"`
+	TranslateOnPaste *bool               `json:"translate_on_paste,omitempty"` // Auto-translate text shown via hotkey; nil means true
+	PostProcessors   []string            `json:"post_processors,omitempty"`    // Built-in transforms applied to translations, in order
+	EchoLabels       map[string][]string `json:"echo_labels,omitempty"`        // Extra output labels removed by strip_echo, by language
+	AlwaysOnTop      bool                `json:"always_on_top,omitempty"`      // Keep the window pinned above other apps, even when unfocused
+	ShowWithoutFocus bool                `json:"show_without_focus,omitempty"` // Show the window without activating it
+	PasteBack        bool                `json:"paste_back,omitempty"`         // Paste clipboard translations into the focused app
+	EstimateUsage    bool                `json:"estimate_usage,omitempty"`     // Estimate token usage a provider doesn't report
+
+	// ModelPrices overrides the built-in price list used for cost
+	// estimates, keyed by model name prefix, e.g. "gpt-4o" or a custom
+	// model served through an OpenAI-compatible endpoint.
+	ModelPrices map[string]types.ModelPrice `json:"model_prices,omitempty"`
+
+	// PreferredTargetLang, when set, is the default target for every
+	// detected source except itself; text already in it goes to
+	// SecondaryTargetLang, or DefaultLanguages if that is empty.
+	PreferredTargetLang string `json:"preferred_target_lang,omitempty"`
+	SecondaryTargetLang string `json:"secondary_target_lang,omitempty"`
+
+	// TargetFromLocale uses the system language, instead of English, as the
+	// default target for sources without a DefaultLanguages mapping.
+	TargetFromLocale bool `json:"target_from_locale,omitempty"`
+
+	// OfflineMode refuses every provider that would send data off the
+	// machine: only credentials with a loopback base URL and local live
+	// providers are used.
+	OfflineMode bool `json:"offline_mode,omitempty"`
+
+	// AllowInsecureEndpoints permits http:// base URLs on remote hosts.
+	// Loopback hosts (local models, proxies) are always allowed.
+	AllowInsecureEndpoints bool `json:"allow_insecure_endpoints,omitempty"`
+
+	// Proxy routes outbound API requests through an http, https or socks5
+	// proxy, e.g. "http://proxy.corp:3128". Empty uses HTTP_PROXY and
+	// HTTPS_PROXY from the environment.
+	Proxy string `json:"proxy,omitempty"`
 }
 
 // Load loads configuration from the config file.
@@ -63,6 +115,7 @@ func Load() (*Config, error) {
 	if cfg.DefaultLanguages == nil {
 		cfg.DefaultLanguages = defaultLanguages()
 	}
+	cfg.migrateLanguagePresets()
 
 	// Migrate from legacy Provider format to new Credential + Profile format
 	if err := cfg.migrateToNewFormat(); err != nil {
@@ -211,13 +264,15 @@ func validateProvider(p types.Provider) error {
 	if p.Type == "openai-compatible" && p.BaseURL == "" {
 		return fmt.Errorf("base url required for openai-compatible")
 	}
+	if p.Type == "azure-openai" && p.BaseURL == "" {
+		return fmt.Errorf("base url required for azure-openai")
+	}
 	return nil
 }
 
+// applyDefaults fills in unset provider fields. MaxTokens stays zero so
+// the migrated profile sizes it per request.
 func applyDefaults(p *types.Provider) {
-	if p.MaxTokens == 0 {
-		p.MaxTokens = types.DefaultMaxTokens
-	}
 	if p.Temperature == 0 {
 		p.Temperature = types.DefaultTemperature
 	}
@@ -232,10 +287,12 @@ func configPath() (string, error) {
 }
 
 func defaultConfig() *Config {
-	return &Config{
+	cfg := &Config{
 		Providers:        []types.Provider{},
 		DefaultLanguages: defaultLanguages(),
 	}
+	cfg.migrateLanguagePresets()
+	return cfg
 }
 
 func defaultLanguages() map[string]string {
@@ -323,6 +380,13 @@ func (c *Config) migrateToNewFormat() error {
 			c.Credentials = append(c.Credentials, *cred)
 		}
 
+		// The legacy default was filled in rather than chosen; drop it so
+		// the budget is sized per request.
+		maxTokens := p.MaxTokens
+		if maxTokens == types.DefaultMaxTokens {
+			maxTokens = 0
+		}
+
 		// Create translation profile
 		profile := types.TranslationProfile{
 			ID:              uuid.New().String(),
@@ -330,7 +394,7 @@ func (c *Config) migrateToNewFormat() error {
 			CredentialID:    cred.ID,
 			Model:           p.Model,
 			SystemPrompt:    p.SystemPrompt,
-			MaxTokens:       p.MaxTokens,
+			MaxTokens:       maxTokens,
 			Temperature:     p.Temperature,
 			Active:          p.Active,
 			DisableThinking: p.DisableThinking,
@@ -375,6 +439,18 @@ func (c *Config) AddCredential(cred types.APICredential) error {
 	if cred.Type == "openai-compatible" && cred.BaseURL == "" {
 		return fmt.Errorf("base url required for openai-compatible")
 	}
+	if err := validateAzure(cred); err != nil {
+		return err
+	}
+	if err := validateOpenAIScope(cred); err != nil {
+		return err
+	}
+	if err := checkCredentialSwap(cred); err != nil {
+		return err
+	}
+	if err := c.checkEndpoint(cred.BaseURL); err != nil {
+		return err
+	}
 
 	if cred.ID == "" {
 		cred.ID = uuid.New().String()
@@ -392,12 +468,48 @@ func (c *Config) UpdateCredential(id string, cred types.APICredential) error {
 	if idx == -1 {
 		return fmt.Errorf("credential not found: %s", id)
 	}
+	if err := validateAzure(cred); err != nil {
+		return err
+	}
+	if err := validateOpenAIScope(cred); err != nil {
+		return err
+	}
+	if err := checkCredentialSwap(cred); err != nil {
+		return err
+	}
+	if err := c.checkEndpoint(cred.BaseURL); err != nil {
+		return err
+	}
 
 	cred.ID = id // Preserve ID
 	c.Credentials[idx] = cred
 	return c.Save()
 }
 
+// validateAzure requires the resource URL and API version of azure-openai
+// credentials. The deployment is the model of each profile using it.
+func validateAzure(cred types.APICredential) error {
+	if cred.Type != "azure-openai" {
+		return nil
+	}
+	if cred.BaseURL == "" {
+		return fmt.Errorf("base url required for azure-openai")
+	}
+	if cred.APIVersion == "" {
+		return fmt.Errorf("api version required for azure-openai")
+	}
+	return nil
+}
+
+// validateOpenAIScope rejects organization/project IDs on credentials
+// other than OpenAI, where the headers have no meaning.
+func validateOpenAIScope(cred types.APICredential) error {
+	if (cred.OrgID != "" || cred.ProjectID != "") && cred.Type != "openai" {
+		return fmt.Errorf("organization and project ids require an openai credential")
+	}
+	return nil
+}
+
 // RemoveCredential removes a credential by ID.
 // Returns error if credential is in use by any profile or speech config.
 func (c *Config) RemoveCredential(id string) error {
@@ -432,6 +544,16 @@ func (c *Config) GetTranslationProfiles() []types.TranslationProfile {
 	return c.TranslationProfiles
 }
 
+// GetTranslationProfile returns the translation profile with id, or nil.
+func (c *Config) GetTranslationProfile(id string) *types.TranslationProfile {
+	for i := range c.TranslationProfiles {
+		if c.TranslationProfiles[i].ID == id {
+			return &c.TranslationProfiles[i]
+		}
+	}
+	return nil
+}
+
 // GetActiveTranslationProfile returns the currently active translation profile.
 func (c *Config) GetActiveTranslationProfile() *types.TranslationProfile {
 	for i := range c.TranslationProfiles {
@@ -459,6 +581,18 @@ func (c *Config) AddTranslationProfile(profile types.TranslationProfile) error {
 	if profile.Model == "" {
 		return fmt.Errorf("model required")
 	}
+	if err := checkProfileSwap(profile); err != nil {
+		return err
+	}
+	if _, err := ParseInstructionTemplate(profile.InstructionTemplate); err != nil {
+		return err
+	}
+	if err := checkProfileStyle(profile); err != nil {
+		return err
+	}
+	if err := c.checkGlossaryRef(profile.GlossaryID); err != nil {
+		return err
+	}
 
 	// Validate credential exists
 	if c.GetCredential(profile.CredentialID) == nil {
@@ -469,13 +603,11 @@ func (c *Config) AddTranslationProfile(profile types.TranslationProfile) error {
 		profile.ID = uuid.New().String()
 	}
 
-	// Apply defaults
-	if profile.MaxTokens == 0 {
-		profile.MaxTokens = types.DefaultMaxTokens
-	}
+	// Apply defaults. MaxTokens stays zero to size it per request.
 	if profile.Temperature == 0 {
 		profile.Temperature = types.DefaultTemperature
 	}
+	profile.SystemPrompt = defaultSystemPrompt(profile.SystemPrompt)
 
 	// First profile or explicitly active: deactivate others
 	if len(c.TranslationProfiles) == 0 || profile.Active {
@@ -502,6 +634,18 @@ func (c *Config) UpdateTranslationProfile(id string, profile types.TranslationPr
 	if c.GetCredential(profile.CredentialID) == nil {
 		return fmt.Errorf("credential not found: %s", profile.CredentialID)
 	}
+	if err := checkProfileSwap(profile); err != nil {
+		return err
+	}
+	if _, err := ParseInstructionTemplate(profile.InstructionTemplate); err != nil {
+		return err
+	}
+	if err := checkProfileStyle(profile); err != nil {
+		return err
+	}
+	if err := c.checkGlossaryRef(profile.GlossaryID); err != nil {
+		return err
+	}
 
 	wasActive := c.TranslationProfiles[idx].Active
 	if profile.Active && !wasActive {
@@ -576,6 +720,20 @@ func (c *Config) SetSpeechConfig(cfg types.SpeechConfig) error {
 		}
 	}
 
+	if n := utf8.RuneCountInString(cfg.Prompt); n > types.MaxSpeechPromptRunes {
+		return fmt.Errorf("speech prompt too long: %d characters, max %d", n, types.MaxSpeechPromptRunes)
+	}
+	switch cfg.AudioSource {
+	case "", "system", "microphone", "both":
+	default:
+		return fmt.Errorf("invalid audio source: %q", cfg.AudioSource)
+	}
+	if cfg.VAD != nil {
+		if err := validateVAD(*cfg.VAD); err != nil {
+			return err
+		}
+	}
+
 	// Default model
 	if cfg.Model == "" {
 		cfg.Model = "whisper-1"
@@ -585,6 +743,42 @@ func (c *Config) SetSpeechConfig(cfg types.SpeechConfig) error {
 	return c.Save()
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// Local Server
+// ─────────────────────────────────────────────────────────────────────────────
+
+// GetLocalServerConfig returns the local server configuration.
+func (c *Config) GetLocalServerConfig() *types.LocalServerConfig {
+	return c.LocalServer
+}
+
+// SetLocalServerConfig sets the local server configuration.
+// A missing port or token is filled in with a default or a random token.
+func (c *Config) SetLocalServerConfig(cfg types.LocalServerConfig) error {
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", cfg.Port)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = types.DefaultLocalServerPort
+	}
+	if cfg.Token == "" {
+		cfg.Token = uuid.New().String()
+	}
+
+	c.LocalServer = &cfg
+	return c.Save()
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Preferences
+// ─────────────────────────────────────────────────────────────────────────────
+
+// TranslateOnPasteEnabled reports whether text brought in via the hotkey
+// is translated immediately. Defaults to true when unset.
+func (c *Config) TranslateOnPasteEnabled() bool {
+	return c.TranslateOnPaste == nil || *c.TranslateOnPaste
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Compatibility: Build Provider from new format for existing code
 // ─────────────────────────────────────────────────────────────────────────────
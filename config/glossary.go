@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Glossary is a reusable set of terminology rules that translation profiles
+// reference by ID. Terms maps a source term to its required translation; a
+// term mapped to itself or to "" must be left untranslated.
+type Glossary struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Terms map[string]string `json:"terms"`
+}
+
+// GetGlossaries returns all glossaries.
+func (c *Config) GetGlossaries() []Glossary {
+	return c.Glossaries
+}
+
+// GetGlossary returns the glossary with the given ID, or nil.
+func (c *Config) GetGlossary(id string) *Glossary {
+	idx := c.glossaryIndex(id)
+	if idx == -1 {
+		return nil
+	}
+	return &c.Glossaries[idx]
+}
+
+// GlossaryTerms returns the terms of the glossary with the given ID, or nil
+// if id is empty or unknown.
+func (c *Config) GlossaryTerms(id string) map[string]string {
+	if id == "" {
+		return nil
+	}
+	if g := c.GetGlossary(id); g != nil {
+		return g.Terms
+	}
+	return nil
+}
+
+// AddGlossary adds a new glossary, assigning an ID if missing.
+func (c *Config) AddGlossary(g Glossary) error {
+	if err := validateGlossary(g); err != nil {
+		return err
+	}
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+
+	c.Glossaries = append(c.Glossaries, g)
+	return c.Save()
+}
+
+// UpdateGlossary replaces the glossary with the given ID.
+func (c *Config) UpdateGlossary(id string, g Glossary) error {
+	idx := c.glossaryIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("glossary not found: %s", id)
+	}
+	if err := validateGlossary(g); err != nil {
+		return err
+	}
+
+	g.ID = id // Preserve ID
+	c.Glossaries[idx] = g
+	return c.Save()
+}
+
+// RemoveGlossary removes a glossary by ID and detaches it from the profiles
+// that referenced it.
+func (c *Config) RemoveGlossary(id string) error {
+	idx := c.glossaryIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("glossary not found: %s", id)
+	}
+
+	c.Glossaries = slices.Delete(c.Glossaries, idx, idx+1)
+	for i := range c.TranslationProfiles {
+		if c.TranslationProfiles[i].GlossaryID == id {
+			c.TranslationProfiles[i].GlossaryID = ""
+		}
+	}
+	return c.Save()
+}
+
+func (c *Config) glossaryIndex(id string) int {
+	return slices.IndexFunc(c.Glossaries, func(x Glossary) bool {
+		return x.ID == id
+	})
+}
+
+// checkGlossaryRef verifies that a profile's glossary reference exists.
+func (c *Config) checkGlossaryRef(id string) error {
+	if id != "" && c.glossaryIndex(id) == -1 {
+		return fmt.Errorf("glossary not found: %s", id)
+	}
+	return nil
+}
+
+func validateGlossary(g Glossary) error {
+	if g.Name == "" {
+		return fmt.Errorf("glossary name required")
+	}
+	for term := range g.Terms {
+		if strings.TrimSpace(term) == "" {
+			return fmt.Errorf("glossary %q has an empty term", g.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestGlossaryCRUD(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := &Config{
+		DefaultLanguages: defaultLanguages(),
+		Credentials:      []types.APICredential{{ID: "c1", Name: "OpenAI", Type: "openai", APIKey: "sk-test"}},
+	}
+	g := Glossary{Name: "Product", Terms: map[string]string{"Transy": "Transy", "token": "令牌"}}
+	if err := cfg.AddGlossary(g); err != nil {
+		t.Fatalf("AddGlossary() error = %v", err)
+	}
+	id := cfg.GetGlossaries()[0].ID
+	if id == "" {
+		t.Fatal("AddGlossary() did not assign an ID")
+	}
+
+	profile := types.TranslationProfile{Name: "Fast", CredentialID: "c1", Model: "gpt-4o-mini", GlossaryID: id}
+	if err := cfg.AddTranslationProfile(profile); err != nil {
+		t.Fatalf("AddTranslationProfile() error = %v", err)
+	}
+	profile.GlossaryID = "missing"
+	if err := cfg.AddTranslationProfile(profile); err == nil {
+		t.Error("AddTranslationProfile() with unknown glossary succeeded")
+	}
+
+	g.Terms = map[string]string{"token": "词元"}
+	if err := cfg.UpdateGlossary(id, g); err != nil {
+		t.Fatalf("UpdateGlossary() error = %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := loaded.GlossaryTerms(id); !reflect.DeepEqual(got, g.Terms) {
+		t.Errorf("GlossaryTerms() = %v, want %v", got, g.Terms)
+	}
+
+	if err := cfg.RemoveGlossary(id); err != nil {
+		t.Fatalf("RemoveGlossary() error = %v", err)
+	}
+	if len(cfg.GetGlossaries()) != 0 {
+		t.Errorf("GetGlossaries() = %v after remove", cfg.GetGlossaries())
+	}
+	if got := cfg.TranslationProfiles[0].GlossaryID; got != "" {
+		t.Errorf("profile GlossaryID = %q after remove, want empty", got)
+	}
+}
+
+func TestValidateGlossary(t *testing.T) {
+	tests := []struct {
+		name    string
+		g       Glossary
+		wantErr bool
+	}{
+		{name: "valid", g: Glossary{Name: "A", Terms: map[string]string{"x": "y"}}},
+		{name: "no terms", g: Glossary{Name: "A"}},
+		{name: "missing name", g: Glossary{Terms: map[string]string{"x": "y"}}, wantErr: true},
+		{name: "blank term", g: Glossary{Name: "A", Terms: map[string]string{" ": "y"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGlossary(tt.g); (err != nil) != tt.wantErr {
+				t.Errorf("validateGlossary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
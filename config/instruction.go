@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// InstructionData holds the fields a profile's InstructionTemplate can
+// use, e.g. "Translate this {{.SourceLang}} text to casual {{.TargetLang}}:
+// {{.Text}}".
+type InstructionData struct {
+	SourceLang string
+	TargetLang string
+	Text       string
+	Context    string // Previous sentences; empty if none or context is off
+	Glossary   string // Glossary rules as instructions; empty if none
+}
+
+// errInstructionNoText rejects templates that would drop the text.
+var errInstructionNoText = errors.New("instruction template must include {{.Text}}")
+
+// ParseInstructionTemplate parses a profile's InstructionTemplate. It fails
+// if the template does not parse, does not render (for example because it
+// names an unknown field), or leaves out the text to translate, with or
+// without a context and glossary. An empty template returns nil, selecting
+// the built-in instruction.
+func ParseInstructionTemplate(s string) (*template.Template, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("instruction").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instruction template: %w", err)
+	}
+
+	// Render with and without the optional fields: few-shot examples never
+	// have a context or glossary, and the text must survive either way.
+	const sample = "\x00text\x00"
+	for _, d := range []InstructionData{
+		{SourceLang: "en", TargetLang: "zh", Text: sample, Context: "context", Glossary: "glossary"},
+		{SourceLang: "en", TargetLang: "zh", Text: sample},
+	} {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, d); err != nil {
+			return nil, fmt.Errorf("invalid instruction template: %w", err)
+		}
+		if !strings.Contains(b.String(), sample) {
+			return nil, errInstructionNoText
+		}
+	}
+	return tmpl, nil
+}
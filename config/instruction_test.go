@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestParseInstructionTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantNil bool
+		wantErr bool
+	}{
+		{"empty", "", true, false},
+		{"blank", "  \n", true, false},
+		{"all fields", "{{.Glossary}} {{.Context}} {{.SourceLang}} -> {{.TargetLang}}: {{.Text}}", false, false},
+		{"conditional context", "{{if .Context}}After {{.Context}}: {{end}}{{.Text}}", false, false},
+		{"malformed", "Translate {{.Text", true, true},
+		{"unknown field", "{{.Foo}} {{.Text}}", true, true},
+		{"missing text", "Translate to {{.TargetLang}}", true, true},
+		{"text only with context", "{{if .Context}}After {{.Context}}: {{.Text}}{{end}}", true, true},
+		{"text only with glossary", "{{with .Glossary}}{{.}} {{$.Text}}{{end}}", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInstructionTemplate(tt.tmpl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseInstructionTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("ParseInstructionTemplate() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestProfileInstructionTemplate(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := &Config{Credentials: []types.APICredential{{ID: "c1", Name: "a", Type: "openai", APIKey: "sk-x"}}}
+	p := types.TranslationProfile{Name: "p", CredentialID: "c1", Model: "gpt-4o", InstructionTemplate: "Translate to {{.TargetLang}}"}
+	if err := cfg.AddTranslationProfile(p); !errors.Is(err, errInstructionNoText) {
+		t.Errorf("AddTranslationProfile() error = %v, want %v", err, errInstructionNoText)
+	}
+
+	p.InstructionTemplate = "Translate to {{.TargetLang}}: {{.Text}}"
+	if err := cfg.AddTranslationProfile(p); err != nil {
+		t.Fatalf("AddTranslationProfile() error = %v", err)
+	}
+	id := cfg.TranslationProfiles[len(cfg.TranslationProfiles)-1].ID
+	p.InstructionTemplate = "{{.Nope}} {{.Text}}"
+	if err := cfg.UpdateTranslationProfile(id, p); err == nil {
+		t.Error("UpdateTranslationProfile() with an unknown field should fail")
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// LanguagePreset is a named set of default targets, e.g. "Work: en→zh" and
+// "Chat: en→ja", so one source can go to different targets by context.
+type LanguagePreset struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Languages map[string]string `json:"languages"` // Source code → default target code
+}
+
+// defaultLanguagePresetID identifies the preset migrated from DefaultLanguages.
+const defaultLanguagePresetID = "default"
+
+// GetLanguagePresets returns all language presets.
+func (c *Config) GetLanguagePresets() []LanguagePreset {
+	return c.LanguagePresets
+}
+
+// ActiveLanguages returns the default targets of the active language
+// preset, falling back to DefaultLanguages for configs without presets.
+func (c *Config) ActiveLanguages() map[string]string {
+	if idx := c.languagePresetIndex(c.ActiveLanguagePresetID); idx != -1 {
+		return c.LanguagePresets[idx].Languages
+	}
+	return c.DefaultLanguages
+}
+
+// AddLanguagePreset adds a new language preset, assigning an ID if missing.
+// A preset without languages starts as a copy of the active ones.
+func (c *Config) AddLanguagePreset(p LanguagePreset) error {
+	if err := validateLanguagePreset(p); err != nil {
+		return err
+	}
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	if c.languagePresetIndex(p.ID) != -1 {
+		return fmt.Errorf("language preset already exists: %s", p.ID)
+	}
+	if p.Languages == nil {
+		p.Languages = maps.Clone(c.ActiveLanguages())
+	}
+	if p.Languages == nil {
+		p.Languages = make(map[string]string)
+	}
+
+	c.LanguagePresets = append(c.LanguagePresets, p)
+	return c.Save()
+}
+
+// UpdateLanguagePreset replaces the language preset with the given ID.
+func (c *Config) UpdateLanguagePreset(id string, p LanguagePreset) error {
+	idx := c.languagePresetIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("language preset not found: %s", id)
+	}
+	if err := validateLanguagePreset(p); err != nil {
+		return err
+	}
+	if p.Languages == nil {
+		p.Languages = make(map[string]string)
+	}
+
+	p.ID = id // Preserve ID
+	c.LanguagePresets[idx] = p
+	c.syncDefaultLanguages()
+	return c.Save()
+}
+
+// RemoveLanguagePreset removes a language preset by ID. The last preset
+// can't be removed; removing the active one activates the first remaining.
+func (c *Config) RemoveLanguagePreset(id string) error {
+	idx := c.languagePresetIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("language preset not found: %s", id)
+	}
+	if len(c.LanguagePresets) == 1 {
+		return fmt.Errorf("cannot remove the last language preset")
+	}
+
+	c.LanguagePresets = slices.Delete(c.LanguagePresets, idx, idx+1)
+	if c.ActiveLanguagePresetID == id {
+		c.ActiveLanguagePresetID = c.LanguagePresets[0].ID
+	}
+	c.syncDefaultLanguages()
+	return c.Save()
+}
+
+// SetActiveLanguagePreset makes the language preset with the given ID the
+// one DetectLanguage consults. It leaves ActivePresetID, which ApplyPreset
+// sets for a Preset, unchanged.
+func (c *Config) SetActiveLanguagePreset(id string) error {
+	if c.languagePresetIndex(id) == -1 {
+		return fmt.Errorf("language preset not found: %s", id)
+	}
+	c.ActiveLanguagePresetID = id
+	c.syncDefaultLanguages()
+	return c.Save()
+}
+
+// SetDefaultLanguage sets the default target for src in the active
+// language preset.
+func (c *Config) SetDefaultLanguage(src, dst string) error {
+	c.setDefaultLanguage(src, dst)
+	return c.Save()
+}
+
+func (c *Config) setDefaultLanguage(src, dst string) {
+	langs := c.ActiveLanguages()
+	if langs == nil {
+		langs = make(map[string]string)
+		if idx := c.languagePresetIndex(c.ActiveLanguagePresetID); idx != -1 {
+			c.LanguagePresets[idx].Languages = langs
+		}
+	}
+	langs[src] = dst
+	c.DefaultLanguages = langs
+}
+
+// migrateLanguagePresets turns the single DefaultLanguages map of older
+// configs into the default language preset, and repairs a dangling active
+// preset ID.
+func (c *Config) migrateLanguagePresets() {
+	if len(c.LanguagePresets) == 0 {
+		c.LanguagePresets = []LanguagePreset{{
+			ID:        defaultLanguagePresetID,
+			Name:      "Default",
+			Languages: c.DefaultLanguages,
+		}}
+		c.ActiveLanguagePresetID = defaultLanguagePresetID
+	}
+	for i := range c.LanguagePresets {
+		if c.LanguagePresets[i].Languages == nil {
+			c.LanguagePresets[i].Languages = make(map[string]string)
+		}
+	}
+	if c.languagePresetIndex(c.ActiveLanguagePresetID) == -1 {
+		c.ActiveLanguagePresetID = c.LanguagePresets[0].ID
+	}
+	c.syncDefaultLanguages()
+}
+
+// syncDefaultLanguages points DefaultLanguages at the active preset's
+// languages, so it stays valid for older versions reading the config.
+func (c *Config) syncDefaultLanguages() {
+	if idx := c.languagePresetIndex(c.ActiveLanguagePresetID); idx != -1 {
+		c.DefaultLanguages = c.LanguagePresets[idx].Languages
+	}
+}
+
+func (c *Config) languagePresetIndex(id string) int {
+	if id == "" {
+		return -1
+	}
+	return slices.IndexFunc(c.LanguagePresets, func(x LanguagePreset) bool {
+		return x.ID == id
+	})
+}
+
+func validateLanguagePreset(p LanguagePreset) error {
+	if p.Name == "" {
+		return fmt.Errorf("language preset name required")
+	}
+	for src, dst := range p.Languages {
+		if src == "" || dst == "" {
+			return fmt.Errorf("language preset %q has an empty language code", p.Name)
+		}
+	}
+	return nil
+}
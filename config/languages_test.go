@@ -0,0 +1,104 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func languagePresetTestConfig() *Config {
+	cfg := &Config{DefaultLanguages: map[string]string{"en": "zh"}}
+	cfg.migrateLanguagePresets()
+	return cfg
+}
+
+func TestLanguagePresetCRUD(t *testing.T) {
+	useTempConfigDir(t)
+	cfg := languagePresetTestConfig()
+
+	// A preset without languages starts from the active ones.
+	if err := cfg.AddLanguagePreset(LanguagePreset{ID: "chat", Name: "Chat"}); err != nil {
+		t.Fatalf("AddLanguagePreset() error = %v", err)
+	}
+	if got := cfg.GetLanguagePresets()[1].Languages; !reflect.DeepEqual(got, map[string]string{"en": "zh"}) {
+		t.Errorf("new preset languages = %v, want a copy of the active ones", got)
+	}
+	if err := cfg.AddLanguagePreset(LanguagePreset{ID: "chat", Name: "Again"}); err == nil {
+		t.Error("AddLanguagePreset() with a duplicate ID succeeded")
+	}
+
+	if err := cfg.SetActiveLanguagePreset("chat"); err != nil {
+		t.Fatalf("SetActiveLanguagePreset() error = %v", err)
+	}
+	if err := cfg.SetDefaultLanguage("en", "ja"); err != nil {
+		t.Fatalf("SetDefaultLanguage() error = %v", err)
+	}
+	if got := cfg.ActiveLanguages()["en"]; got != "ja" {
+		t.Errorf("active en target = %q, want ja", got)
+	}
+	if got := cfg.LanguagePresets[0].Languages["en"]; got != "zh" {
+		t.Errorf("default preset en target = %q, want it untouched", got)
+	}
+	if got := cfg.DefaultLanguages["en"]; got != "ja" {
+		t.Errorf("DefaultLanguages[en] = %q, want the active preset's ja", got)
+	}
+
+	if err := cfg.UpdateLanguagePreset("chat", LanguagePreset{Name: "Chat", Languages: map[string]string{"en": "ko"}}); err != nil {
+		t.Fatalf("UpdateLanguagePreset() error = %v", err)
+	}
+	if got := cfg.ActiveLanguages()["en"]; got != "ko" {
+		t.Errorf("active en target after update = %q, want ko", got)
+	}
+
+	if err := cfg.RemoveLanguagePreset("chat"); err != nil {
+		t.Fatalf("RemoveLanguagePreset() error = %v", err)
+	}
+	if cfg.ActiveLanguagePresetID != defaultLanguagePresetID {
+		t.Errorf("active preset after removing it = %q, want %q", cfg.ActiveLanguagePresetID, defaultLanguagePresetID)
+	}
+	if got := cfg.DefaultLanguages["en"]; got != "zh" {
+		t.Errorf("DefaultLanguages[en] after remove = %q, want zh", got)
+	}
+	if err := cfg.RemoveLanguagePreset(defaultLanguagePresetID); err == nil {
+		t.Error("RemoveLanguagePreset() removed the last preset")
+	}
+}
+
+func TestLanguagePresetErrors(t *testing.T) {
+	useTempConfigDir(t)
+	cfg := languagePresetTestConfig()
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"add without name", func() error { return cfg.AddLanguagePreset(LanguagePreset{}) }},
+		{"add empty code", func() error {
+			return cfg.AddLanguagePreset(LanguagePreset{Name: "X", Languages: map[string]string{"en": ""}})
+		}},
+		{"update missing", func() error { return cfg.UpdateLanguagePreset("nope", LanguagePreset{Name: "X"}) }},
+		{"remove missing", func() error { return cfg.RemoveLanguagePreset("nope") }},
+		{"activate missing", func() error { return cfg.SetActiveLanguagePreset("nope") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestApplyPresetWritesActiveLanguagePreset(t *testing.T) {
+	useTempConfigDir(t)
+	cfg := presetTestConfig()
+	cfg.migrateLanguagePresets()
+	if err := cfg.AddPreset(Preset{ID: "m", Name: "Meeting", SourceLang: "ja", TargetLang: "en"}); err != nil {
+		t.Fatalf("AddPreset() error = %v", err)
+	}
+	if _, err := cfg.ApplyPreset("m"); err != nil {
+		t.Fatalf("ApplyPreset() error = %v", err)
+	}
+	if got := cfg.ActiveLanguages()["ja"]; got != "en" {
+		t.Errorf("active ja target = %q, want en", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"go.aimuz.me/transy/internal/types"
@@ -128,3 +129,143 @@ func TestMigration(t *testing.T) {
 		t.Errorf("profile 0 should be active")
 	}
 }
+
+func TestMigrationMaxTokens(t *testing.T) {
+	useTempConfigDir(t)
+	writeConfigFile(t, `{"providers": [
+		{"name": "Default", "type": "openai", "api_key": "sk-a", "model": "gpt-4o", "max_tokens": 1000, "active": true},
+		{"name": "Unset", "type": "openai", "api_key": "sk-b", "model": "gpt-4o"},
+		{"name": "Custom", "type": "openai", "api_key": "sk-c", "model": "gpt-4o", "max_tokens": 4000}
+	]}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]int{"Default": 0, "Unset": 0, "Custom": 4000}
+	for _, p := range cfg.TranslationProfiles {
+		if p.MaxTokens != want[p.Name] {
+			t.Errorf("profile %q MaxTokens = %d, want %d", p.Name, p.MaxTokens, want[p.Name])
+		}
+	}
+	if len(cfg.TranslationProfiles) != len(want) {
+		t.Errorf("got %d profiles, want %d", len(cfg.TranslationProfiles), len(want))
+	}
+}
+
+// writeConfigFile writes raw config JSON where Load will find it.
+func writeConfigFile(t *testing.T, data string) {
+	t.Helper()
+	path, err := configPath()
+	if err != nil {
+		t.Fatalf("configPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateLanguagePresets(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantActive string
+		wantIDs    []string
+		wantLangs  map[string]string
+	}{
+		{
+			name:       "single map becomes default preset",
+			data:       `{"default_languages": {"en": "ja", "ja": "en"}}`,
+			wantActive: "default",
+			wantIDs:    []string{"default"},
+			wantLangs:  map[string]string{"en": "ja", "ja": "en"},
+		},
+		{
+			name:       "missing map uses built-in defaults",
+			data:       `{}`,
+			wantActive: "default",
+			wantIDs:    []string{"default"},
+			wantLangs:  map[string]string{"zh": "en", "en": "zh"},
+		},
+		{
+			name: "presets are kept and active one wins",
+			data: `{
+				"default_languages": {"en": "zh"},
+				"language_presets": [
+					{"id": "work", "name": "Work", "languages": {"en": "zh"}},
+					{"id": "chat", "name": "Chat", "languages": {"en": "ja"}}
+				],
+				"active_language_preset_id": "chat"
+			}`,
+			wantActive: "chat",
+			wantIDs:    []string{"work", "chat"},
+			wantLangs:  map[string]string{"en": "ja"},
+		},
+		{
+			name: "dangling active ID falls back to first preset",
+			data: `{
+				"language_presets": [{"id": "work", "name": "Work"}],
+				"active_language_preset_id": "gone"
+			}`,
+			wantActive: "work",
+			wantIDs:    []string{"work"},
+			wantLangs:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempConfigDir(t)
+			writeConfigFile(t, tt.data)
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.ActiveLanguagePresetID != tt.wantActive {
+				t.Errorf("ActiveLanguagePresetID = %q, want %q", cfg.ActiveLanguagePresetID, tt.wantActive)
+			}
+			var ids []string
+			for _, p := range cfg.GetLanguagePresets() {
+				ids = append(ids, p.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("preset IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if got := cfg.ActiveLanguages(); !reflect.DeepEqual(got, tt.wantLangs) {
+				t.Errorf("ActiveLanguages() = %v, want %v", got, tt.wantLangs)
+			}
+			if !reflect.DeepEqual(cfg.DefaultLanguages, tt.wantLangs) {
+				t.Errorf("DefaultLanguages = %v, want the active preset's %v", cfg.DefaultLanguages, tt.wantLangs)
+			}
+		})
+	}
+}
+
+func TestMigrateLanguagePresetsSaveRoundTrip(t *testing.T) {
+	useTempConfigDir(t)
+	writeConfigFile(t, `{"default_languages": {"en": "ja"}}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.SetDefaultLanguage("fr", "en"); err != nil {
+		t.Fatalf("SetDefaultLanguage() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := map[string]string{"en": "ja", "fr": "en"}
+	if len(loaded.LanguagePresets) != 1 || !reflect.DeepEqual(loaded.LanguagePresets[0].Languages, want) {
+		t.Errorf("LanguagePresets = %+v, want one preset with %v", loaded.LanguagePresets, want)
+	}
+	if !reflect.DeepEqual(loaded.DefaultLanguages, want) {
+		t.Errorf("DefaultLanguages = %v, want %v", loaded.DefaultLanguages, want)
+	}
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"go.aimuz.me/transy/internal/types"
+)
+
+// Preset bundles the settings for one audience or use case so they can be
+// switched in a single step, e.g. "Meeting: zh→en, formal, realtime".
+// Empty fields leave the corresponding setting unchanged when applied.
+type Preset struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	SourceLang  string `json:"source_lang,omitempty"`
+	TargetLang  string `json:"target_lang,omitempty"`
+	ProfileID   string `json:"profile_id,omitempty"`   // Translation profile to activate
+	SpeechMode  string `json:"speech_mode,omitempty"`  // "transcription" or "realtime"
+	DisplayMode string `json:"display_mode,omitempty"` // Caption layout, interpreted by the UI
+	Formality   string `json:"formality,omitempty"`    // types.Formality*, set on the activated profile
+}
+
+// validSpeechModes are the accepted SpeechConfig modes.
+var validSpeechModes = []string{"transcription", "realtime"}
+
+// GetPresets returns all presets.
+func (c *Config) GetPresets() []Preset {
+	return c.Presets
+}
+
+// AddPreset adds a new preset, assigning an ID if missing.
+func (c *Config) AddPreset(p Preset) error {
+	if err := c.validatePreset(p); err != nil {
+		return err
+	}
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+
+	c.Presets = append(c.Presets, p)
+	return c.Save()
+}
+
+// UpdatePreset replaces the preset with the given ID.
+func (c *Config) UpdatePreset(id string, p Preset) error {
+	idx := c.presetIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("preset not found: %s", id)
+	}
+	if err := c.validatePreset(p); err != nil {
+		return err
+	}
+
+	p.ID = id // Preserve ID
+	c.Presets[idx] = p
+	return c.Save()
+}
+
+// RemovePreset removes a preset by ID.
+func (c *Config) RemovePreset(id string) error {
+	idx := c.presetIndex(id)
+	if idx == -1 {
+		return fmt.Errorf("preset not found: %s", id)
+	}
+
+	c.Presets = slices.Delete(c.Presets, idx, idx+1)
+	if c.ActivePresetID == id {
+		c.ActivePresetID = ""
+	}
+	return c.Save()
+}
+
+// ApplyPreset activates the preset's translation profile, sets its
+// formality, speech mode and language pair, then saves once. Everything is
+// validated before any setting changes, so a failed apply leaves the config
+// untouched.
+func (c *Config) ApplyPreset(id string) (*Preset, error) {
+	idx := c.presetIndex(id)
+	if idx == -1 {
+		return nil, fmt.Errorf("preset not found: %s", id)
+	}
+	p := c.Presets[idx]
+	if err := c.validatePreset(p); err != nil {
+		return nil, err
+	}
+
+	if p.ProfileID != "" {
+		for i := range c.TranslationProfiles {
+			c.TranslationProfiles[i].Active = c.TranslationProfiles[i].ID == p.ProfileID
+		}
+	}
+	if p.Formality != "" {
+		if active := c.GetActiveTranslationProfile(); active != nil {
+			active.Formality = p.Formality
+		}
+	}
+	if p.SpeechMode != "" {
+		if c.SpeechConfig == nil {
+			c.SpeechConfig = &types.SpeechConfig{}
+		}
+		c.SpeechConfig.Mode = p.SpeechMode
+	}
+	if p.SourceLang != "" && p.SourceLang != "auto" && p.TargetLang != "" {
+		c.setDefaultLanguage(p.SourceLang, p.TargetLang)
+	}
+	c.ActivePresetID = id
+
+	if err := c.Save(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (c *Config) presetIndex(id string) int {
+	return slices.IndexFunc(c.Presets, func(x Preset) bool {
+		return x.ID == id
+	})
+}
+
+func (c *Config) validatePreset(p Preset) error {
+	if p.Name == "" {
+		return fmt.Errorf("preset name required")
+	}
+	if p.ProfileID != "" && !slices.ContainsFunc(c.TranslationProfiles, func(x types.TranslationProfile) bool {
+		return x.ID == p.ProfileID
+	}) {
+		return fmt.Errorf("profile not found: %s", p.ProfileID)
+	}
+	if p.SpeechMode != "" && !slices.Contains(validSpeechModes, p.SpeechMode) {
+		return fmt.Errorf("invalid speech mode: %q", p.SpeechMode)
+	}
+	return checkProfileStyle(types.TranslationProfile{Formality: p.Formality})
+}
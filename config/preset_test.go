@@ -0,0 +1,171 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// useTempConfigDir points the user config dir at a temp dir so Save
+// doesn't touch the real config.
+func useTempConfigDir(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AppData", dir)
+}
+
+func presetTestConfig() *Config {
+	return &Config{
+		DefaultLanguages: defaultLanguages(),
+		TranslationProfiles: []types.TranslationProfile{
+			{ID: "p1", Name: "Fast", Active: true},
+			{ID: "p2", Name: "Formal"},
+		},
+		SpeechConfig: &types.SpeechConfig{Mode: "transcription", Model: "whisper-1"},
+	}
+}
+
+func TestPresetRoundTrip(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := presetTestConfig()
+	want := Preset{
+		Name:        "Meeting",
+		SourceLang:  "zh",
+		TargetLang:  "en",
+		ProfileID:   "p2",
+		SpeechMode:  "realtime",
+		DisplayMode: "bilingual",
+		Formality:   "formal",
+	}
+	if err := cfg.AddPreset(want); err != nil {
+		t.Fatalf("AddPreset() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got := loaded.GetPresets()
+	if len(got) != 1 || got[0].ID == "" {
+		t.Fatalf("GetPresets() = %+v, want one preset with an ID", got)
+	}
+	want.ID = got[0].ID
+	if !reflect.DeepEqual(got[0], want) {
+		t.Errorf("loaded preset = %+v, want %+v", got[0], want)
+	}
+
+	want.Name = "Standup"
+	if err := loaded.UpdatePreset(want.ID, want); err != nil {
+		t.Fatalf("UpdatePreset() error = %v", err)
+	}
+	if name := loaded.GetPresets()[0].Name; name != "Standup" {
+		t.Errorf("updated name = %q, want %q", name, "Standup")
+	}
+	if err := loaded.RemovePreset(want.ID); err != nil {
+		t.Fatalf("RemovePreset() error = %v", err)
+	}
+	if n := len(loaded.GetPresets()); n != 0 {
+		t.Errorf("presets after remove = %d, want 0", n)
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := presetTestConfig()
+	cfg.Presets = []Preset{
+		{ID: "meeting", Name: "Meeting", SourceLang: "zh", TargetLang: "en", ProfileID: "p2", SpeechMode: "realtime", Formality: types.FormalityFormal},
+		{ID: "study", Name: "Study", SourceLang: "ja", TargetLang: "en"},
+	}
+
+	p, err := cfg.ApplyPreset("meeting")
+	if err != nil {
+		t.Fatalf("ApplyPreset() error = %v", err)
+	}
+	if p.Name != "Meeting" {
+		t.Errorf("applied preset = %q, want %q", p.Name, "Meeting")
+	}
+	if active := cfg.GetActiveTranslationProfile(); active.ID != "p2" {
+		t.Errorf("active profile = %q, want %q", active.ID, "p2")
+	}
+	if f := cfg.GetActiveTranslationProfile().Formality; f != types.FormalityFormal {
+		t.Errorf("active profile formality = %q, want %q", f, types.FormalityFormal)
+	}
+	if f := cfg.TranslationProfiles[0].Formality; f != "" {
+		t.Errorf("inactive profile formality = %q, want unchanged", f)
+	}
+	if mode := cfg.SpeechConfig.Mode; mode != "realtime" {
+		t.Errorf("speech mode = %q, want %q", mode, "realtime")
+	}
+	if dst := cfg.DefaultLanguages["zh"]; dst != "en" {
+		t.Errorf("default target for zh = %q, want %q", dst, "en")
+	}
+	if cfg.ActivePresetID != "meeting" {
+		t.Errorf("ActivePresetID = %q, want %q", cfg.ActivePresetID, "meeting")
+	}
+
+	// Empty fields leave settings unchanged.
+	if _, err := cfg.ApplyPreset("study"); err != nil {
+		t.Fatalf("ApplyPreset() error = %v", err)
+	}
+	if active := cfg.GetActiveTranslationProfile(); active.ID != "p2" {
+		t.Errorf("active profile = %q, want unchanged %q", active.ID, "p2")
+	}
+	if mode := cfg.SpeechConfig.Mode; mode != "realtime" {
+		t.Errorf("speech mode = %q, want unchanged %q", mode, "realtime")
+	}
+	if dst := cfg.DefaultLanguages["ja"]; dst != "en" {
+		t.Errorf("default target for ja = %q, want %q", dst, "en")
+	}
+}
+
+func TestApplyPresetInvalid(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := presetTestConfig()
+	cfg.Presets = []Preset{
+		{ID: "stale", Name: "Stale", ProfileID: "gone", SpeechMode: "realtime"},
+	}
+
+	if _, err := cfg.ApplyPreset("missing"); err == nil {
+		t.Error("ApplyPreset(missing) error = nil, want error")
+	}
+	if _, err := cfg.ApplyPreset("stale"); err == nil {
+		t.Error("ApplyPreset(stale) error = nil, want error")
+	}
+
+	// A failed apply must not change anything.
+	if active := cfg.GetActiveTranslationProfile(); active.ID != "p1" {
+		t.Errorf("active profile = %q, want %q", active.ID, "p1")
+	}
+	if mode := cfg.SpeechConfig.Mode; mode != "transcription" {
+		t.Errorf("speech mode = %q, want %q", mode, "transcription")
+	}
+	if cfg.ActivePresetID != "" {
+		t.Errorf("ActivePresetID = %q, want empty", cfg.ActivePresetID)
+	}
+}
+
+func TestAddPresetValidation(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := presetTestConfig()
+	tests := []struct {
+		name   string
+		preset Preset
+	}{
+		{"missing name", Preset{}},
+		{"unknown profile", Preset{Name: "x", ProfileID: "nope"}},
+		{"bad speech mode", Preset{Name: "x", SpeechMode: "telepathy"}},
+		{"bad formality", Preset{Name: "x", Formality: "polite"}},
+	}
+	for _, tt := range tests {
+		if err := cfg.AddPreset(tt.preset); err == nil {
+			t.Errorf("%s: AddPreset() error = nil, want error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// SetModelPrices validates and saves the custom model prices, replacing
+// any saved before. Nil or empty clears them.
+func (c *Config) SetModelPrices(prices map[string]types.ModelPrice) error {
+	clean := make(map[string]types.ModelPrice, len(prices))
+	for model, p := range prices {
+		model = strings.TrimSpace(model)
+		if model == "" {
+			return fmt.Errorf("model price: model name required")
+		}
+		for _, v := range []float64{p.Prompt, p.Completion} {
+			if v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("model price for %q: invalid price %v", model, v)
+			}
+		}
+		clean[model] = p
+	}
+	if len(clean) == 0 {
+		clean = nil
+	}
+	c.ModelPrices = clean
+	return c.Save()
+}
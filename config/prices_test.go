@@ -0,0 +1,41 @@
+package config
+
+import (
+	"math"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestSetModelPrices(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		name    string
+		prices  map[string]types.ModelPrice
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", map[string]types.ModelPrice{"gpt-4o": {Prompt: 2.5, Completion: 10}}, false},
+		{"free local model", map[string]types.ModelPrice{"llama3": {}}, false},
+		{"blank model", map[string]types.ModelPrice{" ": {Prompt: 1}}, true},
+		{"negative", map[string]types.ModelPrice{"gpt-4o": {Prompt: -1}}, true},
+		{"nan", map[string]types.ModelPrice{"gpt-4o": {Completion: math.NaN()}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			if err := cfg.SetModelPrices(tt.prices); (err != nil) != tt.wantErr {
+				t.Errorf("SetModelPrices() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	if err := cfg.SetModelPrices(map[string]types.ModelPrice{" my-model ": {Prompt: 1, Completion: 2}}); err != nil {
+		t.Fatalf("SetModelPrices() error = %v", err)
+	}
+	if _, ok := cfg.ModelPrices["my-model"]; !ok {
+		t.Errorf("ModelPrices = %v, want the trimmed name", cfg.ModelPrices)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import "strings"
+
+// PromptTemplate is a recommended system prompt for a use case.
+type PromptTemplate struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// DefaultPromptTemplate is applied to new profiles without a system prompt.
+const DefaultPromptTemplate = "general"
+
+var promptTemplates = []PromptTemplate{
+	{
+		ID:   "general",
+		Name: "General",
+		Prompt: `You are a professional translator. Translate the provided text into the target language accurately.
+
+Key Requirements:
+- Preserve the logic, structure, and distinct style of the original text.
+- Use professional and context-appropriate terminology.
+- Output strictly the translation alone.`,
+	},
+	{
+		ID:   "technical",
+		Name: "Technical",
+		Prompt: `You are a technical translator for software and engineering content. Translate the provided text into the target language.
+
+Key Requirements:
+- Keep code, commands, identifiers, file paths, and product names unchanged.
+- Use the established terminology of the field; keep an English term where no standard translation exists.
+- Keep the structure: lists, headings, and line breaks.
+- Output strictly the translation alone.`,
+	},
+	{
+		ID:   "casual",
+		Name: "Casual",
+		Prompt: `You translate informal conversation. Translate the provided text into the target language the way a native speaker would say it.
+
+Key Requirements:
+- Match the tone: keep slang, humor, and emphasis natural rather than literal.
+- Keep it concise.
+- Output strictly the translation alone.`,
+	},
+	{
+		ID:   "subtitles",
+		Name: "Subtitles",
+		Prompt: `You translate subtitles and live captions. Translate the provided text into the target language.
+
+Key Requirements:
+- Keep each line short and easy to read at a glance.
+- Translate the speaker's meaning; drop filler words and false starts.
+- Do not merge, split, or add lines.
+- Output strictly the translation alone.`,
+	},
+}
+
+// PromptTemplates returns the built-in system prompt templates, the
+// default one first. The profile editor prefills new profiles with it.
+func PromptTemplates() []PromptTemplate {
+	return append([]PromptTemplate(nil), promptTemplates...)
+}
+
+// promptTemplate returns the template with id, or false if there is none.
+func promptTemplate(id string) (PromptTemplate, bool) {
+	for _, t := range promptTemplates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return PromptTemplate{}, false
+}
+
+// defaultSystemPrompt returns prompt, or the default template's prompt if
+// prompt is blank. A custom prompt is always kept as is.
+func defaultSystemPrompt(prompt string) string {
+	if strings.TrimSpace(prompt) != "" {
+		return prompt
+	}
+	t, _ := promptTemplate(DefaultPromptTemplate)
+	return t.Prompt
+}
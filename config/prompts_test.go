@@ -0,0 +1,61 @@
+package config
+
+import (
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestAddTranslationProfileDefaultPrompt(t *testing.T) {
+	useTempConfigDir(t)
+
+	general, ok := promptTemplate(DefaultPromptTemplate)
+	if !ok || general.Prompt == "" {
+		t.Fatalf("default template %q missing", DefaultPromptTemplate)
+	}
+
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"empty gets default", "", general.Prompt},
+		{"blank gets default", "  \n", general.Prompt},
+		{"custom untouched", "Translate like a pirate.", "Translate like a pirate."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Credentials: []types.APICredential{{ID: "c1", Name: "a", Type: "openai", APIKey: "sk-x"}}}
+			p := types.TranslationProfile{Name: "p", CredentialID: "c1", Model: "gpt-4o", SystemPrompt: tt.prompt}
+			if err := cfg.AddTranslationProfile(p); err != nil {
+				t.Fatalf("AddTranslationProfile() error = %v", err)
+			}
+			if got := cfg.TranslationProfiles[0].SystemPrompt; got != tt.want {
+				t.Errorf("SystemPrompt = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptTemplates(t *testing.T) {
+	templates := PromptTemplates()
+	if len(templates) == 0 || templates[0].ID != DefaultPromptTemplate {
+		t.Fatalf("first template is not %q", DefaultPromptTemplate)
+	}
+	seen := make(map[string]bool)
+	for _, tmpl := range templates {
+		if tmpl.ID == "" || tmpl.Name == "" || tmpl.Prompt == "" {
+			t.Errorf("incomplete template %+v", tmpl)
+		}
+		if seen[tmpl.ID] {
+			t.Errorf("duplicate template id %q", tmpl.ID)
+		}
+		seen[tmpl.ID] = true
+	}
+	for _, id := range []string{"general", "technical", "casual", "subtitles"} {
+		if !seen[id] {
+			t.Errorf("missing template %q", id)
+		}
+	}
+}
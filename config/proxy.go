@@ -0,0 +1,18 @@
+package config
+
+import (
+	"strings"
+
+	"go.aimuz.me/transy/transport"
+)
+
+// SetProxy validates and saves the outbound proxy URL. Empty clears it.
+// The caller applies it with transport.SetProxy.
+func (c *Config) SetProxy(raw string) error {
+	raw = strings.TrimSpace(raw)
+	if _, err := transport.ParseProxy(raw); err != nil {
+		return err
+	}
+	c.Proxy = raw
+	return c.Save()
+}
@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestSetProxy(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{" http://proxy.corp:3128 ", "http://proxy.corp:3128", false},
+		{"socks5://127.0.0.1:1080", "socks5://127.0.0.1:1080", false},
+		{"proxy.corp:3128", "", true},
+		{"ftp://proxy.corp", "", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		err := cfg.SetProxy(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetProxy(%q) error = %v, want error %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if cfg.Proxy != tt.want {
+			t.Errorf("SetProxy(%q) saved %q, want %q", tt.raw, cfg.Proxy, tt.want)
+		}
+	}
+
+	cfg := &Config{}
+	if err := cfg.SetProxy("http://proxy.corp:3128"); err != nil {
+		t.Fatalf("SetProxy() error = %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Proxy != "http://proxy.corp:3128" {
+		t.Errorf("loaded Proxy = %q, want it persisted", loaded.Proxy)
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// maxVADDurationMs bounds the durations in VADSettings.
+const maxVADDurationMs = 10_000
+
+// GetVADSettings returns the live VAD settings; zero if none are saved.
+func (c *Config) GetVADSettings() types.VADSettings {
+	if c.SpeechConfig == nil || c.SpeechConfig.VAD == nil {
+		return types.VADSettings{}
+	}
+	return *c.SpeechConfig.VAD
+}
+
+// SetVADSettings validates and saves the live VAD settings.
+func (c *Config) SetVADSettings(v types.VADSettings) error {
+	if err := validateVAD(v); err != nil {
+		return err
+	}
+	if c.SpeechConfig == nil {
+		c.SpeechConfig = &types.SpeechConfig{}
+	}
+	c.SpeechConfig.VAD = &v
+	return c.Save()
+}
+
+func validateVAD(v types.VADSettings) error {
+	switch v.Mode {
+	case "", types.VADModeSemantic, types.VADModeServer:
+	default:
+		return fmt.Errorf("invalid VAD mode: %q", v.Mode)
+	}
+	switch v.Eagerness {
+	case "", "low", "medium", "high", "auto":
+	default:
+		return fmt.Errorf("invalid VAD eagerness: %q", v.Eagerness)
+	}
+	if v.Threshold < 0 || v.Threshold > 1 {
+		return fmt.Errorf("VAD threshold %v outside 0-1", v.Threshold)
+	}
+	for _, d := range []int{v.PrefixPaddingMs, v.SilenceDurationMs} {
+		if d < 0 || d > maxVADDurationMs {
+			return fmt.Errorf("VAD duration %dms outside 0-%dms", d, maxVADDurationMs)
+		}
+	}
+	return nil
+}
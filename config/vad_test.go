@@ -0,0 +1,52 @@
+package config
+
+import (
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestSetVADSettings(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		name    string
+		v       types.VADSettings
+		wantErr bool
+	}{
+		{"zero", types.VADSettings{}, false},
+		{"server", types.VADSettings{Mode: types.VADModeServer, Threshold: 0.8, PrefixPaddingMs: 300, SilenceDurationMs: 1000}, false},
+		{"semantic", types.VADSettings{Mode: types.VADModeSemantic, Eagerness: "auto"}, false},
+		{"bad mode", types.VADSettings{Mode: "energy"}, true},
+		{"bad eagerness", types.VADSettings{Eagerness: "eager"}, true},
+		{"threshold above 1", types.VADSettings{Threshold: 1.5}, true},
+		{"negative silence", types.VADSettings{SilenceDurationMs: -1}, true},
+		{"silence too long", types.VADSettings{SilenceDurationMs: maxVADDurationMs + 1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.SetVADSettings(tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetVADSettings() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.GetVADSettings() != tt.v {
+				t.Errorf("GetVADSettings() = %+v, want %+v", cfg.GetVADSettings(), tt.v)
+			}
+		})
+	}
+
+	// Settings survive a reload.
+	cfg := &Config{}
+	v := types.VADSettings{Mode: types.VADModeServer, Threshold: 0.6}
+	if err := cfg.SetVADSettings(v); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := loaded.GetVADSettings(); got != v {
+		t.Errorf("reloaded VAD = %+v, want %+v", got, v)
+	}
+}
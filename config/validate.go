@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// Errors for values that look like they were pasted into the wrong field.
+// Set AllowUnusualValues on the credential or profile to save them anyway.
+var (
+	ErrModelLooksLikeKey = errors.New("model looks like an API key; did you swap the model and key fields?")
+	ErrKeyLooksLikeModel = errors.New("api key looks like a model name; did you swap the model and key fields?")
+)
+
+// ErrInsecureEndpoint is returned for a plain-HTTP base URL on a remote
+// host, which would send the API key in clear text.
+var ErrInsecureEndpoint = errors.New("base url must use https for remote hosts; enable insecure endpoints to allow http")
+
+// ErrOfflineMode is returned for a provider that needs the network while
+// OfflineMode is set.
+var ErrOfflineMode = errors.New("offline mode is on: this provider needs the network; use a local endpoint or turn offline mode off")
+
+var (
+	// apiKeyPattern matches well-known API key prefixes (OpenAI, Anthropic, Gemini).
+	apiKeyPattern = regexp.MustCompile(`^(sk-|sk-ant-|sk-proj-|AIza)[A-Za-z0-9_\-]{16,}$`)
+
+	// modelPattern matches common model names.
+	modelPattern = regexp.MustCompile(`^(gpt-|o\d|chatgpt-|claude-|gemini-|whisper-|text-embedding-|deepseek-|qwen|llama)[a-z0-9.\-:]*$`)
+)
+
+// looksLikeAPIKey reports whether s has the shape of an API key.
+func looksLikeAPIKey(s string) bool {
+	return apiKeyPattern.MatchString(strings.TrimSpace(s))
+}
+
+// looksLikeModel reports whether s has the shape of a model name.
+func looksLikeModel(s string) bool {
+	return modelPattern.MatchString(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// checkCredentialSwap rejects a credential whose key looks like a model name.
+func checkCredentialSwap(cred types.APICredential) error {
+	if !cred.AllowUnusualValues && looksLikeModel(cred.APIKey) {
+		return ErrKeyLooksLikeModel
+	}
+	return nil
+}
+
+// checkProfileSwap rejects a profile whose model looks like an API key.
+func checkProfileSwap(p types.TranslationProfile) error {
+	if !p.AllowUnusualValues && looksLikeAPIKey(p.Model) {
+		return ErrModelLooksLikeKey
+	}
+	return nil
+}
+
+// checkEndpoint rejects http:// base URLs on non-loopback hosts unless
+// AllowInsecureEndpoints is set. Other schemes are left to the client.
+func (c *Config) checkEndpoint(baseURL string) error {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || !strings.EqualFold(u.Scheme, "http") {
+		return nil
+	}
+	if isLoopbackHost(u.Hostname()) || c.AllowInsecureEndpoints {
+		return nil
+	}
+	return ErrInsecureEndpoint
+}
+
+// CheckOffline returns ErrOfflineMode if OfflineMode is set and cred is
+// missing or doesn't point at the local machine. Provider default URLs
+// are all remote.
+func (c *Config) CheckOffline(cred *types.APICredential) error {
+	if !c.OfflineMode {
+		return nil
+	}
+	if cred == nil || !IsLocalEndpoint(cred.BaseURL) {
+		return ErrOfflineMode
+	}
+	return nil
+}
+
+// IsLocalEndpoint reports whether baseURL is on a loopback host, such as a
+// local model server.
+func IsLocalEndpoint(baseURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	return err == nil && u.Host != "" && isLoopbackHost(u.Hostname())
+}
+
+// isLoopbackHost reports whether host names the local machine.
+func isLoopbackHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkProfileStyle rejects unknown formality and tone values.
+func checkProfileStyle(p types.TranslationProfile) error {
+	switch p.Formality {
+	case "", types.FormalityDefault, types.FormalityFormal, types.FormalityInformal:
+	default:
+		return fmt.Errorf("invalid formality: %q", p.Formality)
+	}
+	switch p.Tone {
+	case "", types.ToneNeutral, types.ToneFriendly, types.ToneTechnical:
+	default:
+		return fmt.Errorf("invalid tone: %q", p.Tone)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestCredentialSwapGuard(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		name    string
+		cred    types.APICredential
+		wantErr error
+	}{
+		{"openai key", types.APICredential{Name: "a", Type: "openai", APIKey: "sk-proj-abcdefghijklmnopqrstuvwx"}, nil},
+		{"opaque key", types.APICredential{Name: "a", Type: "openai-compatible", BaseURL: "http://localhost", APIKey: "3f9a1c0e77b2"}, nil},
+		{"gpt model as key", types.APICredential{Name: "a", Type: "openai", APIKey: "gpt-4o-mini"}, ErrKeyLooksLikeModel},
+		{"claude model as key", types.APICredential{Name: "a", Type: "claude", APIKey: "claude-3-5-sonnet-20241022"}, ErrKeyLooksLikeModel},
+		{"gemini model as key", types.APICredential{Name: "a", Type: "gemini", APIKey: " gemini-2.0-flash "}, ErrKeyLooksLikeModel},
+		{"override", types.APICredential{Name: "a", Type: "openai-compatible", BaseURL: "http://localhost", APIKey: "llama3", AllowUnusualValues: true}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			if err := cfg.AddCredential(tt.cred); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddCredential() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	if err := cfg.AddCredential(types.APICredential{ID: "c1", Name: "a", Type: "openai", APIKey: "sk-valid"}); err != nil {
+		t.Fatalf("AddCredential() error = %v", err)
+	}
+	err := cfg.UpdateCredential("c1", types.APICredential{Name: "a", Type: "openai", APIKey: "o3-mini"})
+	if !errors.Is(err, ErrKeyLooksLikeModel) {
+		t.Errorf("UpdateCredential() error = %v, want %v", err, ErrKeyLooksLikeModel)
+	}
+}
+
+func TestProfileSwapGuard(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		name    string
+		model   string
+		allow   bool
+		wantErr error
+	}{
+		{"model name", "gpt-4o", false, nil},
+		{"custom model", "my-finetune:v2", false, nil},
+		{"openai key as model", "sk-abcdefghijklmnopqrstuvwxyz012345", false, ErrModelLooksLikeKey},
+		{"anthropic key as model", "sk-ant-REDACTED", false, ErrModelLooksLikeKey},
+		{"gemini key as model", "AIzaSyA1b2C3d4E5f6G7h8I9j0", false, ErrModelLooksLikeKey},
+		{"override", "sk-abcdefghijklmnopqrstuvwxyz012345", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Credentials: []types.APICredential{{ID: "c1", Name: "a", Type: "openai", APIKey: "sk-x"}}}
+			p := types.TranslationProfile{Name: "p", CredentialID: "c1", Model: tt.model, AllowUnusualValues: tt.allow}
+			if err := cfg.AddTranslationProfile(p); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddTranslationProfile() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSpeechPromptLimit(t *testing.T) {
+	useTempConfigDir(t)
+
+	cfg := &Config{}
+	ok := types.SpeechConfig{Prompt: strings.Repeat("词", types.MaxSpeechPromptRunes)}
+	if err := cfg.SetSpeechConfig(ok); err != nil {
+		t.Errorf("SetSpeechConfig(max length) error = %v", err)
+	}
+	long := types.SpeechConfig{Prompt: strings.Repeat("a", types.MaxSpeechPromptRunes+1)}
+	if err := cfg.SetSpeechConfig(long); err == nil {
+		t.Error("SetSpeechConfig(too long) should fail")
+	}
+}
+
+func TestSpeechAudioSource(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		source  string
+		wantErr bool
+	}{
+		{"", false},
+		{"system", false},
+		{"microphone", false},
+		{"both", false},
+		{"line-in", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{}
+		err := cfg.SetSpeechConfig(types.SpeechConfig{AudioSource: tt.source})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetSpeechConfig(audio source %q) error = %v, want error %v", tt.source, err, tt.wantErr)
+		}
+	}
+}
+
+func TestInsecureEndpointGuard(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		name    string
+		baseURL string
+		allow   bool
+		wantErr error
+	}{
+		{"default endpoint", "", false, nil},
+		{"remote https", "https://api.example.com/v1", false, nil},
+		{"localhost http", "http://localhost:11434/v1", false, nil},
+		{"loopback ipv4 http", "http://127.0.0.1:8080/v1", false, nil},
+		{"loopback ipv6 http", "http://[::1]:8080/v1", false, nil},
+		{"remote http", "http://api.example.com/v1", false, ErrInsecureEndpoint},
+		{"lan http", "http://192.168.1.20:11434/v1", false, ErrInsecureEndpoint},
+		{"remote http allowed", "http://api.example.com/v1", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{AllowInsecureEndpoints: tt.allow}
+			cred := types.APICredential{Name: "c", Type: "openai-compatible", APIKey: "k", BaseURL: tt.baseURL}
+			if tt.baseURL == "" {
+				cred.Type = "openai"
+			}
+			if err := cfg.AddCredential(cred); !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddCredential() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if err := cfg.UpdateCredential(cfg.Credentials[0].ID, cred); err != nil {
+					t.Errorf("UpdateCredential() error = %v", err)
+				}
+			}
+		})
+	}
+
+	cfg := &Config{Credentials: []types.APICredential{{ID: "c1", Name: "c", Type: "openai", APIKey: "k"}}}
+	cred := types.APICredential{Name: "c", Type: "openai-compatible", APIKey: "k", BaseURL: "http://api.example.com"}
+	if err := cfg.UpdateCredential("c1", cred); !errors.Is(err, ErrInsecureEndpoint) {
+		t.Errorf("UpdateCredential(remote http) error = %v, want %v", err, ErrInsecureEndpoint)
+	}
+}
+
+func TestCheckOffline(t *testing.T) {
+	tests := []struct {
+		name    string
+		offline bool
+		cred    *types.APICredential
+		wantErr error
+	}{
+		{"online cloud", false, &types.APICredential{Type: "openai"}, nil},
+		{"offline cloud default", true, &types.APICredential{Type: "openai"}, ErrOfflineMode},
+		{"offline cloud url", true, &types.APICredential{Type: "openai-compatible", BaseURL: "https://api.example.com/v1"}, ErrOfflineMode},
+		{"offline localhost", true, &types.APICredential{Type: "openai-compatible", BaseURL: "http://localhost:11434/v1"}, nil},
+		{"offline loopback ip", true, &types.APICredential{Type: "openai-compatible", BaseURL: "http://127.0.0.1:8080"}, nil},
+		{"offline ipv6 loopback", true, &types.APICredential{Type: "openai-compatible", BaseURL: "http://[::1]:8080"}, nil},
+		{"offline lan host", true, &types.APICredential{Type: "openai-compatible", BaseURL: "http://192.168.1.10:8080"}, ErrOfflineMode},
+		{"offline missing credential", true, nil, ErrOfflineMode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{OfflineMode: tt.offline}
+			if err := cfg.CheckOffline(tt.cred); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckOffline() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAzureCredentialValidation(t *testing.T) {
+	useTempConfigDir(t)
+
+	azure := types.APICredential{
+		Name:       "Azure",
+		Type:       "azure-openai",
+		BaseURL:    "https://res.openai.azure.com",
+		APIKey:     "0123456789abcdef",
+		APIVersion: "2024-10-21",
+	}
+	noURL := azure
+	noURL.BaseURL = ""
+	noVersion := azure
+	noVersion.APIVersion = ""
+
+	tests := []struct {
+		name    string
+		cred    types.APICredential
+		wantErr string
+	}{
+		{"valid", azure, ""},
+		{"missing base url", noURL, "base url required"},
+		{"missing api version", noVersion, "api version required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			err := cfg.AddCredential(tt.cred)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("AddCredential() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AddCredential() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	cfg := &Config{}
+	if err := cfg.AddCredential(azure); err != nil {
+		t.Fatalf("AddCredential() error = %v", err)
+	}
+	if err := cfg.UpdateCredential(cfg.Credentials[0].ID, noVersion); err == nil {
+		t.Error("UpdateCredential() without api version succeeded")
+	}
+	if err := validateProvider(types.Provider{Name: "a", Type: "azure-openai", APIKey: "k", Model: "d"}); err == nil {
+		t.Error("validateProvider() accepted azure-openai without base url")
+	}
+}
+
+func TestProfileStyle(t *testing.T) {
+	useTempConfigDir(t)
+
+	tests := []struct {
+		formality string
+		tone      string
+		wantErr   bool
+	}{
+		{"", "", false},
+		{types.FormalityDefault, types.ToneNeutral, false},
+		{types.FormalityFormal, types.ToneTechnical, false},
+		{types.FormalityInformal, types.ToneFriendly, false},
+		{"polite", "", true},
+		{"", "sarcastic", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Credentials: []types.APICredential{{ID: "c1", Name: "a", Type: "openai", APIKey: "sk-x"}}}
+		p := types.TranslationProfile{Name: "p", CredentialID: "c1", Model: "gpt-4o", Formality: tt.formality, Tone: tt.tone}
+		err := cfg.AddTranslationProfile(p)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("AddTranslationProfile(formality %q, tone %q) error = %v, want error %v", tt.formality, tt.tone, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got := cfg.TranslationProfiles[0]; got.Formality != tt.formality || got.Tone != tt.tone {
+			t.Errorf("saved profile formality/tone = %q/%q, want %q/%q", got.Formality, got.Tone, tt.formality, tt.tone)
+		}
+		p.Formality = "polite"
+		if err := cfg.UpdateTranslationProfile(cfg.TranslationProfiles[0].ID, p); err == nil {
+			t.Error("UpdateTranslationProfile() with an invalid formality should fail")
+		}
+	}
+}
@@ -37,6 +37,55 @@ export async function takeScreenshotAndOCR(): Promise<string> {
   return await App.TakeScreenshotAndOCR()
 }
 
+// Languages OCR recognizes, most preferred first. Defaults to the system
+// language and English.
+export async function getOCRLanguages(): Promise<string[]> {
+  return (await App.GetOCRLanguages()) || []
+}
+
+// Saves the OCR languages; an empty list restores the default.
+export async function setOCRLanguages(langs: string[]): Promise<void> {
+  await App.SetOCRLanguages(langs)
+}
+
+// Translates several texts in one call; results keep the request order and
+// failed items carry an error.
+export async function translateBatch(requests: TranslateRequest[]): Promise<TranslateResult[]> {
+  const results = await App.TranslateBatch(requests)
+  return (results || []) as TranslateResult[]
+}
+
+// Cancels the OCR capture or translation in progress, if any.
+export async function cancelCurrentOperation(): Promise<boolean> {
+  return await App.CancelCurrentOperation()
+}
+
+// Cache
+import type { CacheStats } from '../types'
+
+export async function getCacheStats(): Promise<CacheStats> {
+  return (await App.GetCacheStats()) as CacheStats
+}
+
+// Removes every cached translation and returns how many were removed.
+export async function clearCache(): Promise<number> {
+  return await App.ClearCache()
+}
+
+// History
+import type { HistoryEntry } from '../types'
+
+// Returns past translations, newest first.
+export async function getTranslationHistory(limit: number, offset: number): Promise<HistoryEntry[]> {
+  const entries = await App.GetTranslationHistory(limit, offset)
+  return (entries || []) as HistoryEntry[]
+}
+
+export async function searchTranslationHistory(query: string): Promise<HistoryEntry[]> {
+  const entries = await App.SearchTranslationHistory(query)
+  return (entries || []) as HistoryEntry[]
+}
+
 // Version
 export async function getVersion(): Promise<string> {
   return await App.GetVersion()
@@ -64,7 +113,13 @@ export async function getLiveStatus(): Promise<LiveStatus> {
 // New Configuration Architecture
 // ─────────────────────────────────────────────────────────────────────────────
 
-import type { APICredential, TranslationProfile, SpeechConfig } from '../types'
+import type {
+  APICredential,
+  TranslationProfile,
+  PromptTemplate,
+  Glossary,
+  SpeechConfig,
+} from '../types'
 
 // API Credentials
 export async function getCredentials(): Promise<APICredential[]> {
@@ -110,6 +165,16 @@ export async function setTranslationProfileActive(id: string): Promise<void> {
   await App.SetTranslationProfileActive(id)
 }
 
+export async function getPromptTemplates(): Promise<PromptTemplate[]> {
+  const templates = await App.GetPromptTemplates()
+  return (templates || []) as PromptTemplate[]
+}
+
+export async function getGlossaries(): Promise<Glossary[]> {
+  const glossaries = await App.GetGlossaries()
+  return (glossaries || []) as Glossary[]
+}
+
 // Speech Config
 export async function getSpeechConfig(): Promise<SpeechConfig | null> {
   return (await App.GetSpeechConfig()) as SpeechConfig | null
@@ -118,3 +183,58 @@ export async function getSpeechConfig(): Promise<SpeechConfig | null> {
 export async function setSpeechConfig(config: SpeechConfig): Promise<void> {
   await App.SetSpeechConfig(config)
 }
+
+// Audio input devices, for the speech settings. A saved device that is no
+// longer connected triggers an 'audio-device-missing' event on start.
+import type { AudioDevice } from '../types'
+
+export async function getAudioDevices(): Promise<AudioDevice[]> {
+  return ((await App.GetAudioDevices()) as AudioDevice[]) || []
+}
+
+// Voice activity detection; changes apply to a running session.
+import type { VADSettings } from '../types'
+
+export async function getVADSettings(): Promise<VADSettings> {
+  return (await App.GetVADSettings()) as VADSettings
+}
+
+export async function setVADSettings(settings: VADSettings): Promise<void> {
+  await App.SetVADSettings(settings)
+}
+
+// Outbound proxy for API requests; empty uses the system environment.
+export async function getProxy(): Promise<string> {
+  return (await App.GetProxy()) as string
+}
+
+export async function setProxy(proxy: string): Promise<void> {
+  await App.SetProxy(proxy)
+}
+
+// Cost estimates before translating, priced with custom model prices
+// (keyed by model name prefix) over the built-in list.
+import type { CostEstimate, ModelPrice } from '../types'
+
+export async function estimateTranslationCost(request: TranslateRequest): Promise<CostEstimate> {
+  return (await App.EstimateTranslationCost(request)) as CostEstimate
+}
+
+export async function setModelPrices(prices: Record<string, ModelPrice>): Promise<void> {
+  await App.SetModelPrices(prices)
+}
+
+// Checks a saved credential with a minimal request; rejects with a
+// descriptive message if the key, model or endpoint doesn't work.
+export async function testCredential(id: string): Promise<void> {
+  await App.TestCredential(id)
+}
+
+// Side-by-side comparison: translates with several profiles at once,
+// keyed by profile ID. Failed profiles have `error` set.
+export async function translateWithProfiles(
+  ids: string[],
+  request: TranslateRequest
+): Promise<Record<string, TranslateResult>> {
+  return ((await App.TranslateWithProfiles(ids, request)) || {}) as Record<string, TranslateResult>
+}
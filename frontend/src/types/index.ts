@@ -3,7 +3,7 @@
 
 export type Provider = {
   name: string
-  type: 'openai' | 'openai-compatible' | 'gemini' | 'claude'
+  type: 'openai' | 'openai-compatible' | 'azure-openai' | 'gemini' | 'claude'
   base_url?: string
   api_key: string
   model: string
@@ -18,12 +18,14 @@ export type TranslateRequest = {
   text: string
   sourceLang: string
   targetLang: string
+  glossary?: Record<string, string>
 }
 
 export type DetectLanguageResponse = {
   code: string
   name: string
   defaultTarget: string
+  supported: boolean
 }
 
 export type Usage = {
@@ -31,11 +33,14 @@ export type Usage = {
   completionTokens: number
   totalTokens: number
   cacheHit: boolean
+  estimated?: boolean
 }
 
 export type TranslateResult = {
   text: string
   usage: Usage
+  done?: boolean
+  error?: string
 }
 
 // Streaming translation event payload
@@ -43,6 +48,30 @@ export type TranslateChunk = {
   text: string
   done: boolean
   usage?: Usage
+  error?: string
+}
+
+export type CacheStats = {
+  hits: number
+  misses: number
+  entries: number
+  bytes: number
+}
+
+// A past UI or clipboard translation
+export type HistoryEntry = {
+  time: string
+  sourceText: string
+  targetText: string
+  sourceLang: string
+  targetLang: string
+  profile?: string
+}
+
+// Text placed into the source field by the hotkey or OCR
+export type SourceText = {
+  text: string
+  autoTranslate: boolean
 }
 
 export type Language = {
@@ -88,9 +117,11 @@ export type LiveTranscript = {
   timestamp: number
   isFinal: boolean
   confidence: number
+  sessionStart?: number // Unix ms
+  speaker?: string // Diarization label, e.g. "A"
 }
 
-export type VADState = 'listening' | 'speaking' | 'processing'
+export type VADState = 'listening' | 'speaking' | 'processing' | 'reconnecting'
 
 export type LiveStatus = {
   active: boolean
@@ -100,6 +131,7 @@ export type LiveStatus = {
   sttProvider: string
   transcriptCount: number
   vadState: VADState
+  sessionStart: number // Unix ms, 0 if none
 }
 
 export type STTProviderInfo = {
@@ -118,9 +150,14 @@ export type STTProviderInfo = {
 export type APICredential = {
   id: string
   name: string
-  type: 'openai' | 'openai-compatible' | 'gemini' | 'claude'
+  type: 'openai' | 'openai-compatible' | 'azure-openai' | 'gemini' | 'claude'
   base_url?: string
   api_key: string
+  api_version?: string
+  org_id?: string
+  project_id?: string
+  omit_stream_options?: boolean
+  allow_unusual_values?: boolean
 }
 
 export type TranslationProfile = {
@@ -133,6 +170,34 @@ export type TranslationProfile = {
   temperature?: number
   active: boolean
   disable_thinking?: boolean
+  glossary_id?: string
+  instruction_template?: string
+  formality?: 'default' | 'formal' | 'informal'
+  tone?: 'neutral' | 'friendly' | 'technical'
+  use_context?: boolean // Unset means true
+  min_length_ratio?: number
+  max_length_ratio?: number
+  examples?: TranslationExample[]
+  preserve_emoji?: boolean
+  localize_units?: boolean
+  allow_unusual_values?: boolean
+}
+
+export type TranslationExample = {
+  source: string
+  target: string
+}
+
+export type Glossary = {
+  id: string
+  name: string
+  terms: Record<string, string>
+}
+
+export type PromptTemplate = {
+  id: string
+  name: string
+  prompt: string
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -157,4 +222,35 @@ export type SpeechConfig = {
   credential_id?: string
   model?: string
   mode?: 'transcription' | 'realtime'
+  audio_source?: 'system' | 'microphone' | 'both'
+  audio_device_id?: string // Empty uses the default input
+}
+
+// Voice activity detection for live translation. Zero fields keep the defaults.
+export type VADSettings = {
+  mode?: 'semantic' | 'server'
+  eagerness?: 'low' | 'medium' | 'high' | 'auto' // Semantic mode
+  threshold?: number // Server mode, 0-1
+  prefix_padding_ms?: number // Server mode
+  silence_duration_ms?: number // Server mode
+}
+
+export type AudioDevice = {
+  id: string
+  name: string
+}
+
+// Approximate size and cost of a translation before it is sent.
+export type CostEstimate = {
+  model: string
+  promptTokens: number
+  completionTokens: number // Assumes output about as long as the source
+  cost: number // USD; 0 when priceKnown is false
+  priceKnown: boolean
+}
+
+// USD per million tokens.
+export type ModelPrice = {
+  prompt: number
+  completion: number
 }
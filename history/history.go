@@ -0,0 +1,245 @@
+// Package history keeps a searchable log of past translations in a
+// JSONL file.
+package history
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.aimuz.me/transy/internal/jsonl"
+)
+
+// Entry is one recorded translation.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	SourceText string    `json:"sourceText"`
+	TargetText string    `json:"targetText"`
+	SourceLang string    `json:"sourceLang"`
+	TargetLang string    `json:"targetLang"`
+	Profile    string    `json:"profile,omitempty"`
+}
+
+// queueSize bounds the appends waiting to be written. Appends beyond it
+// are dropped rather than slowing down the caller.
+const queueSize = 64
+
+// MaxSearchResults caps the entries returned by Search.
+const MaxSearchResults = 200
+
+// MaxEntries is the number of entries kept. The file may grow a tenth
+// past it before the oldest entries are dropped, so trimming doesn't
+// rewrite it on every append.
+const MaxEntries = 5000
+
+// request is an entry to write, or with flushed set, a marker that
+// acknowledges once every earlier entry is on disk.
+type request struct {
+	entry   Entry
+	flushed chan struct{}
+}
+
+// Store appends entries from a background writer so recording never
+// blocks a translation. Reads see every entry appended before them.
+type Store struct {
+	path  string
+	mu    sync.Mutex // Guards the file and count
+	count int        // Lines in the file; -1 until counted
+	queue chan request
+	quit  chan struct{} // Closed by Close; the queue itself never is
+	done  chan struct{}
+
+	closeOnce sync.Once
+}
+
+// Open returns a store backed by the file at path, created on first write.
+// Close it to flush pending entries.
+func Open(path string) *Store {
+	s := &Store{
+		path:  path,
+		count: -1,
+		queue: make(chan request, queueSize),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *Store) run() {
+	defer close(s.done)
+	for {
+		select {
+		case r := <-s.queue:
+			s.handle(r)
+		case <-s.quit:
+			// Write what was queued before Close.
+			for {
+				select {
+				case r := <-s.queue:
+					s.handle(r)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *Store) handle(r request) {
+	if r.flushed != nil {
+		close(r.flushed)
+		return
+	}
+	if err := s.write(r.entry); err != nil {
+		slog.Warn("write translation history", "error", err)
+	}
+}
+
+// Append records e in the background. It is best-effort: when the writer
+// falls behind or the store is closed, e is dropped.
+func (s *Store) Append(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	select {
+	case <-s.quit:
+		return
+	default:
+	}
+	select {
+	case s.queue <- request{entry: e}:
+	default:
+		slog.Warn("translation history queue full, dropping entry")
+	}
+}
+
+// Close writes pending entries and stops the writer. Appends racing with
+// or following Close are dropped; reads still see what was written.
+func (s *Store) Close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		<-s.done
+	})
+}
+
+// flush waits for entries appended so far to be written, or for the
+// writer to stop.
+func (s *Store) flush() {
+	ack := make(chan struct{})
+	select {
+	case s.queue <- request{flushed: ack}:
+	case <-s.done:
+		return
+	}
+	select {
+	case <-ack:
+	case <-s.done:
+	}
+}
+
+func (s *Store) write(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := jsonl.Append(s.path, e); err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	if s.count < 0 {
+		entries, err := s.read()
+		if err != nil {
+			return err
+		}
+		s.count = len(entries)
+	} else {
+		s.count++
+	}
+	if s.count > MaxEntries+MaxEntries/10 {
+		return s.trim()
+	}
+	return nil
+}
+
+// trim rewrites the file with only the newest MaxEntries entries. The
+// caller holds s.mu.
+func (s *Store) trim() error {
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	entries = entries[:min(len(entries), MaxEntries)]
+	slices.Reverse(entries)
+	if err := jsonl.Replace(s.path, entries); err != nil {
+		return fmt.Errorf("trim history: %w", err)
+	}
+	s.count = len(entries)
+	return nil
+}
+
+// List returns up to limit entries, newest first, after skipping offset
+// of them. A limit <= 0 returns all remaining entries.
+func (s *Store) List(limit, offset int) ([]Entry, error) {
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	offset = max(offset, 0)
+	if offset >= len(entries) {
+		return nil, nil
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// Search returns the entries whose source or target text contains query,
+// ignoring case, newest first and at most MaxSearchResults of them. An
+// empty query matches nothing.
+func (s *Store) Search(query string) ([]Entry, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil, nil
+	}
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Entry
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.SourceText), query) ||
+			strings.Contains(strings.ToLower(e.TargetText), query) {
+			matches = append(matches, e)
+			if len(matches) == MaxSearchResults {
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+// load reads every entry written so far, newest first.
+func (s *Store) load() ([]Entry, error) {
+	s.flush()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// read reads every entry in the file, newest first. Lines that don't
+// decode, such as one cut short by a crash, are skipped. The caller holds
+// s.mu.
+func (s *Store) read() ([]Entry, error) {
+	entries, err := jsonl.Load[Entry](s.path, true)
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
@@ -0,0 +1,169 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func testStore(t *testing.T) *Store {
+	t.Helper()
+	s := Open(filepath.Join(t.TempDir(), "history", "history.jsonl"))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func texts(entries []Entry) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.SourceText)
+	}
+	return out
+}
+
+func TestAppendList(t *testing.T) {
+	s := testStore(t)
+
+	if got, err := s.List(10, 0); err != nil || len(got) != 0 {
+		t.Fatalf("List() on empty store = %v, %v", got, err)
+	}
+
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, text := range []string{"one", "two", "three"} {
+		s.Append(Entry{
+			Time:       base.Add(time.Duration(i) * time.Minute),
+			SourceText: text,
+			TargetText: text + "-zh",
+			SourceLang: "en",
+			TargetLang: "zh",
+			Profile:    "Fast",
+		})
+	}
+
+	tests := []struct {
+		limit, offset int
+		want          []string
+	}{
+		{limit: 0, offset: 0, want: []string{"three", "two", "one"}},
+		{limit: 2, offset: 0, want: []string{"three", "two"}},
+		{limit: 2, offset: 2, want: []string{"one"}},
+		{limit: 5, offset: 3, want: nil},
+		{limit: 1, offset: -1, want: []string{"three"}},
+	}
+	for _, tt := range tests {
+		got, err := s.List(tt.limit, tt.offset)
+		if err != nil {
+			t.Fatalf("List(%d, %d) error = %v", tt.limit, tt.offset, err)
+		}
+		if !slices.Equal(texts(got), tt.want) {
+			t.Errorf("List(%d, %d) = %v, want %v", tt.limit, tt.offset, texts(got), tt.want)
+		}
+	}
+
+	got, _ := s.List(1, 0)
+	want := Entry{
+		Time:       base.Add(2 * time.Minute),
+		SourceText: "three",
+		TargetText: "three-zh",
+		SourceLang: "en",
+		TargetLang: "zh",
+		Profile:    "Fast",
+	}
+	if got[0] != want {
+		t.Errorf("List()[0] = %+v, want %+v", got[0], want)
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := Open(path)
+	s.Append(Entry{SourceText: "hello", TargetText: "你好"})
+	s.Close()
+
+	// A line cut short by a crash is skipped.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"sourceText":"trunc`)
+	f.Close()
+
+	s = Open(path)
+	defer s.Close()
+	got, err := s.List(0, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != 1 || got[0].TargetText != "你好" || got[0].Time.IsZero() {
+		t.Errorf("List() after reopen = %+v", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := testStore(t)
+	s.Append(Entry{SourceText: "Good morning", TargetText: "早上好"})
+	s.Append(Entry{SourceText: "Good night", TargetText: "晚安"})
+	s.Append(Entry{SourceText: "Thanks", TargetText: "谢谢"})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "good", want: []string{"Good night", "Good morning"}},
+		{query: "  NIGHT ", want: []string{"Good night"}},
+		{query: "谢谢", want: []string{"Thanks"}},
+		{query: "absent", want: nil},
+		{query: "", want: nil},
+	}
+	for _, tt := range tests {
+		got, err := s.Search(tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", tt.query, err)
+		}
+		if !slices.Equal(texts(got), tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, texts(got), tt.want)
+		}
+	}
+}
+
+func TestAppendAfterClose(t *testing.T) {
+	s := Open(filepath.Join(t.TempDir(), "history.jsonl"))
+	s.Append(Entry{SourceText: "before"})
+	s.Close()
+
+	// Appends racing with shutdown must not panic.
+	s.Append(Entry{SourceText: "after"})
+	s.Close()
+
+	got, err := s.List(0, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !slices.Equal(texts(got), []string{"before"}) {
+		t.Errorf("List() after Close = %v, want [before]", texts(got))
+	}
+}
+
+func TestTrim(t *testing.T) {
+	s := testStore(t)
+	limit := MaxEntries + MaxEntries/10
+	for i := range limit + 1 {
+		if err := s.write(Entry{SourceText: strconv.Itoa(i)}); err != nil {
+			t.Fatalf("write() error = %v", err)
+		}
+	}
+
+	got, err := s.List(0, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(got) != MaxEntries {
+		t.Fatalf("len(List()) = %d, want %d", len(got), MaxEntries)
+	}
+	if first, last := got[0].SourceText, got[len(got)-1].SourceText; first != strconv.Itoa(limit) || last != strconv.Itoa(limit+1-MaxEntries) {
+		t.Errorf("List() spans %s..%s, want the newest %d entries", first, last, MaxEntries)
+	}
+}
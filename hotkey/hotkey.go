@@ -11,6 +11,19 @@ bool checkAccessibility(bool prompt) {
     NSDictionary *opts = @{(__bridge NSString *)kAXTrustedCheckOptionPrompt: @(prompt)};
     return AXIsProcessTrustedWithOptions((__bridge CFDictionaryRef)opts);
 }
+
+void simulatePaste(void) {
+    CGEventSourceRef src = CGEventSourceCreate(kCGEventSourceStateHIDSystemState);
+    CGEventRef down = CGEventCreateKeyboardEvent(src, (CGKeyCode)9, true); // kVK_ANSI_V
+    CGEventRef up = CGEventCreateKeyboardEvent(src, (CGKeyCode)9, false);
+    CGEventSetFlags(down, kCGEventFlagMaskCommand);
+    CGEventSetFlags(up, kCGEventFlagMaskCommand);
+    CGEventPost(kCGHIDEventTap, down);
+    CGEventPost(kCGHIDEventTap, up);
+    CFRelease(down);
+    CFRelease(up);
+    if (src) CFRelease(src);
+}
 */
 import "C"
 
@@ -30,6 +43,7 @@ type HotkeyManager struct {
 	toggleCb    func()        // 切换窗口回调函数
 	ocrCb       func()        // OCR 截图回调函数
 	statusCb    func(bool)    // 权限状态回调函数
+	clipboardCb func()        // 剪贴板翻译回调函数
 	stopPolling chan struct{} // 停止轮询信号
 	clickTime   time.Time     // 上次点击时间
 }
@@ -49,6 +63,17 @@ func (hm *HotkeyManager) SetStatusCallback(cb func(bool)) {
 	hm.statusCb = cb
 }
 
+// SetClipboardCallback 设置剪贴板翻译快捷键 (Cmd+Shift+Y) 的回调
+func (hm *HotkeyManager) SetClipboardCallback(cb func()) {
+	hm.clipboardCb = cb
+}
+
+// SimulatePaste 模拟按下 Cmd+V，粘贴到当前焦点应用
+// 需要辅助功能权限
+func SimulatePaste() {
+	C.simulatePaste()
+}
+
 // IsAccessibilityEnabled 检查辅助功能权限是否已授予
 // prompt: 是否弹出系统授权提示
 func IsAccessibilityEnabled(prompt bool) bool {
@@ -98,6 +123,13 @@ func (hm *HotkeyManager) startHook() error {
 		}
 	})
 
+	// 注册剪贴板翻译快捷键: Cmd+Shift+Y
+	hook.Register(hook.KeyDown, []string{"cmd", "shift", "y"}, func(e hook.Event) {
+		if hm.clipboardCb != nil {
+			hm.clipboardCb()
+		}
+	})
+
 	// 启动钩子监听
 	evChan := hook.Start()
 	go func() {
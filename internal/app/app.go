@@ -2,16 +2,23 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"go.aimuz.me/transy/audiocapture"
 	"go.aimuz.me/transy/cache"
 	"go.aimuz.me/transy/clipboard"
 	"go.aimuz.me/transy/config"
+	"go.aimuz.me/transy/history"
 	"go.aimuz.me/transy/hotkey"
 	"go.aimuz.me/transy/internal/types"
 	"go.aimuz.me/transy/langdetect"
@@ -19,6 +26,8 @@ import (
 	"go.aimuz.me/transy/llm"
 	"go.aimuz.me/transy/ocr"
 	"go.aimuz.me/transy/screenshot"
+	"go.aimuz.me/transy/subtitle"
+	"go.aimuz.me/transy/transport"
 
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
@@ -41,6 +50,24 @@ type Service struct {
 	// Components with proper synchronization
 	translator *Translator
 	live       LiveAdapter
+	liveMu     sync.Mutex // Serializes starting and stopping live translation
+	providers  *livetranslate.Registry
+	segments   *segmentStore  // Finalized transcripts of the current live session
+	usage      *usageLog      // Token usage of translations; nil if the config dir is unknown
+	history    *history.Store // Past UI and clipboard translations; nil if the config dir is unknown
+
+	// Lines from recent OCR captures, for incremental OCR
+	ocrSeen *ocrHistory
+
+	// Keeps hotkey and tray OCR captures from overlapping
+	ocrFlight singleFlight
+
+	// OCR captures and UI translations in flight, for CancelCurrentOperation
+	ops operations
+
+	// Local HTTP server for integrations
+	serverMu sync.Mutex
+	server   *localServer
 
 	// Version info (set by caller)
 	version string
@@ -48,7 +75,12 @@ type Service struct {
 
 // New creates a new Service. Call Init() after Wails app is created.
 func New(version string) *Service {
-	return &Service{version: version}
+	return &Service{
+		version:   version,
+		ocrSeen:   newOCRHistory(ocrHistorySize),
+		providers: newLiveRegistry(),
+		segments:  newSegmentStore(),
+	}
 }
 
 // GetVersion returns the application version.
@@ -69,15 +101,79 @@ func (s *Service) Init(app *application.App, window application.Window) {
 		cfg = &config.Config{}
 	}
 	s.cfg = cfg
+	s.providers.SetOffline(s.cfg.OfflineMode)
+	if err := transport.SetProxy(s.cfg.Proxy); err != nil {
+		slog.Warn("ignoring invalid proxy setting", "error", err)
+	}
+
+	// Restore window pinning
+	if s.cfg.AlwaysOnTop && s.window != nil {
+		s.window.SetAlwaysOnTop(true)
+	}
 
 	// Initialize cache
 	s.setupCache()
 
 	// Initialize translator
 	s.translator = NewTranslator(s.cache)
+	if err := s.usePostProcessors(s.cfg.PostProcessors, s.cfg.EchoLabels); err != nil {
+		slog.Warn("post-processors", "error", err)
+	}
+	s.translator.SetEstimateUsage(s.cfg.EstimateUsage)
+
+	if configDir, err := os.UserConfigDir(); err != nil {
+		slog.Warn("get config dir for usage log", "error", err)
+	} else {
+		s.usage = &usageLog{path: filepath.Join(configDir, "transy", "usage.jsonl")}
+		s.history = history.Open(filepath.Join(configDir, "transy", "history.jsonl"))
+	}
 
 	// Setup hotkey
 	s.setupHotkey()
+
+	// Start local server if enabled
+	if sc := s.cfg.GetLocalServerConfig(); sc != nil && sc.Enabled {
+		if err := s.startServer(*sc); err != nil {
+			slog.Error("start local server", "error", err)
+		}
+	}
+
+	// Report readiness without blocking startup
+	if !s.cfg.SkipStartupCheck {
+		go func() {
+			evStartupChecks.Emit(s.emit, s.RunStartupChecks())
+		}()
+	}
+}
+
+// RunStartupChecks verifies permissions, audio capture and configuration,
+// returning one result per check for the UI to show as a checklist.
+func (s *Service) RunStartupChecks() []CheckResult {
+	results := []CheckResult{
+		permissionCheck(CheckScreenRecording, screenshot.HasPermission()),
+		permissionCheck(CheckAccessibility, hotkey.IsAccessibilityEnabled(false)),
+		checkAudioCapture(),
+		checkTranslation(s.cfg),
+		checkSpeech(s.cfg.GetSpeechConfig(), s.cfg),
+	}
+	for _, r := range results {
+		if !r.OK {
+			slog.Warn("startup check failed", "check", r.Name, "message", r.Message)
+		}
+	}
+	return results
+}
+
+// GetSkipStartupCheck reports whether startup checks are skipped.
+func (s *Service) GetSkipStartupCheck() bool {
+	return s.cfg.SkipStartupCheck
+}
+
+// SetSkipStartupCheck sets whether startup checks are skipped at launch.
+// RunStartupChecks still runs on request.
+func (s *Service) SetSkipStartupCheck(skip bool) error {
+	s.cfg.SkipStartupCheck = skip
+	return s.cfg.Save()
 }
 
 // Shutdown cleans up resources.
@@ -86,6 +182,10 @@ func (s *Service) Shutdown() {
 		s.hotkey.Stop()
 	}
 	_ = s.live.Stop()
+	s.stopServer()
+	if s.history != nil {
+		s.history.Close()
+	}
 	if s.cache != nil {
 		if err := s.cache.Close(); err != nil {
 			slog.Error("close cache", "error", err)
@@ -101,7 +201,7 @@ func (s *Service) setupCache() {
 	}
 
 	cachePath := filepath.Join(configDir, "transy", "cache")
-	c, err := cache.New(cachePath)
+	c, err := cache.New(cachePath, cache.Options{MaxBytes: cache.DefaultMaxBytes})
 	if err != nil {
 		slog.Error("init cache", "error", err)
 		return
@@ -113,17 +213,19 @@ func (s *Service) setupCache() {
 func (s *Service) setupHotkey() {
 	s.hotkey = hotkey.NewHotkeyManager(
 		func() { s.ToggleWindowVisibility() },
-		func() {
-			go func() {
-				if _, err := s.TakeScreenshotAndOCR(); err != nil {
-					slog.Error("ocr screenshot", "error", err)
-				}
-			}()
-		},
+		func() { s.triggerOCR("hotkey") },
 	)
 
+	s.hotkey.SetClipboardCallback(func() {
+		go func() {
+			if _, err := s.TranslateClipboard(); err != nil {
+				slog.Error("translate clipboard", "error", err)
+			}
+		}()
+	})
+
 	s.hotkey.SetStatusCallback(func(granted bool) {
-		s.emit(EventAccessibilityPerm, granted)
+		evAccessibilityPerm.Emit(s.emit, granted)
 		if granted {
 			slog.Info("accessibility permission granted")
 		} else {
@@ -136,6 +238,23 @@ func (s *Service) setupHotkey() {
 	}
 }
 
+func init() {
+	registerEvents()
+}
+
+// registerEvents declares the payload of every event in knownEvents to
+// Wails, which validates emitted data.
+func registerEvents() {
+	for _, e := range knownEvents {
+		e.register()
+	}
+}
+
+// register declares e to Wails.
+func (e Event[T]) register() {
+	application.RegisterEvent[T](e.Name)
+}
+
 // emit is a safe wrapper around app.Event.Emit
 func (s *Service) emit(name string, data any) {
 	if s.app != nil {
@@ -156,11 +275,7 @@ func (s *Service) SetupSystemTray(icon []byte) {
 	s.trayMenu.Add("OCR 翻译").
 		SetAccelerator("CmdOrCtrl+Shift+O").
 		OnClick(func(*application.Context) {
-			go func() {
-				if _, err := s.TakeScreenshotAndOCR(); err != nil {
-					slog.Error("ocr from tray", "error", err)
-				}
-			}()
+			s.triggerOCR("tray")
 		})
 
 	s.profileMenu = s.trayMenu.AddSubmenu("翻译服务")
@@ -196,11 +311,43 @@ func (s *Service) rebuildProfileMenu() {
 // Live Translation
 // ─────────────────────────────────────────────────────────────────────────────
 
-// StartLiveTranslation starts real-time audio translation.
+// StartLiveTranslation starts real-time audio translation. Starting again
+// with the same languages while running is a no-op; other languages restart
+// the session. Concurrent calls are serialized so only one capture stack
+// exists at a time.
 func (s *Service) StartLiveTranslation(sourceLang, targetLang string) error {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
+
+	if st := s.live.Status(); st.Active && st.SourceLang == sourceLang && st.TargetLang == targetLang {
+		return nil
+	}
+	// Release the audio device before the new provider opens it.
+	if err := s.live.Stop(); err != nil {
+		slog.Warn("stop previous live translation", "error", err)
+	}
+
 	cfg := s.buildLiveConfig()
+	if cfg.AudioSource.UsesMicrophone() && cfg.AudioDeviceID != "" && !audioDeviceConnected(cfg.AudioDeviceID, audiocapture.ListDevices) {
+		slog.Warn("saved audio device not connected, using default", "device", cfg.AudioDeviceID)
+		evDeviceMissing.Emit(s.emit, cfg.AudioDeviceID)
+		cfg.AudioDeviceID = ""
+	}
 
-	translator, err := livetranslate.New(cfg)
+	var preferred string
+	if sc := s.cfg.GetSpeechConfig(); sc != nil {
+		preferred = sc.Provider
+	}
+	provider, err := s.providers.Select(preferred)
+	if err != nil {
+		return err
+	}
+
+	translator, err := provider.New(cfg)
+	if errors.Is(err, audiocapture.ErrUnsupportedOSVersion) {
+		// Only live mode depends on system audio; everything else keeps working.
+		return fmt.Errorf("live translation requires macOS %s or later", audiocapture.MinOSVersion)
+	}
 	if err != nil {
 		return err
 	}
@@ -208,13 +355,63 @@ func (s *Service) StartLiveTranslation(sourceLang, targetLang string) error {
 	if err := s.live.Start(context.Background(), translator, sourceLang, targetLang); err != nil {
 		return err
 	}
+	s.setupSegmentRollover()
+	s.segments.Reset()
+
+	breaker := s.newLiveBreaker()
+	history := newLiveContext(liveContextSize)
+
+	var opts ForwardOptions
+	if speechCfg := s.cfg.GetSpeechConfig(); speechCfg != nil {
+		opts.IdleTimeout = time.Duration(speechCfg.IdleTimeout) * time.Second
+		opts.SilenceFinalize = time.Duration(speechCfg.FinalizeAfterSilence) * time.Second
+		opts.MaxCaptionChars = speechCfg.MaxCaptionChars
+		if speechCfg.LockDirection {
+			opts.Lock = &DirectionLock{SourceLang: sourceLang, TargetLang: targetLang}
+		}
+	}
+
+	// Offline without a local translation model, show source captions only.
+	sourceOnly := s.checkOfflineProfile() != nil
 
 	// Forward events in background
-	go s.live.ForwardEvents(s.emit, s.translateAndEmit)
+	go s.live.ForwardEvents(translator, s.emit, func(t types.LiveTranscript) {
+		if sourceOnly {
+			s.segments.Put(t)
+			return
+		}
+		s.translateAndEmit(breaker, history, opts.MaxCaptionChars, t)
+	}, opts)
 
 	return nil
 }
 
+// setupSegmentRollover bounds the in-memory session segments, flushing older
+// ones to a file in the config directory.
+func (s *Service) setupSegmentRollover() {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		slog.Warn("get config dir for session spill", "error", err)
+		return
+	}
+	var limit int
+	if sc := s.cfg.GetSpeechConfig(); sc != nil {
+		limit = sc.MaxSessionSegments
+	}
+	s.segments.SetRollover(limit, fileSpill{path: filepath.Join(configDir, "transy", "live-session.jsonl")})
+}
+
+func (s *Service) newLiveBreaker() *translateBreaker {
+	speechCfg := s.cfg.GetSpeechConfig()
+	if speechCfg == nil {
+		return newTranslateBreaker(0, 0)
+	}
+	return newTranslateBreaker(
+		speechCfg.TranslateMaxFailures,
+		time.Duration(speechCfg.TranslateCooldown)*time.Second,
+	)
+}
+
 func (s *Service) buildLiveConfig() livetranslate.Config {
 	speechCfg := s.cfg.GetSpeechConfig()
 
@@ -222,47 +419,287 @@ func (s *Service) buildLiveConfig() livetranslate.Config {
 	if speechCfg != nil && speechCfg.CredentialID != "" {
 		if cred := s.cfg.GetCredential(speechCfg.CredentialID); cred != nil {
 			cfg.APIKey = cred.APIKey
+			cfg.OrgID = cred.OrgID
+			cfg.ProjectID = cred.ProjectID
 		}
 		cfg.Model = speechCfg.Model
 		cfg.SystemPrompt = "You are a professional translator. Translate the input audio text into the target language directly. Output only the translated text."
 		cfg.Temperature = 0.6
+		cfg.Normalize = speechCfg.NormalizeText
+		cfg.Prompt = speechCfg.Prompt
+		cfg.PromptContext = speechCfg.PromptContext
+		cfg.Diarize = speechCfg.Diarize
+		cfg.AudioSource = audiocapture.Source(speechCfg.AudioSource)
+		cfg.AudioDeviceID = speechCfg.AudioDeviceID
+		cfg.VAD = s.cfg.GetVADSettings()
+		cfg.MaxSpeaking = time.Duration(speechCfg.MaxSpeakingSeconds) * time.Second
 	}
 	return cfg
 }
 
-func (s *Service) translateAndEmit(t types.LiveTranscript) {
+func (s *Service) translateAndEmit(breaker *translateBreaker, history *liveContext, maxChars int, t types.LiveTranscript) {
+	// Auto mode may detect speech already in the target language.
+	t.TargetLang = s.targetPrefs().retarget(t.SourceLang, t.TargetLang)
+
+	// Previous sentences give the model context for coherent captions.
+	prev := history.Push(t.SourceText)
+	s.segments.Put(t)
+
+	// Source-only captions were already emitted; skip translation while degraded.
+	if !breaker.Allow() {
+		return
+	}
+
 	req := types.TranslateRequest{
 		Text:       t.SourceText,
 		SourceLang: t.SourceLang,
 		TargetLang: t.TargetLang,
+		Context:    prev,
 	}
+	// A stream can still fail after it opened, so the outcome is only
+	// known from the final chunk.
 	fullText := ""
-	err := s.translate(req, func(chunk TranslateChunk) {
-		fullText += chunk.Text
+	err := s.translate(context.Background(), req, func(chunk TranslateChunk) {
+		if chunk.Error != "" {
+			s.liveTranslateFailed(breaker, t.ID, chunk.Error)
+			return
+		}
+		if chunk.Done {
+			// The final chunk carries the complete text.
+			fullText = chunk.Text
+		} else {
+			fullText += chunk.Text
+		}
 		t.TargetText = fullText
-		s.emit(EventLiveTranscript, t)
+		evLiveTranscript.Emit(s.emit, displayCaption(t, maxChars))
+		if chunk.Done {
+			s.segments.Put(t)
+			breaker.Success()
+		}
 	})
 	if err != nil {
-		slog.Warn("async translate failed", "id", t.ID, "error", err)
-		return
+		s.liveTranslateFailed(breaker, t.ID, err.Error())
+	}
+}
+
+// liveTranslateFailed records a failed live translation on breaker and
+// emits evTranslateDegraded if that trips it.
+func (s *Service) liveTranslateFailed(breaker *translateBreaker, id, reason string) {
+	slog.Warn("live translate failed", "id", id, "error", reason)
+	if breaker.Failure() {
+		status := breaker.Status()
+		slog.Warn("live translation degraded", "failures", status.Failures, "retryAfter", status.RetryAfter)
+		evTranslateDegraded.Emit(s.emit, status)
 	}
 }
 
 // StopLiveTranslation stops real-time audio translation.
 func (s *Service) StopLiveTranslation() error {
+	s.liveMu.Lock()
+	defer s.liveMu.Unlock()
 	return s.live.Stop()
 }
 
+// GetAudioDevices returns the connected microphones, for choosing
+// SpeechConfig.AudioDeviceID.
+func (s *Service) GetAudioDevices() ([]audiocapture.Device, error) {
+	return audiocapture.ListDevices()
+}
+
+// GetVADSettings returns the voice activity detection settings for live
+// translation.
+func (s *Service) GetVADSettings() types.VADSettings {
+	return s.cfg.GetVADSettings()
+}
+
+// SetVADSettings saves new voice activity detection settings and applies
+// them to a running live session without restarting capture.
+func (s *Service) SetVADSettings(v types.VADSettings) error {
+	if err := s.cfg.SetVADSettings(v); err != nil {
+		return err
+	}
+	return s.live.SetVADConfig(v)
+}
+
 // GetLiveStatus returns the current live translation status.
 func (s *Service) GetLiveStatus() types.LiveStatus {
 	return s.live.Status()
 }
 
+// RetranslateSession translates every finalized segment of the current
+// session into targetLang with the active profile. Results are available
+// from GetSessionTranscripts; segments that fail keep an empty translation.
+func (s *Service) RetranslateSession(targetLang string) error {
+	segs := s.segments.Segments()
+	if len(segs) == 0 {
+		return fmt.Errorf("no session transcripts to retranslate")
+	}
+
+	out, err := retranslateSegments(context.Background(), segs, targetLang, translateWorkers, s.translateSync)
+	s.segments.SetTranslations(targetLang, out)
+	if err != nil {
+		return fmt.Errorf("retranslate session: %w", err)
+	}
+	return nil
+}
+
+// GetSessionTranscripts returns the finalized segments of the current
+// session. If targetLang names a language the session was re-translated
+// into, those translations are returned instead.
+func (s *Service) GetSessionTranscripts(targetLang string) []types.LiveTranscript {
+	if targetLang != "" {
+		if segs, ok := s.segments.Translations(targetLang); ok {
+			return segs
+		}
+	}
+	return s.segments.Segments()
+}
+
+// ExportSessionJSON returns the current session as a transcript.json
+// document: every finalized segment with both texts and languages, plus the
+// provider and model. targetLang selects re-translations as in
+// GetSessionTranscripts. ReadJSON in the subtitle package parses it back.
+func (s *Service) ExportSessionJSON(targetLang string, opts subtitle.Options) ([]byte, error) {
+	segs := s.GetSessionTranscripts(targetLang)
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("no session transcripts to export")
+	}
+
+	var session subtitle.Session
+	if sc := s.cfg.GetSpeechConfig(); sc != nil {
+		session.Model = sc.Model
+		if p, err := s.providers.Select(sc.Provider); err == nil {
+			session.Provider = p.Name()
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := subtitle.WriteJSON(&buf, session, segs, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportLiveSession writes the finalized segments of the current session
+// to path as bilingual subtitles, source line first. format is "srt",
+// "vtt" or "json"; the JSON document is the one ExportSessionJSON returns.
+func (s *Service) ExportLiveSession(format, path string) error {
+	opts := subtitle.Options{Order: subtitle.SourceFirst}
+
+	var data []byte
+	switch format {
+	case subtitle.FormatSRT, subtitle.FormatVTT:
+		segs := s.GetSessionTranscripts("")
+		if len(segs) == 0 {
+			return fmt.Errorf("no session transcripts to export")
+		}
+		write := subtitle.WriteSRT
+		if format == subtitle.FormatVTT {
+			write = subtitle.WriteVTT
+		}
+		var buf bytes.Buffer
+		if err := write(&buf, segs, opts); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	case "json":
+		var err error
+		if data, err = s.ExportSessionJSON("", opts); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported export format: %q", format)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write session export: %w", err)
+	}
+	return nil
+}
+
+// ImportSession replaces the current session with one exported by
+// ExportSessionJSON, so it can be corrected, re-translated or re-exported
+// without re-recording. Malformed documents are rejected and leave the
+// session unchanged. Live translation must be stopped first.
+func (s *Service) ImportSession(data []byte) error {
+	if s.live.Status().Active {
+		return fmt.Errorf("stop live translation before importing a session")
+	}
+	_, segs, err := subtitle.ReadJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("import session: %w", err)
+	}
+	s.segments.Import(segs)
+	return nil
+}
+
+// UpdateSessionSegment corrects the texts of a finalized segment of the
+// current or imported session.
+func (s *Service) UpdateSessionSegment(id, sourceText, targetText string) error {
+	if !s.segments.Edit(id, sourceText, targetText) {
+		return fmt.Errorf("segment not found: %s", id)
+	}
+	return nil
+}
+
+// TranslateSubtitleFile translates the SRT or WebVTT file at path into
+// targetLang with the active profile and returns the translated file.
+// Indices, timings and styling tags are preserved; cues are translated
+// concurrently with the previous cue as context.
+func (s *Service) TranslateSubtitleFile(path, targetLang string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read subtitle file: %w", err)
+	}
+	file, err := subtitle.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceLang, _ := langdetect.Detect(cueText(file.Cues))
+	cues, err := translateCues(context.Background(), file.Cues, sourceLang, targetLang, translateWorkers, s.translateSync)
+	if err != nil {
+		return nil, fmt.Errorf("translate subtitle file: %w", err)
+	}
+	file.Cues = cues
+	return file.Bytes(), nil
+}
+
+// GetRecentTranscripts returns the most recent finalized segments of the
+// current session, those still held in memory.
+func (s *Service) GetRecentTranscripts() []types.LiveTranscript {
+	return s.segments.Recent()
+}
+
+// RegisterExternalProvider adds a custom live translation provider, e.g. a
+// local STT engine in a fork. Set SpeechConfig.Provider to its name to
+// prefer it over the built-in provider.
+func (s *Service) RegisterExternalProvider(p livetranslate.Provider) error {
+	return s.providers.Register(p)
+}
+
+// GetLiveProviders returns the names of the registered live providers.
+func (s *Service) GetLiveProviders() []string {
+	return s.providers.Names()
+}
+
+// GetEffectiveSTTProvider returns the provider the next live session will
+// use, chosen exactly as StartLiveTranslation does. It fails if that
+// provider is not ready, e.g. has no API key.
+func (s *Service) GetEffectiveSTTProvider() (types.STTProviderInfo, error) {
+	var preferred string
+	if sc := s.cfg.GetSpeechConfig(); sc != nil {
+		preferred = sc.Provider
+	}
+	return s.providers.Effective(preferred, s.buildLiveConfig())
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Window & Clipboard
 // ─────────────────────────────────────────────────────────────────────────────
 
 // ToggleWindowVisibility shows the window with clipboard text.
+// The text is translated right away only if TranslateOnPaste is enabled.
 func (s *Service) ToggleWindowVisibility() {
 	text, err := clipboard.GetText(s.app)
 	if err != nil {
@@ -271,53 +708,225 @@ func (s *Service) ToggleWindowVisibility() {
 	}
 	s.showWindow()
 	if text != "" {
-		s.emit(EventSetClipboard, text)
+		evSetClipboard.Emit(s.emit, SourceText{Text: text, AutoTranslate: s.cfg.TranslateOnPasteEnabled()})
 	}
 }
 
+// SetTranslateOnPaste sets whether hotkey text is translated immediately.
+func (s *Service) SetTranslateOnPaste(enabled bool) error {
+	s.cfg.TranslateOnPaste = &enabled
+	return s.cfg.Save()
+}
+
+// showWindow shows the window, activating it unless ShowWithoutFocus is set
+// so the user can keep typing in the app they are reading.
 func (s *Service) showWindow() {
-	if s.window != nil {
-		s.window.Show()
+	if s.window == nil {
+		return
+	}
+	s.window.Show()
+	if !s.cfg.ShowWithoutFocus {
 		s.window.Focus()
 	}
 }
 
+// IsAlwaysOnTop reports whether the window is pinned above other apps.
+// A pinned window stays visible when it loses focus.
+func (s *Service) IsAlwaysOnTop() bool {
+	return s.cfg != nil && s.cfg.AlwaysOnTop
+}
+
+// SetAlwaysOnTop pins or unpins the window above other apps.
+func (s *Service) SetAlwaysOnTop(enabled bool) error {
+	s.cfg.AlwaysOnTop = enabled
+	if s.window != nil {
+		s.window.SetAlwaysOnTop(enabled)
+	}
+	return s.cfg.Save()
+}
+
+// SetShowWithoutFocus sets whether showing the window leaves focus on the
+// current app.
+func (s *Service) SetShowWithoutFocus(enabled bool) error {
+	s.cfg.ShowWithoutFocus = enabled
+	return s.cfg.Save()
+}
+
+// SetPasteBack sets whether TranslateClipboard pastes its result into the
+// focused app. Pasting requires accessibility permission.
+func (s *Service) SetPasteBack(enabled bool) error {
+	s.cfg.PasteBack = enabled
+	return s.cfg.Save()
+}
+
+// TranslateClipboard translates the clipboard text with the active profile
+// into the default target for its language and writes the result back to
+// the clipboard. With PasteBack enabled it then presses Cmd+V.
+func (s *Service) TranslateClipboard() (string, error) {
+	translated, err := translateClipboard(
+		func() (string, error) { return clipboard.GetText(s.app) },
+		func(text string) error { return clipboard.SetText(s.app, text) },
+		func(text string) (string, error) {
+			detected := s.DetectLanguage(text)
+			req := types.TranslateRequest{
+				Text:       text,
+				SourceLang: detected.Code,
+				TargetLang: detected.DefaultTarget,
+			}
+			res, err := s.translateSync(context.Background(), req)
+			if err == nil {
+				s.recordHistory(req, res.Text)
+			}
+			return res.Text, err
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if s.cfg.PasteBack {
+		if !hotkey.IsAccessibilityEnabled(false) {
+			return translated, errors.New("paste back requires accessibility permission")
+		}
+		hotkey.SimulatePaste()
+	}
+	return translated, nil
+}
+
 // TakeScreenshotAndOCR captures a screenshot and performs OCR.
 func (s *Service) TakeScreenshotAndOCR() (string, error) {
+	ctx, done := s.ops.Begin()
+	defer done()
+	return s.captureAndOCR(ctx, screenshot.CaptureInteractive, s.window != nil)
+}
+
+// RecaptureRegionOCR captures region without user interaction and performs
+// OCR, e.g. to re-read a fixed area of a video or slide deck. The window is
+// hidden during the capture only if it overlaps region.
+func (s *Service) RecaptureRegionOCR(region screenshot.Region) (string, error) {
+	hide := false
 	if s.window != nil {
+		b := s.window.Bounds()
+		bounds := screenshot.Region{X: b.X, Y: b.Y, Width: b.Width, Height: b.Height}
+		hide = hideForCapture(s.window.IsVisible(), bounds, region)
+	}
+	ctx, done := s.ops.Begin()
+	defer done()
+	return s.captureAndOCR(ctx, func(ctx context.Context) (string, error) {
+		return screenshot.CaptureRegion(ctx, region)
+	}, hide)
+}
+
+// captureAndOCR runs capture, performs OCR on the image and shows the
+// window with the text. If hide is set the window is hidden first so it
+// is not captured, and shown again if the capture fails. Cancelling ctx
+// abandons the flow like dismissing the capture does.
+func (s *Service) captureAndOCR(ctx context.Context, capture func(context.Context) (string, error), hide bool) (string, error) {
+	if hide {
 		s.window.Hide()
+		time.Sleep(100 * time.Millisecond)
+	}
+	restore := func() {
+		if hide {
+			s.window.Show()
+		}
 	}
-	time.Sleep(100 * time.Millisecond)
 
 	if !screenshot.HasPermission() {
 		screenshot.RequestPermission()
 		return "", fmt.Errorf("screen recording permission required")
 	}
 
-	imagePath, err := screenshot.CaptureInteractive()
+	imagePath, err := capture(ctx)
 	if err != nil {
-		if s.window != nil {
-			s.window.Show()
+		restore()
+		if errors.Is(err, screenshot.ErrCaptureCancelled) {
+			return "", nil
 		}
 		return "", fmt.Errorf("capture screenshot: %w", err)
 	}
 	defer os.Remove(imagePath)
 
-	text, err := ocr.RecognizeText(imagePath)
+	text, err := ocr.RecognizeTextWithLangs(imagePath, ocrLanguages(s.cfg.OCRLanguages, langdetect.SystemLanguage()))
 	if err != nil {
-		if s.window != nil {
-			s.window.Show()
-		}
+		restore()
 		return "", fmt.Errorf("recognize text: %w", err)
 	}
+	// Recognition can't be interrupted; drop its result instead.
+	if ctx.Err() != nil {
+		restore()
+		return "", nil
+	}
+
+	// Only pass on newly appeared lines when re-capturing scrolling content
+	if s.cfg.IncrementalOCR {
+		text = s.ocrSeen.NewLines(text)
+	}
 
 	s.showWindow()
 	if text != "" {
-		s.emit(EventSetClipboard, text)
+		evSetClipboard.Emit(s.emit, SourceText{Text: text, AutoTranslate: true})
 	}
 	return text, nil
 }
 
+// triggerOCR runs TakeScreenshotAndOCR in the background for a hotkey or
+// tray press. Presses during a capture are ignored or queued per
+// OCRHotkeyMode.
+func (s *Service) triggerOCR(source string) {
+	started := s.ocrFlight.Trigger(s.cfg.OCRHotkeyMode == OCRPressQueue, func() {
+		if _, err := s.TakeScreenshotAndOCR(); err != nil {
+			slog.Error("ocr screenshot", "source", source, "error", err)
+		}
+	})
+	if !started {
+		slog.Debug("ocr capture in progress, press ignored", "source", source)
+	}
+}
+
+// SetOCRHotkeyMode sets how OCR hotkey presses during a capture are
+// handled: OCRPressIgnore drops them, OCRPressQueue captures once more
+// afterwards.
+func (s *Service) SetOCRHotkeyMode(mode string) error {
+	if err := checkOCRPressMode(mode); err != nil {
+		return err
+	}
+	s.cfg.OCRHotkeyMode = mode
+	return s.cfg.Save()
+}
+
+// GetOCRLanguages returns the languages OCR recognizes, most preferred
+// first, including the system language and English default.
+func (s *Service) GetOCRLanguages() []string {
+	return ocrLanguages(s.cfg.OCRLanguages, langdetect.SystemLanguage())
+}
+
+// SetOCRLanguages sets the languages OCR recognizes, most preferred first.
+// Empty restores the system language and English default.
+func (s *Service) SetOCRLanguages(langs []string) error {
+	if err := checkOCRLanguages(langs); err != nil {
+		return err
+	}
+	s.cfg.OCRLanguages = slices.Clone(langs)
+	return s.cfg.Save()
+}
+
+// SetIncrementalOCR enables or disables incremental OCR.
+// Disabling it also forgets previously captured lines.
+func (s *Service) SetIncrementalOCR(enabled bool) error {
+	s.cfg.IncrementalOCR = enabled
+	if !enabled {
+		s.ocrSeen.Reset()
+	}
+	return s.cfg.Save()
+}
+
+// ResetOCRHistory forgets previously captured lines so the next capture
+// is emitted in full.
+func (s *Service) ResetOCRHistory() {
+	s.ocrSeen.Reset()
+}
+
 // GetAccessibilityPermission returns whether accessibility is enabled.
 func (s *Service) GetAccessibilityPermission() bool {
 	return hotkey.IsAccessibilityEnabled(false)
@@ -337,64 +946,164 @@ func (s *Service) RequestScreenRecordingPermission() {
 // Translation
 // ─────────────────────────────────────────────────────────────────────────────
 
-// TranslateChunk is the event payload for streaming translation.
-type TranslateChunk struct {
-	Text  string      `json:"text"`
-	Done  bool        `json:"done"`
-	Usage types.Usage `json:"usage,omitempty"`
-}
-
+// Translate translates text with streaming output via events.
+// Single-shot UI translations are sent without context.
+// It can be cancelled with CancelCurrentOperation.
 func (s *Service) Translate(req types.TranslateRequest) error {
-	return s.translate(req, func(chunk TranslateChunk) {
-		s.emit(EventTranslateChunk, chunk)
+	req.Context = ""
+	ctx, done := s.ops.Begin()
+	err := s.translate(ctx, req, func(chunk TranslateChunk) {
+		evTranslateChunk.Emit(s.emit, chunk)
+		if !chunk.Done {
+			return
+		}
+		done()
+		if chunk.Error == "" {
+			s.recordHistory(req, chunk.Text)
+		}
+		if chunk.Error == "" && s.cfg.AutoCopyStyle != "" {
+			if err := s.CopyTranslation(req, types.TranslateResult{Text: chunk.Text}, s.cfg.AutoCopyStyle); err != nil {
+				slog.Warn("auto copy translation", "error", err)
+			}
+		}
 	})
+	if err != nil {
+		done()
+	}
+	return err
+}
+
+// CancelCurrentOperation cancels the OCR captures and UI translations in
+// flight. A cancelled capture returns no text; a cancelled streaming
+// translation ends with an error chunk. It reports whether anything was
+// cancelled.
+func (s *Service) CancelCurrentOperation() bool {
+	return s.ops.CancelAll() > 0
+}
+
+// CopyTranslation writes the source and translation to the clipboard
+// formatted in style ("plain", "markdown" or "inline").
+func (s *Service) CopyTranslation(req types.TranslateRequest, result types.TranslateResult, style string) error {
+	text, err := FormatTranslationForClipboard(req, result, style)
+	if err != nil {
+		return err
+	}
+	return clipboard.SetText(s.app, text)
+}
+
+// SetPostProcessors sets the built-in post-processors applied to every
+// translation, in order (e.g. "trim", "strip_quotes", "strip_echo").
+func (s *Service) SetPostProcessors(names []string) error {
+	if err := s.usePostProcessors(names, s.cfg.EchoLabels); err != nil {
+		return err
+	}
+	s.cfg.PostProcessors = names
+	return s.cfg.Save()
+}
+
+// SetEstimateUsage sets whether translations estimate token usage when the
+// provider reports none, so usage reports aren't blank.
+func (s *Service) SetEstimateUsage(enabled bool) error {
+	s.cfg.EstimateUsage = enabled
+	if err := s.cfg.Save(); err != nil {
+		return err
+	}
+	s.translator.SetEstimateUsage(enabled)
+	return nil
+}
+
+// SetEchoLabels sets the output labels removed by the strip_echo
+// post-processor, by language. A language listed here replaces its
+// built-in labels.
+func (s *Service) SetEchoLabels(labels map[string][]string) error {
+	if err := s.usePostProcessors(s.cfg.PostProcessors, labels); err != nil {
+		return err
+	}
+	s.cfg.EchoLabels = labels
+	return s.cfg.Save()
+}
+
+// usePostProcessors sets the translator's post-processor chain from
+// built-in names.
+func (s *Service) usePostProcessors(names []string, echoLabels map[string][]string) error {
+	chain, err := postProcessorsByName(names, echoLabels)
+	if err != nil {
+		return err
+	}
+	s.translator.SetPostProcessors(postProcessorsID(names, echoLabels), chain...)
+	return nil
 }
 
-// TranslateWithLLMStream translates text with streaming output via events.
-func (s *Service) translate(req types.TranslateRequest, callback func(TranslateChunk)) error {
+// SetAutoCopyStyle sets the style used to copy each finished translation.
+// An empty style disables auto-copy.
+func (s *Service) SetAutoCopyStyle(style string) error {
+	if style != "" {
+		if _, err := FormatTranslationForClipboard(types.TranslateRequest{}, types.TranslateResult{}, style); err != nil {
+			return err
+		}
+	}
+	s.cfg.AutoCopyStyle = style
+	return s.cfg.Save()
+}
+
+// EstimateTranslationCost estimates the tokens and cost of translating req
+// with the active profile, without calling the LLM. Prices come from the
+// configured model prices, then the built-in list.
+func (s *Service) EstimateTranslationCost(req types.TranslateRequest) (CostEstimate, error) {
 	profile := s.cfg.GetActiveTranslationProfile()
 	if profile == nil {
-		return fmt.Errorf("no active translation profile")
+		return CostEstimate{}, fmt.Errorf("no active translation profile")
 	}
+	return estimateTranslation(s.translateProfile(profile), req, s.cfg.ModelPrices), nil
+}
 
-	cred := s.cfg.GetCredential(profile.CredentialID)
-	if cred == nil {
-		return fmt.Errorf("credential not found: %s", profile.CredentialID)
+// SetModelPrices saves custom model prices, in USD per million tokens,
+// for cost estimates and the usage export.
+func (s *Service) SetModelPrices(prices map[string]types.ModelPrice) error {
+	return s.cfg.SetModelPrices(prices)
+}
+
+// PreviewTranslation returns the messages that would be sent for req using
+// the active profile, along with an estimated prompt token count.
+// No network call is made.
+func (s *Service) PreviewTranslation(req types.TranslateRequest) (PromptPreview, error) {
+	profile := s.cfg.GetActiveTranslationProfile()
+	if profile == nil {
+		return PromptPreview{}, fmt.Errorf("no active translation profile")
 	}
+	return s.translator.Preview(s.translateProfile(profile), req), nil
+}
 
-	// Check cache first
-	key := s.translator.cacheKey(TranslateProfile{
-		Name:  profile.Name,
-		Model: profile.Model,
-	}, req)
-	if cached, ok := s.translator.getCached(key); ok {
-		// Emit cached result immediately
-		callback(TranslateChunk{
-			Text:  cached.Text,
-			Done:  true,
-			Usage: cached.Usage,
-		})
-		return nil
+// translateProfile converts profile for the Translator, resolving its
+// glossary reference.
+func (s *Service) translateProfile(profile *types.TranslationProfile) TranslateProfile {
+	tp := translateProfileOf(profile)
+	tp.Glossary = s.cfg.GlossaryTerms(profile.GlossaryID)
+	return tp
+}
+
+// translate translates req with the active profile, streaming chunks to
+// callback. The last chunk has Done set, also when the stream fails or ctx
+// is cancelled.
+func (s *Service) translate(ctx context.Context, req types.TranslateRequest, callback func(TranslateChunk)) error {
+	profile, cred, err := s.translationProfile("")
+	if err != nil {
+		return err
 	}
 
-	completer := llm.NewCompleter(cred.Type, cred.APIKey, cred.BaseURL, profile.Model, llm.Options{
-		MaxTokens:       profile.MaxTokens,
-		Temperature:     profile.Temperature,
-		DisableThinking: profile.DisableThinking,
-	})
+	tp := s.translateProfile(profile)
+	completer := newCompleter(cred, profile, req)
 
-	// Check if completer supports streaming
+	// Check if completer supports streaming. Placeholders can only be
+	// restored on the full text, so such requests don't stream.
 	streamer, ok := completer.(llm.StreamCompleter)
-	if !ok {
+	if !ok || tp.needsRestore(req) {
 		// Fallback to non-streaming
-		result, err := s.translator.Translate(context.Background(), completer, TranslateProfile{
-			Name:         profile.Name,
-			Model:        profile.Model,
-			SystemPrompt: profile.SystemPrompt,
-		}, req)
+		result, err := s.translator.Translate(ctx, completer, tp, req)
 		if err != nil {
 			return err
 		}
+		s.recordUsage(tp, result.Usage)
 		callback(TranslateChunk{
 			Text:  result.Text,
 			Done:  true,
@@ -403,49 +1112,317 @@ func (s *Service) translate(req types.TranslateRequest, callback func(TranslateC
 		return nil
 	}
 
-	// Build messages
-	msgs := buildTranslateMessages(profile.SystemPrompt, req)
-
-	// Start streaming
-	ch, err := streamer.StreamComplete(context.Background(), msgs)
+	results, err := s.translator.TranslateStream(ctx, streamer, tp, req)
 	if err != nil {
-		return fmt.Errorf("stream translate: %w", err)
+		return err
 	}
 
-	// Process stream in goroutine
+	// Forward results as deltas; the final chunk carries the complete text.
 	go func() {
-		// [PIKE FIX] Panic recovery to prevent silent goroutine death
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("panic in translate stream", "recover", r)
+		var sent string
+		for res := range results {
+			if res.Error != "" {
+				// The partial text was already sent.
+				callback(TranslateChunk{Done: true, Usage: res.Usage, Error: res.Error})
+				return
 			}
-		}()
-
-		var fullText string
-		var usage types.Usage
-		for delta := range ch {
-			if delta.Text != "" {
-				fullText += delta.Text
-				callback(TranslateChunk{
-					Text: delta.Text,
-				})
-			}
-			if delta.Done {
-				usage = delta.Usage
-				callback(TranslateChunk{
-					Text:  fullText,
-					Done:  delta.Done,
-					Usage: delta.Usage,
-				})
+			if res.Done {
+				s.recordUsage(tp, res.Usage)
+				callback(TranslateChunk{Text: res.Text, Done: true, Usage: res.Usage})
+				return
 			}
+			callback(TranslateChunk{Text: strings.TrimPrefix(res.Text, sent)})
+			sent = res.Text
 		}
-		// Cache the complete result
-		s.translator.setCache(key, fullText, usage)
+		// Cancelled: the stream closes without a final result.
+		callback(TranslateChunk{Done: true, Error: "translation cancelled"})
 	}()
 
 	return nil
 }
 
+// translationProfile returns the translation profile with id, or the
+// active one if id is empty, and its credential. It fails if either is
+// missing or offline mode rules the credential out.
+func (s *Service) translationProfile(id string) (*types.TranslationProfile, *types.APICredential, error) {
+	var profile *types.TranslationProfile
+	if id == "" {
+		if profile = s.cfg.GetActiveTranslationProfile(); profile == nil {
+			return nil, nil, fmt.Errorf("no active translation profile")
+		}
+	} else if profile = s.cfg.GetTranslationProfile(id); profile == nil {
+		return nil, nil, fmt.Errorf("profile not found: %s", id)
+	}
+
+	cred := s.cfg.GetCredential(profile.CredentialID)
+	if cred == nil {
+		return nil, nil, fmt.Errorf("credential not found: %s", profile.CredentialID)
+	}
+	if err := s.cfg.CheckOffline(cred); err != nil {
+		return nil, nil, err
+	}
+	return profile, cred, nil
+}
+
+// debugLLMEnv logs LLM request and response bodies, keys redacted, when
+// set to "1".
+const debugLLMEnv = "TRANSY_DEBUG_LLM"
+
+// newCompleter creates the LLM client for profile, sizing max tokens for req.
+func newCompleter(cred *types.APICredential, profile *types.TranslationProfile, req types.TranslateRequest) llm.Completer {
+	return llm.NewCompleter(cred.Type, cred.APIKey, cred.BaseURL, profile.Model, llm.Options{
+		MaxTokens:         maxTokensFor(profile.MaxTokens, profile.Model, req.Text),
+		Temperature:       profile.Temperature,
+		DisableThinking:   profile.DisableThinking,
+		OmitStreamOptions: cred.OmitStreamOptions,
+		OrgID:             cred.OrgID,
+		ProjectID:         cred.ProjectID,
+		APIVersion:        cred.APIVersion,
+		Debug:             os.Getenv(debugLLMEnv) == "1",
+	})
+}
+
+// translateSync translates req with the active profile and waits for the result.
+func (s *Service) translateSync(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+	profile, cred, err := s.translationProfile("")
+	if err != nil {
+		return types.TranslateResult{}, err
+	}
+
+	completer := newCompleter(cred, profile, req)
+
+	tp := s.translateProfile(profile)
+	result, err := s.translator.Translate(ctx, completer, tp, req)
+	if err == nil {
+		s.recordUsage(tp, result.Usage)
+	}
+	return result, err
+}
+
+// TranslateWithProfiles translates req with each of the given profiles
+// concurrently, for comparing models side by side. The active profile is
+// unchanged. Results are keyed by profile ID; a profile that is missing,
+// unusable or failed has Error set. The returned error is only for
+// problems that affect the whole request. It can be cancelled with
+// CancelCurrentOperation.
+func (s *Service) TranslateWithProfiles(ids []string, req types.TranslateRequest) (map[string]types.TranslateResult, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no profiles to translate with")
+	}
+
+	var runs []profileRun
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		runs = append(runs, s.newProfileRun(id, req))
+	}
+
+	ctx, done := s.ops.Begin()
+	defer done()
+
+	results := s.translator.translateEach(ctx, runs, req)
+	for _, run := range runs {
+		if r := results[run.id]; run.err == nil && r.Error == "" {
+			s.recordUsage(run.profile, r.Usage)
+		}
+	}
+	return results, nil
+}
+
+// newProfileRun prepares the profile with id to translate req.
+func (s *Service) newProfileRun(id string, req types.TranslateRequest) profileRun {
+	run := profileRun{id: id}
+	profile, cred, err := s.translationProfile(id)
+	if err != nil {
+		run.err = err
+		return run
+	}
+	run.profile = s.translateProfile(profile)
+	run.completer = newCompleter(cred, profile, req)
+	return run
+}
+
+// TranslateBatch translates several texts with the active profile, such as
+// UI strings or subtitle lines, using a few concurrent requests. Results
+// are in request order; an item that failed has Error set. The returned
+// error is only for problems that affect the whole batch. It can be
+// cancelled with CancelCurrentOperation.
+func (s *Service) TranslateBatch(reqs []types.TranslateRequest) ([]types.TranslateResult, error) {
+	profile, cred, err := s.translationProfile("")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := s.ops.Begin()
+	defer done()
+
+	tp := s.translateProfile(profile)
+	results := s.translator.TranslateBatch(ctx, func(req types.TranslateRequest) llm.Completer {
+		return newCompleter(cred, profile, req)
+	}, tp, reqs, translateWorkers)
+	for _, r := range results {
+		if r.Error == "" {
+			s.recordUsage(tp, r.Usage)
+		}
+	}
+	return results, nil
+}
+
+// recordUsage logs the tokens a translation with tp used. Cache hits cost
+// nothing and are skipped.
+func (s *Service) recordUsage(tp TranslateProfile, u types.Usage) {
+	if s.usage == nil || u.CacheHit || u.TotalTokens == 0 {
+		return
+	}
+	err := s.usage.Record(UsageRecord{
+		Time:             time.Now(),
+		Profile:          tp.Name,
+		Model:            tp.Model,
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+		Estimated:        u.Estimated,
+	})
+	if err != nil {
+		slog.Warn("record usage", "error", err)
+	}
+}
+
+// recordHistory adds a finished translation of req to the history without
+// waiting for the write.
+func (s *Service) recordHistory(req types.TranslateRequest, text string) {
+	if s.history == nil || text == "" {
+		return
+	}
+	var profile string
+	if p := s.cfg.GetActiveTranslationProfile(); p != nil {
+		profile = p.Name
+	}
+	s.history.Append(history.Entry{
+		SourceText: req.Text,
+		TargetText: text,
+		SourceLang: req.SourceLang,
+		TargetLang: req.TargetLang,
+		Profile:    profile,
+	})
+}
+
+// GetTranslationHistory returns up to limit past translations, newest
+// first, after skipping offset of them.
+func (s *Service) GetTranslationHistory(limit, offset int) ([]history.Entry, error) {
+	if s.history == nil {
+		return nil, nil
+	}
+	return s.history.List(limit, offset)
+}
+
+// SearchTranslationHistory returns the past translations whose source or
+// target text contains query, ignoring case, newest first.
+func (s *Service) SearchTranslationHistory(query string) ([]history.Entry, error) {
+	if s.history == nil {
+		return nil, nil
+	}
+	return s.history.Search(query)
+}
+
+// GetCacheStats returns the translation cache's hit and miss counts, and
+// how many entries and bytes it holds.
+func (s *Service) GetCacheStats() cache.Stats {
+	if s.cache == nil {
+		return cache.Stats{}
+	}
+	return s.cache.Stats()
+}
+
+// ClearCache removes every cached translation, including those on disk,
+// and returns how many were removed.
+func (s *Service) ClearCache() (int, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+	return s.cache.Clear()
+}
+
+// DeleteCacheEntry removes the cached translation stored under key.
+func (s *Service) DeleteCacheEntry(key string) error {
+	if s.cache == nil {
+		return nil
+	}
+	return s.cache.Delete(key)
+}
+
+// ExportUsageCSV returns the recorded translation usage with from <= time
+// < to as CSV, including an estimated cost for known models. A zero bound
+// is open; a range without usage yields just the header row.
+func (s *Service) ExportUsageCSV(from, to time.Time) ([]byte, error) {
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is before %s", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+	var records []UsageRecord
+	if s.usage != nil {
+		var err error
+		if records, err = s.usage.Load(from, to); err != nil {
+			return nil, err
+		}
+	}
+	return usageCSV(records, s.cfg.ModelPrices)
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Local Server
+// ─────────────────────────────────────────────────────────────────────────────
+
+// GetLocalServerConfig returns the local server configuration, including its token.
+func (s *Service) GetLocalServerConfig() *types.LocalServerConfig {
+	return s.cfg.GetLocalServerConfig()
+}
+
+// SetLocalServer enables or disables the local HTTP server on 127.0.0.1:port.
+// The setting is persisted; a running server is restarted on the new port.
+func (s *Service) SetLocalServer(enabled bool, port int) error {
+	sc := types.LocalServerConfig{Enabled: enabled, Port: port}
+	if cur := s.cfg.GetLocalServerConfig(); cur != nil {
+		sc.Token = cur.Token
+	}
+	if err := s.cfg.SetLocalServerConfig(sc); err != nil {
+		return err
+	}
+
+	s.stopServer()
+	if !enabled {
+		return nil
+	}
+	return s.startServer(*s.cfg.GetLocalServerConfig())
+}
+
+func (s *Service) startServer(sc types.LocalServerConfig) error {
+	s.serverMu.Lock()
+	defer s.serverMu.Unlock()
+
+	srv, err := startLocalServer(sc.Port, sc.Token, s.translateSync)
+	if err != nil {
+		return fmt.Errorf("start local server: %w", err)
+	}
+	s.server = srv
+	return nil
+}
+
+func (s *Service) stopServer() {
+	s.serverMu.Lock()
+	defer s.serverMu.Unlock()
+
+	if s.server == nil {
+		return
+	}
+	if err := s.server.Close(); err != nil {
+		slog.Error("stop local server", "error", err)
+	}
+	s.server = nil
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // API Credential Management
 // ─────────────────────────────────────────────────────────────────────────────
@@ -470,6 +1447,79 @@ func (s *Service) RemoveCredential(id string) error {
 	return s.cfg.RemoveCredential(id)
 }
 
+// TestCredential checks a saved credential with a minimal request, using
+// the model of a profile that references it, so settings can show whether
+// the key works. The error says whether the key was rejected, the model or
+// endpoint was not found, or the endpoint was unreachable.
+func (s *Service) TestCredential(id string) error {
+	cred := s.cfg.GetCredential(id)
+	if cred == nil {
+		return fmt.Errorf("credential not found: %s", id)
+	}
+	if err := s.cfg.CheckOffline(cred); err != nil {
+		return err
+	}
+	model, err := pingModel(cred, s.cfg.GetTranslationProfiles())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), llm.PingTimeout)
+	defer cancel()
+	return llm.Ping(ctx, cred.Type, cred.APIKey, cred.BaseURL, model, llm.Options{
+		OmitStreamOptions: cred.OmitStreamOptions,
+		OrgID:             cred.OrgID,
+		ProjectID:         cred.ProjectID,
+		APIVersion:        cred.APIVersion,
+		Debug:             os.Getenv(debugLLMEnv) == "1",
+	})
+}
+
+// SetOfflineMode sets whether providers that send data off the machine are
+// refused. Translation then needs a profile with a loopback endpoint, and
+// live translation a local provider; without a local translation model,
+// live captions show the source text only.
+func (s *Service) SetOfflineMode(enabled bool) error {
+	s.cfg.OfflineMode = enabled
+	if err := s.cfg.Save(); err != nil {
+		return err
+	}
+	s.providers.SetOffline(enabled)
+	return nil
+}
+
+// checkOfflineProfile returns config.ErrOfflineMode if offline mode forbids
+// translating with the active profile.
+func (s *Service) checkOfflineProfile() error {
+	var cred *types.APICredential
+	if profile := s.cfg.GetActiveTranslationProfile(); profile != nil {
+		cred = s.cfg.GetCredential(profile.CredentialID)
+	}
+	return s.cfg.CheckOffline(cred)
+}
+
+// SetAllowInsecureEndpoints sets whether credentials may use http:// base
+// URLs on remote hosts. Loopback hosts are always allowed.
+func (s *Service) SetAllowInsecureEndpoints(allow bool) error {
+	s.cfg.AllowInsecureEndpoints = allow
+	return s.cfg.Save()
+}
+
+// GetProxy returns the configured outbound proxy URL; empty means the
+// environment's proxy settings are used.
+func (s *Service) GetProxy() string {
+	return s.cfg.Proxy
+}
+
+// SetProxy saves the outbound proxy URL and applies it to new requests.
+// Empty falls back to HTTP_PROXY and HTTPS_PROXY.
+func (s *Service) SetProxy(raw string) error {
+	if err := s.cfg.SetProxy(raw); err != nil {
+		return err
+	}
+	return transport.SetProxy(s.cfg.Proxy)
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Translation Profile Management
 // ─────────────────────────────────────────────────────────────────────────────
@@ -484,6 +1534,12 @@ func (s *Service) GetActiveTranslationProfile() *types.TranslationProfile {
 	return s.cfg.GetActiveTranslationProfile()
 }
 
+// GetPromptTemplates returns the built-in system prompts for the profile
+// editor. A profile added without a prompt gets the general one.
+func (s *Service) GetPromptTemplates() []config.PromptTemplate {
+	return config.PromptTemplates()
+}
+
 // AddTranslationProfile adds a new translation profile.
 func (s *Service) AddTranslationProfile(profile types.TranslationProfile) error {
 	return s.cfg.AddTranslationProfile(profile)
@@ -523,38 +1579,180 @@ func (s *Service) SetSpeechConfig(cfg types.SpeechConfig) error {
 	return s.cfg.SetSpeechConfig(cfg)
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// Presets
+// ─────────────────────────────────────────────────────────────────────────────
+
+// GetPresets returns all presets.
+func (s *Service) GetPresets() []config.Preset {
+	return s.cfg.GetPresets()
+}
+
+// AddPreset adds a new preset.
+func (s *Service) AddPreset(p config.Preset) error {
+	return s.cfg.AddPreset(p)
+}
+
+// UpdatePreset updates an existing preset.
+func (s *Service) UpdatePreset(id string, p config.Preset) error {
+	return s.cfg.UpdatePreset(id, p)
+}
+
+// RemovePreset removes a preset by ID.
+func (s *Service) RemovePreset(id string) error {
+	return s.cfg.RemovePreset(id)
+}
+
+// ApplyPreset switches profile, formality, speech mode and language pair to the preset.
+// The applied preset is returned so the UI can adopt its languages and display mode.
+func (s *Service) ApplyPreset(id string) (*config.Preset, error) {
+	p, err := s.cfg.ApplyPreset(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.ProfileID != "" && s.trayMenu != nil {
+		s.rebuildProfileMenu()
+		s.trayMenu.Update()
+	}
+	return p, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Glossaries
+// ─────────────────────────────────────────────────────────────────────────────
+
+// GetGlossaries returns all glossaries.
+func (s *Service) GetGlossaries() []config.Glossary {
+	return s.cfg.GetGlossaries()
+}
+
+// AddGlossary adds a new glossary.
+func (s *Service) AddGlossary(g config.Glossary) error {
+	return s.cfg.AddGlossary(g)
+}
+
+// UpdateGlossary updates an existing glossary.
+func (s *Service) UpdateGlossary(id string, g config.Glossary) error {
+	return s.cfg.UpdateGlossary(id, g)
+}
+
+// RemoveGlossary removes a glossary by ID, detaching it from profiles.
+func (s *Service) RemoveGlossary(id string) error {
+	return s.cfg.RemoveGlossary(id)
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Language Settings
 // ─────────────────────────────────────────────────────────────────────────────
 
-// GetDefaultLanguages returns the default language mappings.
+// GetDefaultLanguages returns the default language mappings of the active
+// language preset.
 func (s *Service) GetDefaultLanguages() map[string]string {
-	return s.cfg.DefaultLanguages
+	return s.cfg.ActiveLanguages()
 }
 
-// SetDefaultLanguage sets the default target language for a source.
+// SetDefaultLanguage sets the default target language for a source in the
+// active language preset.
 func (s *Service) SetDefaultLanguage(src, dst string) error {
-	if s.cfg.DefaultLanguages == nil {
-		s.cfg.DefaultLanguages = make(map[string]string)
+	return s.cfg.SetDefaultLanguage(src, dst)
+}
+
+// GetLanguagePresets returns all language presets.
+func (s *Service) GetLanguagePresets() []config.LanguagePreset {
+	return s.cfg.GetLanguagePresets()
+}
+
+// AddLanguagePreset adds a new language preset.
+func (s *Service) AddLanguagePreset(p config.LanguagePreset) error {
+	return s.cfg.AddLanguagePreset(p)
+}
+
+// UpdateLanguagePreset updates an existing language preset.
+func (s *Service) UpdateLanguagePreset(id string, p config.LanguagePreset) error {
+	return s.cfg.UpdateLanguagePreset(id, p)
+}
+
+// RemoveLanguagePreset removes a language preset by ID.
+func (s *Service) RemoveLanguagePreset(id string) error {
+	return s.cfg.RemoveLanguagePreset(id)
+}
+
+// SetActiveLanguagePreset switches the language preset DetectLanguage
+// consults for default targets. Presets bundling a profile and speech mode
+// are switched with ApplyPreset instead.
+func (s *Service) SetActiveLanguagePreset(id string) error {
+	return s.cfg.SetActiveLanguagePreset(id)
+}
+
+// GetPreferredTargetLang returns the preferred and secondary target
+// languages used by DetectLanguage.
+func (s *Service) GetPreferredTargetLang() (preferred, secondary string) {
+	return s.cfg.PreferredTargetLang, s.cfg.SecondaryTargetLang
+}
+
+// SetPreferredTargetLang sets the target DetectLanguage suggests for every
+// source except preferred itself, which goes to secondary. Empty preferred
+// restores the per-source defaults.
+func (s *Service) SetPreferredTargetLang(preferred, secondary string) error {
+	for _, code := range []string{preferred, secondary} {
+		if code != "" && !langdetect.IsSupported(code) {
+			return fmt.Errorf("unsupported language: %q", code)
+		}
+	}
+	if preferred != "" && secondary == preferred {
+		return fmt.Errorf("secondary language must differ from preferred %q", preferred)
 	}
-	s.cfg.DefaultLanguages[src] = dst
+	s.cfg.PreferredTargetLang = preferred
+	s.cfg.SecondaryTargetLang = secondary
+	return s.cfg.Save()
+}
+
+// GetQuickLanguages returns the user's shortlist of language codes.
+func (s *Service) GetQuickLanguages() []string {
+	return s.cfg.QuickLanguages
+}
+
+// SetQuickLanguages replaces the shortlist of language codes.
+// Every code must be supported; duplicates are dropped, order is kept.
+func (s *Service) SetQuickLanguages(codes []string) error {
+	seen := make(map[string]bool, len(codes))
+	quick := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if !langdetect.IsSupported(code) {
+			return fmt.Errorf("unsupported language: %q", code)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		quick = append(quick, code)
+	}
+	s.cfg.QuickLanguages = quick
 	return s.cfg.Save()
 }
 
 // DetectLanguage detects the language of the given text.
 func (s *Service) DetectLanguage(text string) types.DetectResult {
 	code, name := langdetect.Detect(text)
+	return detectResult(code, name, s.targetPrefs())
+}
 
-	target := "en"
-	if code != "auto" && s.cfg.DefaultLanguages != nil {
-		if t, ok := s.cfg.DefaultLanguages[code]; ok {
-			target = t
-		}
+// targetPrefs returns the configured default target selection.
+func (s *Service) targetPrefs() targetPrefs {
+	prefs := targetPrefs{
+		defaults:  s.cfg.ActiveLanguages(),
+		preferred: s.cfg.PreferredTargetLang,
+		secondary: s.cfg.SecondaryTargetLang,
 	}
-
-	return types.DetectResult{
-		Code:          code,
-		Name:          name,
-		DefaultTarget: target,
+	if s.cfg.TargetFromLocale {
+		prefs.locale = langdetect.SystemLanguage()
 	}
+	return prefs
+}
+
+// SetTargetFromLocale sets whether sources without a default mapping are
+// translated into the system language rather than English.
+func (s *Service) SetTargetFromLocale(enabled bool) error {
+	s.cfg.TargetFromLocale = enabled
+	return s.cfg.Save()
 }
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"log/slog"
+
+	"go.aimuz.me/transy/audiocapture"
+)
+
+// audioDeviceConnected reports whether the device with the given ID is in
+// the list returned by list. If the devices can't be listed it assumes
+// so, leaving capture to report the problem.
+func audioDeviceConnected(id string, list func() ([]audiocapture.Device, error)) bool {
+	devices, err := list()
+	if err != nil {
+		slog.Warn("list audio devices", "error", err)
+		return true
+	}
+	for _, d := range devices {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
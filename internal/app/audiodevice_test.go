@@ -0,0 +1,35 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"go.aimuz.me/transy/audiocapture"
+)
+
+func TestAudioDeviceConnected(t *testing.T) {
+	devices := func() ([]audiocapture.Device, error) {
+		return []audiocapture.Device{{ID: "builtin", Name: "MacBook Pro Microphone"}, {ID: "usb-1", Name: "USB Headset"}}, nil
+	}
+	failing := func() ([]audiocapture.Device, error) {
+		return nil, errors.New("no audio")
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		list func() ([]audiocapture.Device, error)
+		want bool
+	}{
+		{"connected", "usb-1", devices, true},
+		{"unplugged", "usb-2", devices, false},
+		{"list fails", "usb-2", failing, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := audioDeviceConnected(tt.id, tt.list); got != tt.want {
+				t.Errorf("audioDeviceConnected(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
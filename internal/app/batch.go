@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+// translateWorkers bounds concurrent requests when translating many texts
+// at once: batches, session re-translation and subtitles.
+const translateWorkers = 4
+
+// forEachLimit calls fn for each index below n, with at most workers calls
+// running at once, and returns when all have.
+func forEachLimit(n, workers int, fn func(i int)) {
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			fn(i)
+		})
+	}
+	wg.Wait()
+}
+
+// TranslateBatch translates reqs with profile, returning one result per
+// request in input order. Cached items are answered first; the rest are
+// translated with at most workers concurrent requests, each with the
+// completer newCompleter returns for it. Identical uncached requests are
+// translated once, the copies reporting a cache hit. A failed item carries
+// its error in Error without affecting the others.
+func (t *Translator) TranslateBatch(ctx context.Context, newCompleter func(types.TranslateRequest) llm.Completer, profile TranslateProfile, reqs []types.TranslateRequest, workers int) []types.TranslateResult {
+	results := make([]types.TranslateResult, len(reqs))
+
+	// Group cache misses by key so duplicates cost one request.
+	var keys []string
+	pending := make(map[string][]int)
+	for i, req := range reqs {
+		key := t.cacheKey(profile, req)
+		if res, ok := t.getCached(key); ok {
+			results[i] = res
+			continue
+		}
+		if _, ok := pending[key]; !ok {
+			keys = append(keys, key)
+		}
+		pending[key] = append(pending[key], i)
+	}
+
+	forEachLimit(len(keys), workers, func(k int) {
+		idx := pending[keys[k]]
+		req := reqs[idx[0]]
+		res, err := t.Translate(ctx, newCompleter(req), profile, req)
+		if err != nil {
+			res = types.TranslateResult{Error: err.Error()}
+		}
+		results[idx[0]] = res
+		for _, i := range idx[1:] {
+			dup := res
+			if err == nil {
+				dup.Usage = types.Usage{CacheHit: true}
+			}
+			results[i] = dup
+		}
+	})
+
+	return results
+}
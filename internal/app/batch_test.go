@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/cache"
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+// batchCompleter "translates" by upper-casing the text after the prompt's
+// last blank line. Earlier items take longer, so completions arrive out
+// of order. It fails for texts containing "fail".
+type batchCompleter struct {
+	calls    *atomic.Int32
+	inFlight *atomic.Int32
+	peak     *atomic.Int32
+	delay    time.Duration
+}
+
+func (m batchCompleter) Complete(_ context.Context, msgs []llm.Message) (string, types.Usage, error) {
+	m.calls.Add(1)
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		p := m.peak.Load()
+		if n <= p || m.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(m.delay)
+
+	content := msgs[len(msgs)-1].Content
+	text := content[strings.LastIndex(content, "\n\n")+2:]
+	if strings.Contains(text, "fail") {
+		return "", types.Usage{}, errors.New("provider error")
+	}
+	return strings.ToUpper(text), types.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}, nil
+}
+
+func TestTranslatorTranslateBatch(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+
+	tr := NewTranslator(c)
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	req := func(text string) types.TranslateRequest {
+		return types.TranslateRequest{Text: text, SourceLang: "en", TargetLang: "zh"}
+	}
+
+	var calls, inFlight, peak atomic.Int32
+	newCompleter := func(r types.TranslateRequest) llm.Completer {
+		// Earlier texts are slower, so workers finish in reverse order.
+		return batchCompleter{calls: &calls, inFlight: &inFlight, peak: &peak, delay: time.Duration(10-len(r.Text)) * 5 * time.Millisecond}
+	}
+
+	// Seed the cache with one item.
+	if _, err := tr.Translate(context.Background(), newCompleter(req("cached")), profile, req("cached")); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	calls.Store(0)
+
+	reqs := []types.TranslateRequest{
+		req("a"), req("bb"), req("cached"), req("fail"), req("ccc"), req("a"), req("dddd"), req("eeeee"),
+	}
+	const workers = 2
+	got := tr.TranslateBatch(context.Background(), newCompleter, profile, reqs, workers)
+
+	want := []struct {
+		text     string
+		err      bool
+		cacheHit bool
+	}{
+		{text: "A"},
+		{text: "BB"},
+		{text: "CACHED", cacheHit: true},
+		{err: true},
+		{text: "CCC"},
+		{text: "A", cacheHit: true}, // Duplicate of the first item
+		{text: "DDDD"},
+		{text: "EEEEE"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if got[i].Text != w.text {
+			t.Errorf("result %d text = %q, want %q", i, got[i].Text, w.text)
+		}
+		if (got[i].Error != "") != w.err {
+			t.Errorf("result %d error = %q, want error %v", i, got[i].Error, w.err)
+		}
+		if got[i].Usage.CacheHit != w.cacheHit {
+			t.Errorf("result %d cache hit = %v, want %v", i, got[i].Usage.CacheHit, w.cacheHit)
+		}
+	}
+
+	// One request per distinct uncached text: a, bb, fail, ccc, dddd, eeeee.
+	if n := calls.Load(); n != 6 {
+		t.Errorf("completer called %d times, want 6", n)
+	}
+	if p := peak.Load(); p > workers {
+		t.Errorf("peak concurrent requests = %d, want at most %d", p, workers)
+	}
+
+	// A second batch is served from the cache, except the failed item.
+	calls.Store(0)
+	again := tr.TranslateBatch(context.Background(), newCompleter, profile, reqs, workers)
+	for i, r := range again {
+		if i != 3 && !r.Usage.CacheHit {
+			t.Errorf("second batch result %d not a cache hit", i)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("second batch called the completer %d times, want 1", n)
+	}
+}
+
+func TestForEachLimit(t *testing.T) {
+	var running, peak atomic.Int32
+	var done [10]atomic.Bool
+	forEachLimit(len(done), 3, func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		done[i].Store(true)
+	})
+
+	for i := range done {
+		if !done[i].Load() {
+			t.Errorf("index %d not visited", i)
+		}
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", p)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"sync"
+	"time"
+)
+
+// Default live translation degradation policy.
+const (
+	defaultMaxTranslateFailures = 3
+	defaultTranslateCooldown    = 30 * time.Second
+)
+
+// TranslationDegraded is the event payload sent when live translation is
+// temporarily disabled after repeated failures.
+type TranslationDegraded struct {
+	Failures   int   `json:"failures"`   // Consecutive failures that tripped the breaker
+	RetryAfter int64 `json:"retryAfter"` // Seconds until translation is retried
+}
+
+// translateBreaker disables live translation after maxFailures consecutive
+// failures and re-enables it after cooldown or on the next success.
+// Safe for concurrent use.
+type translateBreaker struct {
+	maxFailures int
+	cooldown    time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	until    time.Time // Translation disabled until this time
+}
+
+// newTranslateBreaker creates a breaker. Zero values select the defaults.
+func newTranslateBreaker(maxFailures int, cooldown time.Duration) *translateBreaker {
+	if maxFailures <= 0 {
+		maxFailures = defaultMaxTranslateFailures
+	}
+	if cooldown <= 0 {
+		cooldown = defaultTranslateCooldown
+	}
+	return &translateBreaker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		now:         time.Now,
+	}
+}
+
+// Allow reports whether a translation should be attempted now.
+func (b *translateBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.now().Before(b.until)
+}
+
+// Success resets the failure count.
+func (b *translateBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.until = time.Time{}
+}
+
+// Failure records a failed translation. It returns true exactly when this
+// failure trips the breaker, so the caller can notify the UI once.
+func (b *translateBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures < b.maxFailures {
+		return false
+	}
+	// A failed trial after the cooldown trips again, but only notify on
+	// the transition from healthy.
+	tripped := b.failures == b.maxFailures
+	b.until = b.now().Add(b.cooldown)
+	return tripped
+}
+
+// Status returns the event payload describing the current degradation.
+func (b *translateBreaker) Status() TranslationDegraded {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var retry int64
+	if d := b.until.Sub(b.now()); d > 0 {
+		retry = int64(d.Round(time.Second).Seconds())
+	}
+	return TranslationDegraded{Failures: b.failures, RetryAfter: retry}
+}
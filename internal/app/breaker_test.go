@@ -0,0 +1,65 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTranslateBreaker(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := newTranslateBreaker(2, 10*time.Second)
+	b.now = func() time.Time { return now }
+
+	if !b.Allow() {
+		t.Fatal("new breaker should allow")
+	}
+
+	if b.Failure() {
+		t.Error("first failure should not trip")
+	}
+	if !b.Allow() {
+		t.Error("should allow below threshold")
+	}
+
+	if !b.Failure() {
+		t.Error("second failure should trip")
+	}
+	if b.Allow() {
+		t.Error("should not allow while tripped")
+	}
+	if got := b.Status().RetryAfter; got != 10 {
+		t.Errorf("RetryAfter = %d, want 10", got)
+	}
+
+	// Cooldown elapsed: trial allowed, a failed trial does not re-notify.
+	now = now.Add(11 * time.Second)
+	if !b.Allow() {
+		t.Error("should allow after cooldown")
+	}
+	if b.Failure() {
+		t.Error("failed trial should not notify again")
+	}
+	if b.Allow() {
+		t.Error("failed trial should disable again")
+	}
+
+	// Success resets and re-arms the notification.
+	b.Success()
+	if !b.Allow() {
+		t.Error("should allow after success")
+	}
+	b.Failure()
+	if !b.Failure() {
+		t.Error("should notify again after recovery")
+	}
+}
+
+func TestNewTranslateBreakerDefaults(t *testing.T) {
+	b := newTranslateBreaker(0, 0)
+	if b.maxFailures != defaultMaxTranslateFailures {
+		t.Errorf("maxFailures = %d, want %d", b.maxFailures, defaultMaxTranslateFailures)
+	}
+	if b.cooldown != defaultTranslateCooldown {
+		t.Errorf("cooldown = %v, want %v", b.cooldown, defaultTranslateCooldown)
+	}
+}
@@ -0,0 +1,10 @@
+package app
+
+import "go.aimuz.me/transy/screenshot"
+
+// hideForCapture reports whether the window must be hidden before capturing
+// region: only if it is visible and covers part of the region. Hiding an
+// unrelated window just makes it flicker.
+func hideForCapture(visible bool, window, region screenshot.Region) bool {
+	return visible && window.Overlaps(region)
+}
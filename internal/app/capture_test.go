@@ -0,0 +1,33 @@
+package app
+
+import (
+	"testing"
+
+	"go.aimuz.me/transy/screenshot"
+)
+
+func TestHideForCapture(t *testing.T) {
+	window := screenshot.Region{X: 100, Y: 100, Width: 400, Height: 300}
+	tests := []struct {
+		name    string
+		visible bool
+		region  screenshot.Region
+		want    bool
+	}{
+		{"overlapping", true, screenshot.Region{X: 450, Y: 350, Width: 200, Height: 200}, true},
+		{"inside window", true, screenshot.Region{X: 200, Y: 200, Width: 10, Height: 10}, true},
+		{"covers window", true, screenshot.Region{X: 0, Y: 0, Width: 1000, Height: 1000}, true},
+		{"disjoint", true, screenshot.Region{X: 600, Y: 100, Width: 200, Height: 200}, false},
+		{"touching edge", true, screenshot.Region{X: 500, Y: 100, Width: 200, Height: 200}, false},
+		{"hidden window", false, screenshot.Region{X: 200, Y: 200, Width: 100, Height: 100}, false},
+		{"empty region", true, screenshot.Region{X: 200, Y: 200}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hideForCapture(tt.visible, window, tt.region); got != tt.want {
+				t.Errorf("hideForCapture() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
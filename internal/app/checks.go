@@ -0,0 +1,99 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"go.aimuz.me/transy/audiocapture"
+	"go.aimuz.me/transy/config"
+	"go.aimuz.me/transy/internal/types"
+)
+
+// Startup check names.
+const (
+	CheckScreenRecording = "screen-recording"
+	CheckAccessibility   = "accessibility"
+	CheckAudioCapture    = "audio-capture"
+	CheckTranslation     = "translation"
+	CheckSpeech          = "speech"
+)
+
+// CheckResult is the outcome of a single startup check.
+type CheckResult struct {
+	Name    string `json:"name"`
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+func permissionCheck(name string, granted bool) CheckResult {
+	if granted {
+		return CheckResult{Name: name, OK: true}
+	}
+	return CheckResult{Name: name, Message: "permission not granted"}
+}
+
+func checkAudioCapture() CheckResult {
+	c, err := audiocapture.New(0)
+	if errors.Is(err, audiocapture.ErrUnsupportedOSVersion) {
+		return CheckResult{Name: CheckAudioCapture, Message: "live translation requires macOS " + audiocapture.MinOSVersion + " or later"}
+	}
+	if err != nil {
+		return CheckResult{Name: CheckAudioCapture, Message: err.Error()}
+	}
+	_ = c.Stop()
+	return CheckResult{Name: CheckAudioCapture, OK: true}
+}
+
+// checkTranslation verifies an active profile with a usable credential
+// exists, and that offline mode allows it.
+func checkTranslation(cfg *config.Config) CheckResult {
+	profile := cfg.GetActiveTranslationProfile()
+	if profile == nil {
+		return CheckResult{Name: CheckTranslation, Message: "no translation profile configured"}
+	}
+	cred := cfg.GetCredential(profile.CredentialID)
+	if cred == nil {
+		return CheckResult{Name: CheckTranslation, Message: "credential not found for profile " + profile.Name}
+	}
+	if cred.APIKey == "" {
+		return CheckResult{Name: CheckTranslation, Message: "credential " + cred.Name + " has no API key"}
+	}
+	if err := cfg.CheckOffline(cred); err != nil {
+		return CheckResult{Name: CheckTranslation, Message: "profile " + profile.Name + ": " + err.Error()}
+	}
+	return CheckResult{Name: CheckTranslation, OK: true}
+}
+
+// checkSpeech verifies the speech configuration, if speech is enabled.
+func checkSpeech(sc *types.SpeechConfig, cfg *config.Config) CheckResult {
+	if sc == nil || !sc.Enabled {
+		return CheckResult{Name: CheckSpeech, OK: true, Message: "speech disabled"}
+	}
+	cred := cfg.GetCredential(sc.CredentialID)
+	if cred == nil || cred.APIKey == "" {
+		return CheckResult{Name: CheckSpeech, Message: "speech credential missing or has no API key"}
+	}
+	return CheckResult{Name: CheckSpeech, OK: true}
+}
+
+// pingModels are the models used to test a credential that no profile
+// uses yet. Other types need a profile to name the model or deployment.
+var pingModels = map[string]string{
+	"openai": "gpt-4o-mini",
+	"claude": "claude-3-5-haiku-latest",
+	"gemini": "gemini-2.0-flash",
+}
+
+// pingModel returns the model to test cred with: that of the first profile
+// using it, or a cheap default for its provider.
+func pingModel(cred *types.APICredential, profiles []types.TranslationProfile) (string, error) {
+	for _, p := range profiles {
+		if p.CredentialID == cred.ID && p.Model != "" {
+			return p.Model, nil
+		}
+	}
+	if m, ok := pingModels[cred.Type]; ok {
+		return m, nil
+	}
+	return "", fmt.Errorf("no profile uses credential %s; add one to choose a model to test", cred.Name)
+}
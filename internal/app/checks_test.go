@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+
+	"go.aimuz.me/transy/config"
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestCheckTranslation(t *testing.T) {
+	creds := []types.APICredential{
+		{ID: "remote", Name: "Remote", APIKey: "sk", BaseURL: "https://api.openai.com/v1"},
+		{ID: "local", Name: "Local", APIKey: "x", BaseURL: "http://127.0.0.1:11434/v1"},
+		{ID: "nokey", Name: "NoKey"},
+	}
+
+	tests := []struct {
+		name    string
+		credID  string
+		offline bool
+		noProf  bool
+		wantOK  bool
+	}{
+		{"remote", "remote", false, false, true},
+		{"remote offline", "remote", true, false, false},
+		{"local offline", "local", true, false, true},
+		{"missing credential", "gone", false, false, false},
+		{"no API key", "nokey", false, false, false},
+		{"no profile", "", false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Credentials: creds, OfflineMode: tt.offline}
+			if !tt.noProf {
+				cfg.TranslationProfiles = []types.TranslationProfile{{ID: "p", Name: "P", CredentialID: tt.credID, Active: true}}
+			}
+			got := checkTranslation(cfg)
+			if got.Name != CheckTranslation || got.OK != tt.wantOK || (!got.OK && got.Message == "") {
+				t.Errorf("checkTranslation() = %+v, want OK %v", got, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckSpeech(t *testing.T) {
+	cfg := &config.Config{Credentials: []types.APICredential{
+		{ID: "c1", APIKey: "sk"},
+		{ID: "nokey"},
+	}}
+
+	tests := []struct {
+		name   string
+		sc     *types.SpeechConfig
+		wantOK bool
+	}{
+		{"not configured", nil, true},
+		{"disabled", &types.SpeechConfig{CredentialID: "gone"}, true},
+		{"enabled", &types.SpeechConfig{Enabled: true, CredentialID: "c1"}, true},
+		{"missing credential", &types.SpeechConfig{Enabled: true, CredentialID: "gone"}, false},
+		{"no API key", &types.SpeechConfig{Enabled: true, CredentialID: "nokey"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkSpeech(tt.sc, cfg)
+			if got.Name != CheckSpeech || got.OK != tt.wantOK {
+				t.Errorf("checkSpeech() = %+v, want OK %v", got, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPingModel(t *testing.T) {
+	profiles := []types.TranslationProfile{
+		{CredentialID: "other", Model: "gpt-4.1"},
+		{CredentialID: "c1", Model: "my-finetune"},
+	}
+
+	tests := []struct {
+		name    string
+		cred    types.APICredential
+		want    string
+		wantErr bool
+	}{
+		{"profile model", types.APICredential{ID: "c1", Type: "openai"}, "my-finetune", false},
+		{"openai default", types.APICredential{ID: "c2", Type: "openai"}, "gpt-4o-mini", false},
+		{"gemini default", types.APICredential{ID: "c2", Type: "gemini"}, "gemini-2.0-flash", false},
+		{"compatible needs profile", types.APICredential{ID: "c2", Type: "openai-compatible"}, "", true},
+		{"azure needs profile", types.APICredential{ID: "c2", Type: "azure-openai"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pingModel(&tt.cred, profiles)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("pingModel() = %q, %v, want %q (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
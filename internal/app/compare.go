@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+// profileRun is one profile to translate with in translateEach.
+type profileRun struct {
+	id        string
+	profile   TranslateProfile
+	completer llm.Completer
+	err       error // Why the profile can't be used; completer is then nil
+}
+
+// translateEach translates req with every run concurrently and returns the
+// results keyed by run id. Each run is cached under its own profile, as a
+// single translation would be. A run that can't be used or fails carries
+// its error in Error without affecting the others.
+func (t *Translator) translateEach(ctx context.Context, runs []profileRun, req types.TranslateRequest) map[string]types.TranslateResult {
+	var mu sync.Mutex
+	results := make(map[string]types.TranslateResult, len(runs))
+	var wg sync.WaitGroup
+	for _, run := range runs {
+		if run.err != nil {
+			mu.Lock()
+			results[run.id] = types.TranslateResult{Error: run.err.Error()}
+			mu.Unlock()
+			continue
+		}
+		wg.Go(func() {
+			res, err := t.Translate(ctx, run.completer, run.profile, req)
+			if err != nil {
+				res = types.TranslateResult{Error: err.Error()}
+			}
+			mu.Lock()
+			results[run.id] = res
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.aimuz.me/transy/cache"
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestTranslatorTranslateEach(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+	tr := NewTranslator(c)
+
+	usage := types.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7}
+	runs := []profileRun{
+		{id: "a", profile: TranslateProfile{Name: "A", Model: "gpt-4o"}, completer: &mockCompleter{response: "Hallo Welt", usage: usage}},
+		{id: "b", profile: TranslateProfile{Name: "B", Model: "claude-3-5-haiku"}, completer: &mockCompleter{response: "Servus Welt", usage: usage}},
+		{id: "c", profile: TranslateProfile{Name: "C", Model: "broken"}, completer: &mockCompleter{err: errors.New("provider down")}},
+		{id: "d", err: errors.New("profile not found: d")},
+	}
+	req := types.TranslateRequest{Text: "Hello world", SourceLang: "en", TargetLang: "de"}
+
+	got := tr.translateEach(context.Background(), runs, req)
+	want := map[string]string{"a": "Hallo Welt", "b": "Servus Welt"}
+	for id, text := range want {
+		if got[id].Text != text || got[id].Error != "" {
+			t.Errorf("result %s = %+v, want %q", id, got[id], text)
+		}
+	}
+	for _, id := range []string{"c", "d"} {
+		if got[id].Error == "" {
+			t.Errorf("result %s = %+v, want an error", id, got[id])
+		}
+	}
+	if len(got) != len(runs) {
+		t.Errorf("got %d results, want %d", len(got), len(runs))
+	}
+
+	// Each profile is cached separately.
+	for i := range runs[:2] {
+		runs[i].completer = &mockCompleter{err: errors.New("should be cached")}
+	}
+	again := tr.translateEach(context.Background(), runs[:2], req)
+	for id, text := range want {
+		if again[id].Text != text || !again[id].Usage.CacheHit {
+			t.Errorf("cached result %s = %+v, want %q from cache", id, again[id], text)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"go.aimuz.me/transy/internal/types"
+)
+
+// fallbackTarget is the target language when no default mapping applies
+// and the system language is unknown or not used.
+const fallbackTarget = "en"
+
+// targetPrefs selects the default target for a detected language.
+type targetPrefs struct {
+	defaults  map[string]string // per-source mapping
+	preferred string            // target for any other language; empty disables
+	secondary string            // target when the source is preferred
+	locale    string            // system language, replacing fallbackTarget; empty disables
+}
+
+// target returns the default target for code. A preferred language wins
+// over the per-source mapping; text already in it goes to the secondary,
+// then falls back to the mapping. Without a mapping, text is translated
+// into the system language, or fallbackTarget.
+func (p targetPrefs) target(code string) string {
+	if code == "auto" {
+		if p.preferred != "" {
+			return p.preferred
+		}
+		return p.fallback(code)
+	}
+	if p.preferred != "" && code != p.preferred {
+		return p.preferred
+	}
+	if p.preferred != "" && p.secondary != "" {
+		return p.secondary
+	}
+	if t, ok := p.defaults[code]; ok {
+		return t
+	}
+	return p.fallback(code)
+}
+
+// fallback returns the target for code when no mapping applies. Text
+// already in the system language goes to fallbackTarget.
+func (p targetPrefs) fallback(code string) string {
+	if p.locale != "" && p.locale != code {
+		return p.locale
+	}
+	return fallbackTarget
+}
+
+// retarget returns the target for text in source that was meant to go to
+// target. When auto-detection finds the speaker already using target, the
+// default target for source is used instead, if it differs.
+func (p targetPrefs) retarget(source, target string) string {
+	if source != target {
+		return target
+	}
+	if t := p.target(source); t != source {
+		return t
+	}
+	return target
+}
+
+// supports reports whether code is a language the translation settings
+// cover: a source of the per-source mapping, one of its targets, or the
+// preferred or secondary target. "auto" (detection failed) and an empty
+// mapping support every language.
+func (p targetPrefs) supports(code string) bool {
+	if code == "auto" || len(p.defaults) == 0 {
+		return true
+	}
+	if code == p.preferred || code == p.secondary {
+		return true
+	}
+	if _, ok := p.defaults[code]; ok {
+		return true
+	}
+	for _, t := range p.defaults {
+		if t == code {
+			return true
+		}
+	}
+	return false
+}
+
+// detectResult builds the detection result for code, picking the default
+// target from prefs and flagging languages prefs doesn't cover.
+func detectResult(code, name string, prefs targetPrefs) types.DetectResult {
+	return types.DetectResult{
+		Code:          code,
+		Name:          name,
+		DefaultTarget: prefs.target(code),
+		Supported:     prefs.supports(code),
+	}
+}
@@ -0,0 +1,113 @@
+package app
+
+import "testing"
+
+func TestDetectResult(t *testing.T) {
+	defaults := map[string]string{"zh": "en", "en": "zh", "ja": "zh"}
+
+	tests := []struct {
+		name          string
+		defaults      map[string]string
+		preferred     string
+		code          string
+		wantTarget    string
+		wantSupported bool
+	}{
+		{"mapped source", defaults, "", "en", "zh", true},
+		{"mapped target only", map[string]string{"ja": "zh"}, "", "zh", "en", true},
+		{"outside mapping", defaults, "", "fr", "en", false},
+		{"preferred target", defaults, "fr", "fr", "en", true},
+		{"empty mapping", nil, "", "fr", "en", true},
+		{"auto", defaults, "", "auto", "en", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := targetPrefs{defaults: tt.defaults, preferred: tt.preferred}
+			got := detectResult(tt.code, "", prefs)
+			if got.Code != tt.code || got.DefaultTarget != tt.wantTarget || got.Supported != tt.wantSupported {
+				t.Errorf("detectResult(%q) = %+v, want target %q supported %v", tt.code, got, tt.wantTarget, tt.wantSupported)
+			}
+		})
+	}
+}
+
+func TestTargetPrefsRetarget(t *testing.T) {
+	p := targetPrefs{defaults: map[string]string{"zh": "en", "en": "zh"}, preferred: "en", secondary: "ja"}
+
+	tests := []struct {
+		name, source, target, want string
+	}{
+		{"different languages kept", "zh", "en", "en"},
+		{"source is target uses secondary", "en", "en", "ja"},
+		{"source is target uses mapping", "zh", "zh", "en"},
+		{"no other target keeps it", "fr", "fr", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.retarget(tt.source, tt.target); got != tt.want {
+				t.Errorf("retarget(%q, %q) = %q, want %q", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetPrefsLocale(t *testing.T) {
+	defaults := map[string]string{"en": "zh"}
+
+	tests := []struct {
+		name     string
+		defaults map[string]string
+		locale   string
+		code     string
+		want     string
+	}{
+		{"mapping beats locale", defaults, "de", "en", "zh"},
+		{"locale for unmapped", defaults, "de", "fr", "de"},
+		{"locale without mapping", nil, "ja", "en", "ja"},
+		{"source in locale falls back", nil, "ja", "ja", "en"},
+		{"auto uses locale", defaults, "de", "auto", "de"},
+		{"no locale falls back", nil, "", "fr", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := targetPrefs{defaults: tt.defaults, locale: tt.locale}
+			if got := p.target(tt.code); got != tt.want {
+				t.Errorf("target(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetPrefs(t *testing.T) {
+	defaults := map[string]string{"zh": "en", "en": "zh"}
+
+	tests := []struct {
+		name      string
+		preferred string
+		secondary string
+		code      string
+		want      string
+	}{
+		{"no preference uses mapping", "", "", "en", "zh"},
+		{"no preference falls back", "", "", "fr", "en"},
+		{"preferred beats mapping", "ja", "", "en", "ja"},
+		{"preferred for unmapped", "ja", "", "fr", "ja"},
+		{"source is preferred uses secondary", "ja", "ko", "ja", "ko"},
+		{"source is preferred without secondary uses mapping", "zh", "", "zh", "en"},
+		{"source is preferred without secondary or mapping", "ja", "", "ja", "en"},
+		{"secondary ignored without preferred", "", "ko", "fr", "en"},
+		{"auto uses preferred", "ja", "ko", "auto", "ja"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := targetPrefs{defaults: defaults, preferred: tt.preferred, secondary: tt.secondary}
+			if got := p.target(tt.code); got != tt.want {
+				t.Errorf("target(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
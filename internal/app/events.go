@@ -1,11 +1,61 @@
 // Package app provides the core application service for Wails bindings.
 package app
 
-// Event names for frontend communication.
-const (
-	EventLiveTranscript    = "live-transcript"
-	EventVADUpdate         = "live-vad-update"
-	EventSetClipboard      = "set-clipboard-text"
-	EventAccessibilityPerm = "accessibility-permission"
-	EventTranslateChunk    = "translate-chunk"
+import "go.aimuz.me/transy/internal/types"
+
+// SourceText is the event payload for text placed into the source field.
+type SourceText struct {
+	Text          string `json:"text"`
+	AutoTranslate bool   `json:"autoTranslate"` // Translate immediately rather than waiting for the user
+}
+
+// TranslateChunk is the event payload for streaming translation.
+type TranslateChunk struct {
+	Text  string      `json:"text"`
+	Done  bool        `json:"done"`
+	Usage types.Usage `json:"usage,omitempty"`
+	Error string      `json:"error,omitempty"` // Set on the final chunk if the stream failed
+}
+
+// Event is a frontend event name bound to its payload type, so emitting
+// the wrong payload is a compile error rather than a silent UI mismatch.
+type Event[T any] struct {
+	Name string
+}
+
+// Emit sends data as event e through emit.
+func (e Event[T]) Emit(emit func(name string, data any), data T) {
+	emit(e.Name, data)
+}
+
+func (e Event[T]) name() string { return e.Name }
+
+// Typed events for frontend communication. Every event must also be
+// listed in knownEvents, from which registerEvents registers it.
+var (
+	evLiveTranscript    = Event[types.LiveTranscript]{"live-transcript"}
+	evVADUpdate         = Event[types.VADState]{"live-vad-update"}
+	evSetClipboard      = Event[SourceText]{"set-clipboard-text"}
+	evAccessibilityPerm = Event[bool]{"accessibility-permission"}
+	evTranslateChunk    = Event[TranslateChunk]{"translate-chunk"}
+	evTranslateDegraded = Event[TranslationDegraded]{"translation-degraded"}
+	evLiveAutoStopped   = Event[int64]{"live-auto-stopped"} // Idle timeout in seconds
+	evStartupChecks     = Event[[]CheckResult]{"startup-checks"}
+	evDeviceMissing     = Event[string]{"audio-device-missing"} // ID of the saved device
 )
+
+// knownEvents lists every event the backend emits.
+var knownEvents = []interface {
+	name() string
+	register()
+}{
+	evLiveTranscript,
+	evVADUpdate,
+	evSetClipboard,
+	evAccessibilityPerm,
+	evTranslateChunk,
+	evTranslateDegraded,
+	evLiveAutoStopped,
+	evStartupChecks,
+	evDeviceMissing,
+}
@@ -0,0 +1,27 @@
+package app
+
+import "testing"
+
+func TestKnownEventsUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, e := range knownEvents {
+		if seen[e.name()] {
+			t.Errorf("event %q listed twice", e.name())
+		}
+		seen[e.name()] = true
+	}
+}
+
+func TestEventEmit(t *testing.T) {
+	var gotName string
+	var gotData any
+	emit := func(name string, data any) { gotName, gotData = name, data }
+
+	evTranslateChunk.Emit(emit, TranslateChunk{Text: "hi", Done: true})
+	if gotName != evTranslateChunk.Name {
+		t.Errorf("name = %q, want %q", gotName, evTranslateChunk.Name)
+	}
+	if c, ok := gotData.(TranslateChunk); !ok || c.Text != "hi" {
+		t.Errorf("data = %#v, want TranslateChunk", gotData)
+	}
+}
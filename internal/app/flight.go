@@ -0,0 +1,61 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Handling of OCR hotkey presses while a capture is in flight.
+const (
+	OCRPressIgnore = "ignore" // Drop the press (default)
+	OCRPressQueue  = "queue"  // Capture once more after the current capture
+)
+
+// checkOCRPressMode returns an error if mode is not a known OCR press mode.
+// Empty selects the default.
+func checkOCRPressMode(mode string) error {
+	switch mode {
+	case "", OCRPressIgnore, OCRPressQueue:
+		return nil
+	}
+	return fmt.Errorf("unknown OCR hotkey mode: %s", mode)
+}
+
+// singleFlight runs at most one job at a time. A trigger during a run is
+// dropped, or with queue set, schedules one more run after it; further
+// triggers collapse into that single queued run.
+type singleFlight struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
+
+// Trigger starts fn in a new goroutine unless a run is in flight. It
+// reports whether fn was started or queued.
+func (f *singleFlight) Trigger(queue bool, fn func()) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.running {
+		f.pending = f.pending || queue
+		return queue
+	}
+	f.running = true
+	go f.run(fn)
+	return true
+}
+
+// run calls fn until no run is pending.
+func (f *singleFlight) run(fn func()) {
+	for {
+		fn()
+
+		f.mu.Lock()
+		if !f.pending {
+			f.running = false
+			f.mu.Unlock()
+			return
+		}
+		f.pending = false
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		queue    bool
+		wantRuns int32
+	}{
+		{"ignore", false, 1},
+		{"queue latest", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f singleFlight
+			var runs, active, overlapped atomic.Int32
+			release := make(chan struct{})
+			done := make(chan struct{}, 4)
+			fn := func() {
+				if active.Add(1) > 1 {
+					overlapped.Store(1)
+				}
+				runs.Add(1)
+				<-release
+				active.Add(-1)
+				done <- struct{}{}
+			}
+
+			if !f.Trigger(tt.queue, fn) {
+				t.Fatal("first trigger did not start")
+			}
+			// Presses during the capture.
+			for range 3 {
+				if got := f.Trigger(tt.queue, fn); got != tt.queue {
+					t.Errorf("Trigger() during run = %v, want %v", got, tt.queue)
+				}
+			}
+			close(release)
+			for range tt.wantRuns {
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					t.Fatal("run did not finish")
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			if got := runs.Load(); got != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", got, tt.wantRuns)
+			}
+			if overlapped.Load() != 0 {
+				t.Error("runs overlapped")
+			}
+
+			// Idle again: the next trigger starts a new run.
+			if !f.Trigger(tt.queue, fn) {
+				t.Error("trigger after runs finished did not start")
+			}
+			<-done
+		})
+	}
+}
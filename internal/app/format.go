@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// Clipboard output styles for a source/translation pair.
+const (
+	CopyStylePlain    = "plain"    // Source, blank line, translation
+	CopyStyleMarkdown = "markdown" // Quoted source followed by translation
+	CopyStyleInline   = "inline"   // "source — translation", line by line
+)
+
+// FormatTranslationForClipboard renders a source/translation pair in style.
+func FormatTranslationForClipboard(req types.TranslateRequest, result types.TranslateResult, style string) (string, error) {
+	src := strings.TrimSpace(req.Text)
+	dst := strings.TrimSpace(result.Text)
+
+	switch style {
+	case CopyStylePlain:
+		return src + "\n\n" + dst, nil
+
+	case CopyStyleMarkdown:
+		var b strings.Builder
+		for _, line := range strings.Split(src, "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " "))
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+		b.WriteString(dst)
+		return b.String(), nil
+
+	case CopyStyleInline:
+		srcLines := strings.Split(src, "\n")
+		dstLines := strings.Split(dst, "\n")
+		// Pair lines only when both sides line up; otherwise pair whole texts.
+		if len(srcLines) != len(dstLines) {
+			return strings.Join(srcLines, " ") + " — " + strings.Join(dstLines, " "), nil
+		}
+		out := make([]string, len(srcLines))
+		for i := range srcLines {
+			if strings.TrimSpace(srcLines[i]) == "" {
+				continue
+			}
+			out[i] = srcLines[i] + " — " + dstLines[i]
+		}
+		return strings.Join(out, "\n"), nil
+
+	default:
+		return "", fmt.Errorf("unknown copy style: %s", style)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestFormatTranslationForClipboard(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		dst     string
+		style   string
+		want    string
+		wantErr bool
+	}{
+		{"plain", "Hello", "你好", CopyStylePlain, "Hello\n\n你好", false},
+		{"markdown", "Hello", "你好", CopyStyleMarkdown, "> Hello\n\n你好", false},
+		{"markdown multi-line", "Hello\n\nWorld", "你好\n\n世界", CopyStyleMarkdown, "> Hello\n>\n> World\n\n你好\n\n世界", false},
+		{"inline", "Hello", "你好", CopyStyleInline, "Hello — 你好", false},
+		{"inline multi-line", "Hello\nWorld", "你好\n世界", CopyStyleInline, "Hello — 你好\nWorld — 世界", false},
+		{"inline mismatched lines", "Hello\nWorld", "你好世界", CopyStyleInline, "Hello World — 你好世界", false},
+		{"trims surrounding whitespace", "  Hello\n", "\n你好 ", CopyStylePlain, "Hello\n\n你好", false},
+		{"unknown style", "Hello", "你好", "fancy", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatTranslationForClipboard(
+				types.TranslateRequest{Text: tt.src},
+				types.TranslateResult{Text: tt.dst},
+				tt.style,
+			)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// mergeGlossary combines a profile glossary with per-request terms, the
+// request winning on conflicts. Neither input is modified.
+func mergeGlossary(profile, req map[string]string) map[string]string {
+	if len(profile) == 0 {
+		return req
+	}
+	if len(req) == 0 {
+		return profile
+	}
+	merged := maps.Clone(profile)
+	maps.Copy(merged, req)
+	return merged
+}
+
+// keepTerm reports whether a glossary entry means "do not translate".
+func keepTerm(term, translation string) bool {
+	translation = strings.TrimSpace(translation)
+	return translation == "" || translation == term
+}
+
+// glossaryInstruction tells the model which terms to keep verbatim and
+// which to translate a fixed way. Terms are sorted so the prompt, and
+// anything cached under it, is stable.
+func glossaryInstruction(glossary map[string]string) string {
+	var keep, fixed []string
+	for _, term := range slices.Sorted(maps.Keys(glossary)) {
+		if strings.TrimSpace(term) == "" {
+			continue
+		}
+		if tr := glossary[term]; keepTerm(term, tr) {
+			keep = append(keep, fmt.Sprintf("- %q", term))
+		} else {
+			fixed = append(fixed, fmt.Sprintf("- %q → %q", term, tr))
+		}
+	}
+
+	var parts []string
+	if len(keep) > 0 {
+		parts = append(parts, "Do not translate these terms; keep them exactly as written:\n"+strings.Join(keep, "\n"))
+	}
+	if len(fixed) > 0 {
+		parts = append(parts, "Always translate these terms as given:\n"+strings.Join(fixed, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// glossaryKey renders a glossary deterministically for use in cache keys.
+func glossaryKey(glossary map[string]string) string {
+	var b strings.Builder
+	for _, term := range slices.Sorted(maps.Keys(glossary)) {
+		fmt.Fprintf(&b, "glossary: %q => %q\n", term, glossary[term])
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestGlossaryInstruction(t *testing.T) {
+	tests := []struct {
+		name     string
+		glossary map[string]string
+		want     string
+	}{
+		{name: "empty", glossary: nil, want: ""},
+		{
+			name:     "keep verbatim",
+			glossary: map[string]string{"Transy": "Transy", "macOS": ""},
+			want:     "Do not translate these terms; keep them exactly as written:\n- \"Transy\"\n- \"macOS\"",
+		},
+		{
+			name:     "fixed translation",
+			glossary: map[string]string{"token": "令牌", "cache": "缓存"},
+			want:     "Always translate these terms as given:\n- \"cache\" → \"缓存\"\n- \"token\" → \"令牌\"",
+		},
+		{
+			name:     "both kinds",
+			glossary: map[string]string{"Transy": "Transy", "token": "令牌"},
+			want: "Do not translate these terms; keep them exactly as written:\n- \"Transy\"\n\n" +
+				"Always translate these terms as given:\n- \"token\" → \"令牌\"",
+		},
+		{
+			name:     "blank term skipped",
+			glossary: map[string]string{" ": "x"},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := glossaryInstruction(tt.glossary); got != tt.want {
+				t.Errorf("glossaryInstruction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeGlossary(t *testing.T) {
+	profile := map[string]string{"token": "令牌", "cache": "缓存"}
+	req := map[string]string{"token": "词元"}
+
+	got := mergeGlossary(profile, req)
+	want := map[string]string{"token": "词元", "cache": "缓存"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeGlossary() = %v, want %v", got, want)
+	}
+	if profile["token"] != "令牌" {
+		t.Error("mergeGlossary() modified the profile glossary")
+	}
+	if got := mergeGlossary(nil, req); !reflect.DeepEqual(got, req) {
+		t.Errorf("mergeGlossary(nil, req) = %v, want %v", got, req)
+	}
+}
+
+func TestProfileGlossaryPrompt(t *testing.T) {
+	p := TranslateProfile{
+		Name:         "test",
+		SystemPrompt: "Translate.",
+		Glossary:     map[string]string{"Transy": "Transy"},
+	}
+	req := types.TranslateRequest{
+		Text:       "Transy caches every token",
+		SourceLang: "en",
+		TargetLang: "zh",
+		Glossary:   map[string]string{"token": "令牌"},
+	}
+
+	user := p.messages(req)[1].Content
+	for _, want := range []string{`- "Transy"`, `- "token" → "令牌"`, "Transy caches every token"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("user message missing %q:\n%s", want, user)
+		}
+	}
+	if i, j := strings.Index(user, "Do not translate"), strings.Index(user, "please translate"); i > j {
+		t.Errorf("glossary instructions should precede the text:\n%s", user)
+	}
+
+	tr := NewTranslator(nil)
+	plain := p
+	plain.Glossary = nil
+	if tr.cacheKey(p, req) == tr.cacheKey(plain, req) {
+		t.Error("cacheKey() ignores the profile glossary")
+	}
+	other := req
+	other.Glossary = map[string]string{"token": "词元"}
+	if tr.cacheKey(p, req) == tr.cacheKey(p, other) {
+		t.Error("cacheKey() ignores the request glossary")
+	}
+}
@@ -3,9 +3,15 @@ package app
 import (
 	"context"
 	"log/slog"
+	"os"
+	"slices"
+	"strings"
 	"sync"
+	"time"
+	"unicode"
 
 	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/livetranslate"
 )
 
 // LiveAdapter manages live translation with proper synchronization.
@@ -47,7 +53,11 @@ func (la *LiveAdapter) Start(ctx context.Context, service types.LiveTranslator,
 func (la *LiveAdapter) Stop() error {
 	la.mu.Lock()
 	defer la.mu.Unlock()
+	return la.stopLocked()
+}
 
+// stopLocked stops the current session. The caller holds la.mu.
+func (la *LiveAdapter) stopLocked() error {
 	if la.cancel != nil {
 		la.cancel()
 		la.cancel = nil
@@ -73,27 +83,233 @@ func (la *LiveAdapter) Status() types.LiveStatus {
 	return la.service.Status()
 }
 
-// ForwardEvents forwards all events from the service to the emitter.
-// Blocks until the service is stopped. Should be called in a goroutine.
-func (la *LiveAdapter) ForwardEvents(emit func(name string, data any), translate func(t types.LiveTranscript)) {
+// SetVADConfig applies v to the running session, if its translator
+// supports it. Otherwise v takes effect with the next session.
+func (la *LiveAdapter) SetVADConfig(v types.VADSettings) error {
 	la.mu.RLock()
 	svc := la.service
 	la.mu.RUnlock()
 
+	if c, ok := svc.(types.VADConfigurer); ok {
+		return c.SetVADConfig(v)
+	}
+	return nil
+}
+
+// stopIf stops the session only if svc is still the active service.
+// The check and the stop happen under one lock, so a session a concurrent
+// Start just installed is never stopped. Reports whether it stopped
+// anything.
+func (la *LiveAdapter) stopIf(svc types.LiveTranslator) bool {
+	la.mu.Lock()
+	defer la.mu.Unlock()
+	if la.service != svc {
+		return false
+	}
+
+	if err := la.stopLocked(); err != nil {
+		slog.Warn("stop live translation", "error", err)
+	}
+	return true
+}
+
+// ForwardOptions tunes ForwardEvents.
+type ForwardOptions struct {
+	// IdleTimeout stops the session when no speech is detected for this long.
+	// Zero disables auto-stop.
+	IdleTimeout time.Duration
+
+	// Lock, if non-nil, forces every transcript to this language pair so
+	// auto-detection can't flip the translation direction.
+	Lock *DirectionLock
+
+	// SilenceFinalize marks a pending transcript final, and translates it,
+	// when neither it nor speech has been updated for this long. It unsticks
+	// a trailing segment whose final event never arrives. Zero disables it.
+	SilenceFinalize time.Duration
+
+	// MaxCaptionChars shortens emitted captions; see displayCaption. The
+	// translate callback still receives the full transcript.
+	MaxCaptionChars int
+
+	// newTimer replaces the SilenceFinalize timer in tests.
+	newTimer func() liveTimer
+}
+
+// liveTimer is the part of *time.Timer that silence finalization uses.
+type liveTimer interface {
+	C() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// stdTimer is a liveTimer backed by a *time.Timer.
+type stdTimer struct{ t *time.Timer }
+
+func (s stdTimer) C() <-chan time.Time   { return s.t.C }
+func (s stdTimer) Reset(d time.Duration) { s.t.Reset(d) }
+func (s stdTimer) Stop()                 { s.t.Stop() }
+
+// silenceTimer returns a stopped timer for SilenceFinalize.
+func (o ForwardOptions) silenceTimer() liveTimer {
+	if o.newTimer != nil {
+		return o.newTimer()
+	}
+	t := time.NewTimer(time.Hour)
+	t.Stop()
+	return stdTimer{t}
+}
+
+// maxForced bounds the transcripts finalized on silence that are still
+// waiting for their real final event, which may never come.
+const maxForced = 16
+
+// DirectionLock is a fixed live translation language pair.
+type DirectionLock struct {
+	SourceLang string
+	TargetLang string
+}
+
+// apply returns t with its languages replaced by the locked pair.
+func (l *DirectionLock) apply(t types.LiveTranscript) types.LiveTranscript {
+	if l == nil {
+		return t
+	}
+	t.SourceLang = l.SourceLang
+	t.TargetLang = l.TargetLang
+	return t
+}
+
+// captionEllipsis marks text cut from the start of a caption.
+const captionEllipsis = "…"
+
+// displayCaption returns t with SourceText and TargetText shortened to at
+// most limit characters for the overlay. The end of the text is kept, since
+// it is what is being spoken now. Zero or negative limit disables it.
+func displayCaption(t types.LiveTranscript, limit int) types.LiveTranscript {
+	t.SourceText = truncateCaption(t.SourceText, limit)
+	t.TargetText = truncateCaption(t.TargetText, limit)
+	return t
+}
+
+// truncateCaption keeps the last limit-1 characters of text after an
+// ellipsis, starting at a word boundary where one is near.
+func truncateCaption(text string, limit int) string {
+	r := []rune(text)
+	if limit <= 0 || len(r) <= limit {
+		return text
+	}
+	if limit == 1 {
+		return captionEllipsis
+	}
+	tail := r[len(r)-(limit-1):]
+	// Drop a partial leading word unless that would lose most of the tail.
+	if i := slices.Index(tail, ' '); i >= 0 && i < len(tail)/2 && !unicode.IsSpace(r[len(r)-len(tail)-1]) {
+		tail = tail[i:]
+	}
+	return captionEllipsis + strings.TrimLeftFunc(string(tail), unicode.IsSpace)
+}
+
+// ForwardEvents forwards all events from svc, as returned to Start, to the
+// emitter. Taking svc rather than the current service keeps a forwarder
+// bound to its own session across restarts. Blocks until svc is stopped.
+// Should be called in a goroutine.
+func (la *LiveAdapter) ForwardEvents(svc types.LiveTranslator, emit func(name string, data any), translate func(t types.LiveTranscript), opts ForwardOptions) {
 	if svc == nil {
 		return
 	}
 
 	var wg sync.WaitGroup
 
+	// Speech activity resets the idle timer
+	activity := make(chan struct{}, 1)
+	active := func() {
+		select {
+		case activity <- struct{}{}:
+		default:
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	if opts.IdleTimeout > 0 {
+		go la.watchIdle(svc, opts.IdleTimeout, activity, done, emit)
+	}
+
+	// New speech postpones silence finalization
+	speech := make(chan struct{}, 1)
+
 	// Forward transcripts
 	wg.Go(func() {
-		for transcript := range svc.Transcripts() {
-			emit(EventLiveTranscript, transcript)
+		forward := func(t types.LiveTranscript) {
+			evLiveTranscript.Emit(emit, displayCaption(t, opts.MaxCaptionChars))
 
 			// Async translate if final with source text but no target text
-			if transcript.IsFinal && transcript.SourceText != "" && transcript.TargetText == "" {
-				go translate(transcript)
+			if t.IsFinal && t.SourceText != "" && t.TargetText == "" {
+				go translate(t)
+			}
+		}
+
+		var pending *types.LiveTranscript
+		forced := make(map[string]string) // ID -> source text finalized on silence
+		var forcedIDs []string            // Keys of forced, oldest first
+		silence := opts.silenceTimer()
+		defer silence.Stop()
+
+		transcripts := svc.Transcripts()
+		for {
+			select {
+			case transcript, ok := <-transcripts:
+				if !ok {
+					return
+				}
+				active()
+				transcript = opts.Lock.apply(transcript)
+
+				if text, ok := forced[transcript.ID]; ok {
+					// Already shown as final: drop late partials, and the real
+					// final too unless it changed the text.
+					if !transcript.IsFinal {
+						continue
+					}
+					delete(forced, transcript.ID)
+					forcedIDs = slices.DeleteFunc(forcedIDs, func(id string) bool { return id == transcript.ID })
+					if transcript.SourceText == text {
+						continue
+					}
+				}
+				forward(transcript)
+
+				if opts.SilenceFinalize <= 0 {
+					continue
+				}
+				if !transcript.IsFinal && transcript.SourceText != "" {
+					pending = &transcript
+					silence.Reset(opts.SilenceFinalize)
+				} else if pending != nil && pending.ID == transcript.ID {
+					pending = nil
+					silence.Stop()
+				}
+
+			case <-speech:
+				if pending != nil {
+					silence.Reset(opts.SilenceFinalize)
+				}
+
+			case <-silence.C():
+				if pending == nil {
+					continue
+				}
+				t := *pending
+				pending = nil
+				t.IsFinal = true
+				forced[t.ID] = t.SourceText
+				forcedIDs = append(forcedIDs, t.ID)
+				if len(forcedIDs) > maxForced {
+					delete(forced, forcedIDs[0])
+					forcedIDs = forcedIDs[1:]
+				}
+				slog.Debug("finalizing live segment after silence", "id", t.ID)
+				forward(t)
 			}
 		}
 	})
@@ -101,7 +317,14 @@ func (la *LiveAdapter) ForwardEvents(emit func(name string, data any), translate
 	// Forward VAD updates
 	wg.Go(func() {
 		for state := range svc.VADUpdates() {
-			emit(EventVADUpdate, state)
+			if state == types.VADStateSpeaking {
+				active()
+				select {
+				case speech <- struct{}{}:
+				default:
+				}
+			}
+			evVADUpdate.Emit(emit, state)
 		}
 	})
 
@@ -113,3 +336,76 @@ func (la *LiveAdapter) ForwardEvents(emit func(name string, data any), translate
 	})
 	wg.Wait()
 }
+
+// watchIdle stops svc after timeout without activity and emits
+// evLiveAutoStopped. Returns when done is closed.
+func (la *LiveAdapter) watchIdle(svc types.LiveTranslator, timeout time.Duration, activity <-chan struct{}, done <-chan struct{}, emit func(name string, data any)) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-activity:
+			timer.Reset(timeout)
+		case <-done:
+			return
+		case <-timer.C:
+			if la.stopIf(svc) {
+				slog.Info("live translation auto-stopped", "idle", timeout)
+				evLiveAutoStopped.Emit(emit, int64(timeout.Seconds()))
+			}
+			return
+		}
+	}
+}
+
+// liveContextSize is the number of previous sentences sent as context.
+const liveContextSize = 2
+
+// liveContext keeps the most recent final source sentences of a session.
+// Safe for concurrent use.
+type liveContext struct {
+	mu    sync.Mutex
+	size  int
+	texts []string
+}
+
+func newLiveContext(size int) *liveContext {
+	return &liveContext{size: size}
+}
+
+// Push records text and returns the context that preceded it.
+func (lc *liveContext) Push(text string) string {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	prev := strings.Join(lc.texts, " ")
+	lc.texts = append(lc.texts, text)
+	if len(lc.texts) > lc.size {
+		lc.texts = lc.texts[len(lc.texts)-lc.size:]
+	}
+	return prev
+}
+
+// mockSTTEnv enables the scripted live provider when set to "1", so the
+// live pipeline can be demoed or tested without audio or an API key.
+const mockSTTEnv = "TRANSY_MOCK_STT"
+
+// mockScript is what the scripted live provider says.
+var mockScript = []string{
+	"Welcome to Transy live translation.",
+	"This session is running on the mock speech provider.",
+	"No audio is captured and no API key is needed.",
+}
+
+// newLiveRegistry returns the live provider registry, including the mock
+// provider when mockSTTEnv is set.
+func newLiveRegistry() *livetranslate.Registry {
+	r := livetranslate.NewRegistry()
+	if os.Getenv(mockSTTEnv) == "1" {
+		if err := r.Register(livetranslate.NewMockProvider(mockScript)); err != nil {
+			slog.Warn("register mock live provider", "error", err)
+		}
+	}
+	return r
+}
@@ -0,0 +1,537 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/livetranslate"
+)
+
+// fakeLive implements types.LiveTranslator for testing.
+type fakeLive struct {
+	mu          sync.Mutex
+	running     bool
+	stopped     chan struct{}
+	transcripts chan types.LiveTranscript
+	vad         chan types.VADState
+	errs        chan error
+}
+
+func newFakeLive() *fakeLive {
+	return &fakeLive{
+		stopped:     make(chan struct{}),
+		transcripts: make(chan types.LiveTranscript, 10),
+		vad:         make(chan types.VADState, 10),
+		errs:        make(chan error, 10),
+	}
+}
+
+func (f *fakeLive) Start(_ context.Context, _, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = true
+	return nil
+}
+
+func (f *fakeLive) Stop() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.running {
+		return nil
+	}
+	f.running = false
+	close(f.transcripts)
+	close(f.vad)
+	close(f.errs)
+	close(f.stopped)
+	return nil
+}
+
+func (f *fakeLive) Transcripts() <-chan types.LiveTranscript { return f.transcripts }
+func (f *fakeLive) Errors() <-chan error                     { return f.errs }
+func (f *fakeLive) VADUpdates() <-chan types.VADState        { return f.vad }
+
+func (f *fakeLive) Status() types.LiveStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return types.LiveStatus{Active: f.running}
+}
+
+// recorder collects emitted events.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) emit(name string, _ any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, name)
+}
+
+func (r *recorder) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.events {
+		if e == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestForwardEventsIdleAutoStop(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	rec := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		la.ForwardEvents(svc, rec.emit, func(types.LiveTranscript) {}, ForwardOptions{IdleTimeout: 80 * time.Millisecond})
+		close(done)
+	}()
+
+	// Speech keeps the session alive past the timeout.
+	for range 3 {
+		time.Sleep(40 * time.Millisecond)
+		svc.vad <- types.VADStateSpeaking
+	}
+	if !la.Status().Active {
+		t.Fatal("session stopped despite speech activity")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("session was not auto-stopped")
+	}
+	if la.Status().Active {
+		t.Error("status still active after auto-stop")
+	}
+	if got := rec.count(evLiveAutoStopped.Name); got != 1 {
+		t.Errorf("auto-stopped events = %d, want 1", got)
+	}
+}
+
+func TestLiveAdapterStopIfStale(t *testing.T) {
+	var la LiveAdapter
+	old, cur := newFakeLive(), newFakeLive()
+	if err := la.Start(context.Background(), old, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := la.Start(context.Background(), cur, "en", "ja"); err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+
+	if la.stopIf(old) {
+		t.Error("stopIf(replaced session) = true, want false")
+	}
+	if !la.Status().Active {
+		t.Fatal("stopIf(replaced session) stopped the current one")
+	}
+	if !la.stopIf(cur) {
+		t.Error("stopIf(current session) = false, want true")
+	}
+	if la.Status().Active {
+		t.Error("current session still active after stopIf")
+	}
+}
+
+func TestForwardEventsNoIdleTimeout(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	rec := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		la.ForwardEvents(svc, rec.emit, func(types.LiveTranscript) {}, ForwardOptions{})
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if !la.Status().Active {
+		t.Fatal("session stopped without idle timeout")
+	}
+	_ = la.Stop()
+	<-done
+	if got := rec.count(evLiveAutoStopped.Name); got != 0 {
+		t.Errorf("auto-stopped events = %d, want 0", got)
+	}
+}
+
+func TestLiveContext(t *testing.T) {
+	lc := newLiveContext(2)
+	for i, tc := range []struct{ push, want string }{
+		{"a", ""},
+		{"b", "a"},
+		{"c", "a b"},
+		{"d", "b c"},
+	} {
+		if got := lc.Push(tc.push); got != tc.want {
+			t.Errorf("push %d: context = %q, want %q", i, got, tc.want)
+		}
+	}
+}
+
+func TestForwardEventsDirectionLock(t *testing.T) {
+	tests := []struct {
+		name       string
+		lock       *DirectionLock
+		wantSource string
+		wantTarget string
+	}{
+		{"unlocked keeps detection", nil, "zh", "en"},
+		{"locked overrides detection", &DirectionLock{SourceLang: "en", TargetLang: "zh"}, "en", "zh"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var la LiveAdapter
+			svc := newFakeLive()
+			if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+				t.Fatalf("start: %v", err)
+			}
+
+			got := make(chan types.LiveTranscript, 1)
+			done := make(chan struct{})
+			go func() {
+				la.ForwardEvents(svc, func(string, any) {}, func(tr types.LiveTranscript) { got <- tr }, ForwardOptions{Lock: tt.lock})
+				close(done)
+			}()
+
+			// Detection flipped the pair for this segment.
+			svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "你好", SourceLang: "zh", TargetLang: "en", IsFinal: true}
+
+			select {
+			case tr := <-got:
+				if tr.SourceLang != tt.wantSource || tr.TargetLang != tt.wantTarget {
+					t.Errorf("pair = %s→%s, want %s→%s", tr.SourceLang, tr.TargetLang, tt.wantSource, tt.wantTarget)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("transcript was not translated")
+			}
+			_ = la.Stop()
+			<-done
+		})
+	}
+}
+
+// fakeTimer is a liveTimer the test fires by hand. Each Reset is reported
+// on resets, so the test knows the forwarder has caught up.
+type fakeTimer struct {
+	c      chan time.Time
+	resets chan time.Duration
+}
+
+func newFakeTimer() *fakeTimer {
+	return &fakeTimer{c: make(chan time.Time, 1), resets: make(chan time.Duration, 10)}
+}
+
+func (f *fakeTimer) C() <-chan time.Time   { return f.c }
+func (f *fakeTimer) Reset(d time.Duration) { f.resets <- d }
+func (f *fakeTimer) Stop()                 {}
+
+// waitReset waits for the forwarder to arm the timer.
+func (f *fakeTimer) waitReset(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.resets:
+	case <-time.After(time.Second):
+		t.Fatal("silence timer was not reset")
+	}
+}
+
+// forwardSilence runs ForwardEvents on svc with silence finalization
+// driven by timer. Translated transcripts arrive on the returned channel;
+// done is closed when ForwardEvents returns.
+func forwardSilence(la *LiveAdapter, svc *fakeLive, timer *fakeTimer) (got chan types.LiveTranscript, done chan struct{}) {
+	got = make(chan types.LiveTranscript, 4)
+	done = make(chan struct{})
+	opts := ForwardOptions{SilenceFinalize: time.Second, newTimer: func() liveTimer { return timer }}
+	go func() {
+		la.ForwardEvents(svc, func(string, any) {}, func(tr types.LiveTranscript) { got <- tr }, opts)
+		close(done)
+	}()
+	return got, done
+}
+
+// receiveIDs waits for n translated transcripts and returns their IDs,
+// sorted, since translations run concurrently.
+func receiveIDs(t *testing.T, got <-chan types.LiveTranscript, n int) []string {
+	t.Helper()
+	var ids []string
+	for range n {
+		select {
+		case tr := <-got:
+			ids = append(ids, tr.ID)
+		case <-time.After(time.Second):
+			t.Fatalf("got %d translations %v, want %d", len(ids), ids, n)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestForwardEventsSilenceFinalize(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	svc.transcripts = make(chan types.LiveTranscript)
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer la.Stop()
+	timer := newFakeTimer()
+	got, _ := forwardSilence(&la, svc, timer)
+
+	// The trailing segment never gets its final event.
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "Thanks for"}
+	timer.waitReset(t)
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "Thanks for listening"}
+	timer.waitReset(t)
+
+	// Speech postpones finalization.
+	svc.vad <- types.VADStateSpeaking
+	timer.waitReset(t)
+
+	timer.c <- time.Now()
+	select {
+	case tr := <-got:
+		if !tr.IsFinal || tr.SourceText != "Thanks for listening" {
+			t.Errorf("finalized %+v, want final \"Thanks for listening\"", tr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("trailing segment was not finalized")
+	}
+
+	// A late final with the same text is not translated again; the next
+	// segment is.
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "Thanks for listening", IsFinal: true}
+	svc.transcripts <- types.LiveTranscript{ID: "2", SourceText: "Bye", IsFinal: true}
+	if ids := receiveIDs(t, got, 1); ids[0] != "2" {
+		t.Errorf("translated %v, want only the next segment", ids)
+	}
+}
+
+func TestForwardEventsSilenceFinalizeFinalArrives(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	svc.transcripts = make(chan types.LiveTranscript)
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer la.Stop()
+	timer := newFakeTimer()
+	got, _ := forwardSilence(&la, svc, timer)
+
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "Hello"}
+	timer.waitReset(t)
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: "Hello there", IsFinal: true}
+
+	// A timer firing after the final finds nothing pending.
+	timer.c <- time.Now()
+	svc.transcripts <- types.LiveTranscript{ID: "2", SourceText: "Bye", IsFinal: true}
+	select {
+	case tr := <-got:
+		if tr.ID == "1" && tr.SourceText != "Hello there" {
+			t.Errorf("translated %q, want the real final", tr.SourceText)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("nothing translated")
+	}
+	if tr := <-got; tr.ID == "1" && tr.SourceText != "Hello there" {
+		t.Errorf("translated %q, want the real final", tr.SourceText)
+	}
+}
+
+func TestForwardEventsSilenceFinalizeBoundsForced(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	svc.transcripts = make(chan types.LiveTranscript)
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer la.Stop()
+	timer := newFakeTimer()
+	got, _ := forwardSilence(&la, svc, timer)
+
+	// Force more segments than are remembered; none gets a real final.
+	for i := range maxForced + 1 {
+		svc.transcripts <- types.LiveTranscript{ID: strconv.Itoa(100 + i), SourceText: "partial"}
+		timer.waitReset(t)
+		timer.c <- time.Now()
+		receiveIDs(t, got, 1)
+	}
+
+	// The oldest was forgotten, so its late final is translated; the
+	// newest is still remembered and dropped.
+	svc.transcripts <- types.LiveTranscript{ID: "100", SourceText: "partial", IsFinal: true}
+	svc.transcripts <- types.LiveTranscript{ID: strconv.Itoa(100 + maxForced), SourceText: "partial", IsFinal: true}
+	svc.transcripts <- types.LiveTranscript{ID: "200", SourceText: "Bye", IsFinal: true}
+	if ids := receiveIDs(t, got, 2); !slices.Equal(ids, []string{"100", "200"}) {
+		t.Errorf("translated %v, want the forgotten segment and the next one", ids)
+	}
+}
+
+func TestLiveAdapterRestartTearsDownForwarders(t *testing.T) {
+	var la LiveAdapter
+	const n = 8
+
+	svcs := make([]*fakeLive, n)
+	forwarding := make([]chan struct{}, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		svcs[i] = newFakeLive()
+		forwarding[i] = make(chan struct{})
+		wg.Go(func() {
+			if err := la.Start(context.Background(), svcs[i], "en", "zh"); err != nil {
+				t.Errorf("start %d: %v", i, err)
+				close(forwarding[i])
+				return
+			}
+			go func() {
+				la.ForwardEvents(svcs[i], func(string, any) {}, func(types.LiveTranscript) {}, ForwardOptions{})
+				close(forwarding[i])
+			}()
+		})
+	}
+	wg.Wait()
+
+	running := 0
+	for i, svc := range svcs {
+		if svc.Status().Active {
+			running++
+			continue
+		}
+		// A replaced session's forwarder must exit.
+		select {
+		case <-forwarding[i]:
+		case <-time.After(time.Second):
+			t.Errorf("forwarder %d still running after its session was replaced", i)
+		}
+	}
+	if running != 1 {
+		t.Fatalf("%d sessions running, want 1", running)
+	}
+
+	_ = la.Stop()
+	for i := range svcs {
+		select {
+		case <-forwarding[i]:
+		case <-time.After(time.Second):
+			t.Errorf("forwarder %d still running after Stop", i)
+		}
+	}
+}
+
+func TestTruncateCaption(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  string
+	}{
+		{"short caption", 0, "short caption"},
+		{"short caption", 20, "short caption"},
+		{"exactly ten", 11, "exactly ten"},
+		{"the quick brown fox jumps", 12, "…fox jumps"},
+		{"abcdefghij", 4, "…hij"},
+		{"今天天气很好我们去公园", 6, "…我们去公园"},
+		{"anything", 1, "…"},
+	}
+	for _, tt := range tests {
+		if got := truncateCaption(tt.text, tt.limit); got != tt.want {
+			t.Errorf("truncateCaption(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestForwardEventsMaxCaptionChars(t *testing.T) {
+	var la LiveAdapter
+	svc := newFakeLive()
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	emitted := make(chan types.LiveTranscript, 1)
+	stored := make(chan types.LiveTranscript, 1)
+	emit := func(name string, data any) {
+		if name == evLiveTranscript.Name {
+			emitted <- data.(types.LiveTranscript)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		la.ForwardEvents(svc, emit, func(tr types.LiveTranscript) { stored <- tr }, ForwardOptions{MaxCaptionChars: 12})
+		close(done)
+	}()
+
+	const text = "the quick brown fox jumps"
+	svc.transcripts <- types.LiveTranscript{ID: "1", SourceText: text, IsFinal: true}
+
+	if tr := <-emitted; tr.SourceText != "…fox jumps" {
+		t.Errorf("emitted %q, want truncated caption", tr.SourceText)
+	}
+	if tr := <-stored; tr.SourceText != text {
+		t.Errorf("stored %q, want full text %q", tr.SourceText, text)
+	}
+	_ = la.Stop()
+	<-done
+}
+
+func TestMockLiveProvider(t *testing.T) {
+	if _, ok := newLiveRegistry().Get(livetranslate.MockProvider); ok {
+		t.Fatalf("mock provider registered without %s", mockSTTEnv)
+	}
+
+	t.Setenv(mockSTTEnv, "1")
+	p, err := newLiveRegistry().Select(livetranslate.MockProvider)
+	if err != nil || p.Name() != livetranslate.MockProvider {
+		t.Fatalf("Select(mock) = %v, %v", p, err)
+	}
+	svc, err := p.New(livetranslate.Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var la LiveAdapter
+	if err := la.Start(context.Background(), svc, "en", "zh"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rec := &recorder{}
+	got := make(chan types.LiveTranscript, len(mockScript))
+	done := make(chan struct{})
+	go func() {
+		la.ForwardEvents(svc, rec.emit, func(tr types.LiveTranscript) { got <- tr }, ForwardOptions{})
+		close(done)
+	}()
+
+	select {
+	case tr := <-got:
+		if tr.SourceText != mockScript[0] || tr.SourceLang != "en" || tr.TargetLang != "zh" {
+			t.Errorf("first transcript = %+v, want %q en→zh", tr, mockScript[0])
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("mock provider emitted no transcript")
+	}
+	if rec.count(evLiveTranscript.Name) == 0 {
+		t.Errorf("no %s event emitted", evLiveTranscript.Name)
+	}
+
+	_ = la.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("forwarder still running after Stop")
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Formats for TranslateRequest.PreserveFormat.
+const (
+	FormatNone     = "none"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatSubtitle = "subtitle"
+)
+
+// Patterns whose matches are replaced by placeholders before translation,
+// in application order. Earlier patterns take precedence.
+var (
+	markdownPatterns = []*regexp.Regexp{
+		regexp.MustCompile("(?s)```.*?```"),              // Fenced code blocks
+		regexp.MustCompile("`[^`\n]+`"),                  // Inline code
+		regexp.MustCompile(`\]\([^)\s]+(?: "[^"]*")?\)`), // Link and image targets
+		regexp.MustCompile(`<https?://[^>\s]+>`),         // Autolinks
+		regexp.MustCompile(`https?://[^\s)\]>]+`),        // Bare URLs
+	}
+	htmlPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?is)<script\b.*?</script>`),
+		regexp.MustCompile(`(?is)<style\b.*?</style>`),
+		regexp.MustCompile(`(?is)<pre\b.*?</pre>`),
+		regexp.MustCompile(`(?is)<code\b.*?</code>`),
+		regexp.MustCompile(`<[^<>]+>`),             // Tags
+		regexp.MustCompile(`&(?:[a-zA-Z]+|#\d+);`), // Entities
+	}
+	subtitlePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`<[^<>]+>`),     // Styling tags such as <i> and <font>
+		regexp.MustCompile(`\{\\[^{}]*\}`), // ASS override tags such as {\an8}
+	}
+	placeholderRe = regexp.MustCompile(`⟦(\d+)⟧`)
+
+	// emojiPattern matches a run of emoji, including skin tone modifiers,
+	// variation selectors, ZWJ sequences, flags and tag sequences.
+	emojiPattern = regexp.MustCompile(`[\x{1F1E6}-\x{1F1FF}\x{1F300}-\x{1FAFF}\x{2300}-\x{23FF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}]` +
+		`[\x{1F1E6}-\x{1F1FF}\x{1F300}-\x{1FAFF}\x{2300}-\x{23FF}\x{2600}-\x{27BF}\x{2B00}-\x{2BFF}\x{FE0F}\x{200D}\x{E0020}-\x{E007F}]*`)
+)
+
+// keepPlaceholders tells the model to leave placeholders alone.
+const keepPlaceholders = "Keep placeholders such as ⟦0⟧ unchanged and in place."
+
+// placeholder returns the token standing in for protected segment i.
+func placeholder(i int) string {
+	return fmt.Sprintf("⟦%d⟧", i)
+}
+
+// protectMarkup replaces code, links and tags in text with placeholders so
+// the model cannot translate or reflow them. It returns the rewritten text
+// and the original segments, indexed by placeholder number.
+func protectMarkup(text, format string) (string, []string) {
+	var patterns []*regexp.Regexp
+	switch format {
+	case FormatMarkdown:
+		patterns = markdownPatterns
+	case FormatHTML:
+		patterns = htmlPatterns
+	case FormatSubtitle:
+		patterns = subtitlePatterns
+	default:
+		return text, nil
+	}
+	return protect(text, patterns, nil)
+}
+
+// protect replaces every match of patterns in text with a placeholder,
+// appending the originals to slots.
+func protect(text string, patterns []*regexp.Regexp, slots []string) (string, []string) {
+	for _, re := range patterns {
+		text = re.ReplaceAllStringFunc(text, func(m string) string {
+			slots = append(slots, m)
+			return placeholder(len(slots) - 1)
+		})
+	}
+	return text, slots
+}
+
+// protectEmoji replaces emoji runs in text with placeholders numbered after
+// the existing slots, so they survive translation verbatim.
+func protectEmoji(text string, slots []string) (string, []string) {
+	return protect(text, []*regexp.Regexp{emojiPattern}, slots)
+}
+
+// restoreMarkup puts the protected segments back in place of their
+// placeholders. Unknown placeholders are left untouched.
+func restoreMarkup(text string, slots []string) string {
+	if len(slots) == 0 {
+		return text
+	}
+	return placeholderRe.ReplaceAllStringFunc(text, func(m string) string {
+		var i int
+		if _, err := fmt.Sscanf(m, "⟦%d⟧", &i); err != nil || i >= len(slots) {
+			return m
+		}
+		return slots[i]
+	})
+}
+
+// formatInstruction returns the prompt instruction for preserving format.
+func formatInstruction(format string) string {
+	var b strings.Builder
+	switch format {
+	case FormatMarkdown:
+		b.WriteString("Preserve the Markdown structure (headings, lists, emphasis, line breaks) exactly.")
+	case FormatHTML:
+		b.WriteString("Preserve the HTML structure exactly; translate only human-readable text.")
+	case FormatSubtitle:
+		b.WriteString("This is a subtitle cue; keep its line breaks and keep each line short.")
+	default:
+		return ""
+	}
+	b.WriteString(" " + keepPlaceholders)
+	return b.String()
+}
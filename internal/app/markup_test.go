@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+func TestProtectMarkupRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		text   string
+		keep   []string // Segments that must be hidden from the model
+	}{
+		{
+			name:   "inline code",
+			format: FormatMarkdown,
+			text:   "Run `go test ./...` before pushing.",
+			keep:   []string{"`go test ./...`"},
+		},
+		{
+			name:   "fenced code",
+			format: FormatMarkdown,
+			text:   "Example:\n```go\nfmt.Println(\"hi\")\n```\nDone.",
+			keep:   []string{"```go\nfmt.Println(\"hi\")\n```"},
+		},
+		{
+			name:   "link target",
+			format: FormatMarkdown,
+			text:   "See [the docs](https://example.com/docs \"Docs\") for details.",
+			keep:   []string{`](https://example.com/docs "Docs")`},
+		},
+		{
+			name:   "bare url",
+			format: FormatMarkdown,
+			text:   "Visit https://example.com/a?b=c today.",
+			keep:   []string{"https://example.com/a?b=c"},
+		},
+		{
+			name:   "html tags",
+			format: FormatHTML,
+			text:   `<p class="x">Hello <a href="/a">world</a>&nbsp;<code>x &lt; y</code></p>`,
+			keep:   []string{`<p class="x">`, `<a href="/a">`, "</a>", "&nbsp;", "<code>x &lt; y</code>", "</p>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protected, slots := protectMarkup(tt.text, tt.format)
+			for _, k := range tt.keep {
+				if strings.Contains(protected, k) {
+					t.Errorf("protected text still contains %q: %q", k, protected)
+				}
+			}
+			if got := restoreMarkup(protected, slots); got != tt.text {
+				t.Errorf("restoreMarkup() = %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestProtectMarkupNone(t *testing.T) {
+	text := "Run `x` at <b>once</b>"
+	for _, format := range []string{"", FormatNone} {
+		got, slots := protectMarkup(text, format)
+		if got != text || slots != nil {
+			t.Errorf("protectMarkup(%q) = %q, %v; want unchanged", format, got, slots)
+		}
+	}
+}
+
+func TestRestoreMarkupUnknownPlaceholder(t *testing.T) {
+	got := restoreMarkup("a ⟦0⟧ b ⟦7⟧", []string{"`x`"})
+	if want := "a `x` b ⟦7⟧"; got != want {
+		t.Errorf("restoreMarkup() = %q, want %q", got, want)
+	}
+}
+
+// upperCompleter "translates" the text to translate by upper-casing it,
+// which would corrupt any code or URL that reached the model.
+type upperCompleter struct {
+	prompt string
+}
+
+func (m *upperCompleter) Complete(_ context.Context, msgs []llm.Message) (string, types.Usage, error) {
+	m.prompt = msgs[len(msgs)-1].Content
+	_, text, _ := strings.Cut(m.prompt, ":\n\n")
+	return strings.ToUpper(text), types.Usage{}, nil
+}
+
+func TestTranslatorPreserveFormat(t *testing.T) {
+	completer := &upperCompleter{}
+	tr := NewTranslator(nil)
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	req := types.TranslateRequest{
+		Text:           "Call `foo()` or read [docs](https://example.com/docs).",
+		SourceLang:     "en",
+		TargetLang:     "de",
+		PreserveFormat: FormatMarkdown,
+	}
+
+	result, err := tr.Translate(context.Background(), completer, profile, req)
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if want := "CALL `foo()` OR READ [DOCS](https://example.com/docs)."; result.Text != want {
+		t.Errorf("Translate() = %q, want %q", result.Text, want)
+	}
+	if !strings.Contains(completer.prompt, "Preserve the Markdown structure") {
+		t.Errorf("prompt missing format instruction: %q", completer.prompt)
+	}
+}
+
+func TestProtectEmojiRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantText  string
+		wantSlots []string
+	}{
+		{"none", "hello there", "hello there", nil},
+		{"single", "nice 👍 work", "nice ⟦0⟧ work", []string{"👍"}},
+		{"run", "lol 😂😂 ok", "lol ⟦0⟧ ok", []string{"😂😂"}},
+		{"zwj and skin tone", "team 👩🏽‍💻 and 👨‍👩‍👧", "team ⟦0⟧ and ⟦1⟧", []string{"👩🏽‍💻", "👨‍👩‍👧"}},
+		{"flag and variation selector", "🇯🇵 trip ❤️", "⟦0⟧ trip ⟦1⟧", []string{"🇯🇵", "❤️"}},
+		{"cjk untouched", "好的👌", "好的⟦0⟧", []string{"👌"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, slots := protectEmoji(tt.text, nil)
+			if got != tt.wantText {
+				t.Errorf("protectEmoji() = %q, want %q", got, tt.wantText)
+			}
+			if !slices.Equal(slots, tt.wantSlots) {
+				t.Errorf("slots = %q, want %q", slots, tt.wantSlots)
+			}
+			if back := restoreMarkup(got, slots); back != tt.text {
+				t.Errorf("restoreMarkup() = %q, want %q", back, tt.text)
+			}
+		})
+	}
+}
+
+func TestTranslatorPreserveEmoji(t *testing.T) {
+	completer := &upperCompleter{}
+	tr := NewTranslator(nil)
+	req := types.TranslateRequest{
+		Text:           "see you `soon` 👋🏻 then 🎉🎉!",
+		SourceLang:     "en",
+		TargetLang:     "de",
+		PreserveFormat: FormatMarkdown,
+	}
+
+	profile := TranslateProfile{Name: "test", Model: "gpt-4", Emoji: true}
+	result, err := tr.Translate(context.Background(), completer, profile, req)
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if want := "SEE YOU `soon` 👋🏻 THEN 🎉🎉!"; result.Text != want {
+		t.Errorf("Translate() = %q, want %q", result.Text, want)
+	}
+	if strings.ContainsAny(completer.prompt, "👋🎉") {
+		t.Errorf("emoji reached the model: %q", completer.prompt)
+	}
+	if !profile.needsRestore(req) {
+		t.Error("needsRestore() = false for text with emoji")
+	}
+
+	// Without markup, placeholders still come with an instruction.
+	req.PreserveFormat = ""
+	if _, err := tr.Translate(context.Background(), completer, profile, req); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if !strings.Contains(completer.prompt, keepPlaceholders) {
+		t.Errorf("prompt missing placeholder instruction: %q", completer.prompt)
+	}
+
+	plain := TranslateProfile{Name: "test", Model: "gpt-4"}
+	if tr.cacheKey(plain, req) == tr.cacheKey(profile, req) {
+		t.Error("cache key should differ when emoji are preserved")
+	}
+	if plain.needsRestore(req) {
+		t.Error("needsRestore() = true without emoji preservation")
+	}
+}
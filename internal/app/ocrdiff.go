@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strings"
+	"sync"
+
+	"go.aimuz.me/transy/cache"
+)
+
+// ocrHistorySize bounds the number of remembered OCR lines.
+const ocrHistorySize = 500
+
+// ocrHistory remembers recently captured OCR lines so repeated captures of
+// scrolling content only yield the newly appeared lines.
+// Safe for concurrent use.
+type ocrHistory struct {
+	mu    sync.Mutex
+	max   int
+	seen  map[string]struct{}
+	order []string // Keys in insertion order, oldest first
+}
+
+func newOCRHistory(max int) *ocrHistory {
+	return &ocrHistory{max: max, seen: make(map[string]struct{})}
+}
+
+// NewLines returns the lines of text not seen in previous captures, in their
+// original order, and records them. Blank lines are dropped.
+func (h *ocrHistory) NewLines(text string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		key := cache.GenerateKey("ocr", "", "", "", line)
+		if _, ok := h.seen[key]; ok {
+			continue
+		}
+		h.add(key)
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Reset forgets all remembered lines.
+func (h *ocrHistory) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seen = make(map[string]struct{})
+	h.order = nil
+}
+
+func (h *ocrHistory) add(key string) {
+	h.seen[key] = struct{}{}
+	h.order = append(h.order, key)
+	if len(h.order) > h.max {
+		delete(h.seen, h.order[0])
+		h.order = h.order[1:]
+	}
+}
@@ -0,0 +1,39 @@
+package app
+
+import "testing"
+
+func TestOCRHistoryNewLines(t *testing.T) {
+	h := newOCRHistory(100)
+
+	captures := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"first capture", "line one\nline two\nline three", "line one\nline two\nline three"},
+		{"scrolled by one", "line two\nline three\nline four", "line four"},
+		{"identical capture", "line two\nline three\nline four", ""},
+		{"whitespace differences", "  line  three\nline four\n\nline five", "line five"},
+	}
+
+	for _, c := range captures {
+		if got := h.NewLines(c.text); got != c.want {
+			t.Errorf("%s: NewLines() = %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	h.Reset()
+	if got := h.NewLines("line one"); got != "line one" {
+		t.Errorf("after reset: NewLines() = %q, want %q", got, "line one")
+	}
+}
+
+func TestOCRHistoryEviction(t *testing.T) {
+	h := newOCRHistory(2)
+	h.NewLines("a\nb\nc")
+
+	// "a" was evicted, so it counts as new again.
+	if got := h.NewLines("a\nc"); got != "a" {
+		t.Errorf("NewLines() = %q, want %q", got, "a")
+	}
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+
+	"go.aimuz.me/transy/ocr"
+)
+
+// ocrLanguages returns the languages OCR recognizes: the configured ones,
+// or the system language and English if none are.
+func ocrLanguages(configured []string, system string) []string {
+	if len(configured) > 0 {
+		return configured
+	}
+	langs := []string{}
+	if system != "" {
+		langs = append(langs, system)
+	}
+	if !slices.Contains(langs, "en") {
+		langs = append(langs, "en")
+	}
+	return langs
+}
+
+// checkOCRLanguages returns an error if langs has a language OCR does not
+// recognize or a repeated one. Vision identifiers such as "zh-Hant" are
+// accepted alongside app language codes.
+func checkOCRLanguages(langs []string) error {
+	for i, lang := range langs {
+		if !ocr.IsSupported(lang) {
+			return fmt.Errorf("unsupported OCR language: %q", lang)
+		}
+		if slices.Contains(langs[:i], lang) {
+			return fmt.Errorf("duplicate OCR language: %q", lang)
+		}
+	}
+	return nil
+}
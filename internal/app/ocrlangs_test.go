@@ -0,0 +1,46 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOCRLanguages(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured []string
+		system     string
+		want       []string
+	}{
+		{"configured wins", []string{"ja", "zh"}, "de", []string{"ja", "zh"}},
+		{"system and english", nil, "zh", []string{"zh", "en"}},
+		{"english system", nil, "en", []string{"en"}},
+		{"unknown system", nil, "", []string{"en"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ocrLanguages(tt.configured, tt.system); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ocrLanguages(%q, %q) = %q, want %q", tt.configured, tt.system, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOCRLanguages(t *testing.T) {
+	tests := []struct {
+		langs   []string
+		wantErr bool
+	}{
+		{nil, false},
+		{[]string{"zh", "ja", "en"}, false},
+		{[]string{"zh-Hant", "en-US"}, false},
+		{[]string{"xx"}, true},
+		{[]string{"ja", "ja"}, true},
+	}
+	for _, tt := range tests {
+		if err := checkOCRLanguages(tt.langs); (err != nil) != tt.wantErr {
+			t.Errorf("checkOCRLanguages(%q) error = %v, want error %v", tt.langs, err, tt.wantErr)
+		}
+	}
+}
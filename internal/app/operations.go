@@ -0,0 +1,50 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// operations tracks the user-facing operations in flight, such as an OCR
+// capture or a UI translation, so they can be cancelled together.
+type operations struct {
+	mu     sync.Mutex
+	next   int
+	active map[int]context.CancelFunc
+}
+
+// Begin starts an operation. It returns the context the operation must
+// honour and a func to call when the operation ends; both are safe to use
+// after a cancel.
+func (o *operations) Begin() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.active == nil {
+		o.active = make(map[int]context.CancelFunc)
+	}
+	id := o.next
+	o.next++
+	o.active[id] = cancel
+
+	return ctx, func() {
+		o.mu.Lock()
+		delete(o.active, id)
+		o.mu.Unlock()
+		cancel()
+	}
+}
+
+// CancelAll cancels every operation in flight and returns how many there
+// were.
+func (o *operations) CancelAll() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := len(o.active)
+	for id, cancel := range o.active {
+		cancel()
+		delete(o.active, id)
+	}
+	return n
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+// blockingCompleter blocks until its context is cancelled.
+type blockingCompleter struct {
+	started chan struct{}
+}
+
+func (b *blockingCompleter) Complete(ctx context.Context, _ []llm.Message) (string, types.Usage, error) {
+	close(b.started)
+	<-ctx.Done()
+	return "", types.Usage{}, ctx.Err()
+}
+
+func TestOperationsCancelStopsTranslate(t *testing.T) {
+	var ops operations
+	ctx, done := ops.Begin()
+	defer done()
+
+	tr := NewTranslator(nil)
+	completer := &blockingCompleter{started: make(chan struct{})}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := tr.Translate(ctx, completer, TranslateProfile{Name: "test"}, types.TranslateRequest{Text: "Hello", TargetLang: "zh"})
+		errc <- err
+	}()
+
+	<-completer.started
+	if n := ops.CancelAll(); n != 1 {
+		t.Errorf("CancelAll() = %d, want 1", n)
+	}
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Translate() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("translation did not stop after cancel")
+	}
+
+	if n := ops.CancelAll(); n != 0 {
+		t.Errorf("CancelAll() after cancel = %d, want 0", n)
+	}
+}
+
+func TestOperationsDone(t *testing.T) {
+	var ops operations
+	ctx1, done1 := ops.Begin()
+	ctx2, done2 := ops.Begin()
+	defer done2()
+
+	done1()
+	if ctx1.Err() == nil {
+		t.Error("finished operation's context not released")
+	}
+	if n := ops.CancelAll(); n != 1 {
+		t.Errorf("CancelAll() = %d, want 1", n)
+	}
+	if ctx2.Err() == nil {
+		t.Error("running operation not cancelled")
+	}
+	done1() // Calling done again is harmless.
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyClipboard is returned when there is no text to translate.
+var ErrEmptyClipboard = errors.New("clipboard has no text")
+
+// translateClipboard reads text with get, translates it and writes the
+// translation back with set, returning the translation. The clipboard is
+// left untouched if anything fails.
+func translateClipboard(get func() (string, error), set func(string) error, translate func(text string) (string, error)) (string, error) {
+	text, err := get()
+	if err != nil {
+		return "", fmt.Errorf("read clipboard: %w", err)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", ErrEmptyClipboard
+	}
+
+	translated, err := translate(text)
+	if err != nil {
+		return "", fmt.Errorf("translate clipboard: %w", err)
+	}
+	if err := set(translated); err != nil {
+		return "", fmt.Errorf("write clipboard: %w", err)
+	}
+	return translated, nil
+}
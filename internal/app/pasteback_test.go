@@ -0,0 +1,57 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTranslateClipboard(t *testing.T) {
+	errBoom := errors.New("boom")
+	upper := func(s string) (string, error) { return "T:" + s, nil }
+
+	tests := []struct {
+		name      string
+		clip      string
+		getErr    error
+		transErr  error
+		setErr    error
+		want      string
+		wantClip  string
+		wantErrIs error
+	}{
+		{name: "round trip", clip: "hello", want: "T:hello", wantClip: "T:hello"},
+		{name: "empty", clip: "  \n", wantClip: "  \n", wantErrIs: ErrEmptyClipboard},
+		{name: "read fails", getErr: errBoom, wantErrIs: errBoom},
+		{name: "translate fails keeps clipboard", clip: "hello", transErr: errBoom, wantClip: "hello", wantErrIs: errBoom},
+		{name: "write fails", clip: "hello", setErr: errBoom, wantClip: "hello", wantErrIs: errBoom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clip := tt.clip
+			get := func() (string, error) { return clip, tt.getErr }
+			set := func(s string) error {
+				if tt.setErr != nil {
+					return tt.setErr
+				}
+				clip = s
+				return nil
+			}
+			translate := upper
+			if tt.transErr != nil {
+				translate = func(string) (string, error) { return "", tt.transErr }
+			}
+
+			got, err := translateClipboard(get, set, translate)
+			if !errors.Is(err, tt.wantErrIs) {
+				t.Fatalf("translateClipboard() error = %v, want %v", err, tt.wantErrIs)
+			}
+			if got != tt.want {
+				t.Errorf("translateClipboard() = %q, want %q", got, tt.want)
+			}
+			if clip != tt.wantClip {
+				t.Errorf("clipboard = %q, want %q", clip, tt.wantClip)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// PostProcessor transforms a translation before it is cached and returned.
+// src is the text that was translated.
+type PostProcessor func(src, translated string) string
+
+// Built-in post-processor names, as stored in Config.PostProcessors.
+const (
+	PostTrimSpace   = "trim"
+	PostStripQuotes = "strip_quotes"
+	PostStripEcho   = "strip_echo"
+	PostMetricUnits = "metric_units"
+)
+
+var builtinPostProcessors = map[string]PostProcessor{
+	PostTrimSpace:   TrimSpace,
+	PostStripQuotes: StripQuotes,
+	PostMetricUnits: MetricUnits,
+}
+
+// postProcessorsByName resolves built-in post-processor names, in order.
+// echoLabels configures strip_echo; see StripEcho.
+func postProcessorsByName(names []string, echoLabels map[string][]string) ([]PostProcessor, error) {
+	chain := make([]PostProcessor, 0, len(names))
+	for _, name := range names {
+		if name == PostStripEcho {
+			chain = append(chain, StripEcho(echoLabels))
+			continue
+		}
+		p, ok := builtinPostProcessors[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown post-processor: %q", name)
+		}
+		chain = append(chain, p)
+	}
+	return chain, nil
+}
+
+// postProcessorsID identifies the chain postProcessorsByName builds from
+// names and echoLabels, for Translator.SetPostProcessors.
+func postProcessorsID(names []string, echoLabels map[string][]string) string {
+	id := strings.Join(names, ",")
+	if slices.Contains(names, PostStripEcho) && len(echoLabels) > 0 {
+		// Map keys marshal sorted, so equal labels give equal IDs.
+		b, _ := json.Marshal(echoLabels)
+		id += " echo:" + string(b)
+	}
+	return id
+}
+
+// applyPostProcessors runs chain over translated in order.
+func applyPostProcessors(chain []PostProcessor, src, translated string) string {
+	for _, p := range chain {
+		translated = p(src, translated)
+	}
+	return translated
+}
+
+// TrimSpace removes leading and trailing whitespace.
+func TrimSpace(_, translated string) string {
+	return strings.TrimSpace(translated)
+}
+
+// quotePairs are the opening/closing quotes models tend to wrap output in.
+var quotePairs = map[rune]rune{
+	'"':  '"',
+	'\'': '\'',
+	'“':  '”',
+	'‘':  '’',
+	'「':  '」',
+	'『':  '』',
+	'«':  '»',
+}
+
+// StripQuotes removes one pair of quotes surrounding the whole translation,
+// unless the source was quoted the same way.
+func StripQuotes(src, translated string) string {
+	t := strings.TrimSpace(translated)
+	if !isQuoted(t) || isQuoted(strings.TrimSpace(src)) {
+		return translated
+	}
+	_, open := utf8.DecodeRuneInString(t)
+	_, close := utf8.DecodeLastRuneInString(t)
+	return t[open : len(t)-close]
+}
+
+// isQuoted reports whether s starts and ends with a matching quote pair.
+func isQuoted(s string) bool {
+	first, n := utf8.DecodeRuneInString(s)
+	last, _ := utf8.DecodeLastRuneInString(s)
+	closing, ok := quotePairs[first]
+	return ok && len(s) > n && last == closing
+}
+
+// defaultEchoLabels are the labels models put before a translation, by
+// language of the label.
+var defaultEchoLabels = map[string][]string{
+	"en": {"Translation:", "Translated text:"},
+	"zh": {"译文：", "译文:", "翻译：", "翻译:"},
+	"ja": {"翻訳：", "翻訳:", "訳文："},
+	"ko": {"번역:"},
+	"de": {"Übersetzung:"},
+	"fr": {"Traduction :", "Traduction:"},
+	"es": {"Traducción:"},
+}
+
+// StripEcho returns a post-processor that removes a leading label such as
+// "Translation:" or "译文：", and a leading copy of the source text when a
+// line break or label follows it.
+// labels adds to or, per language, replaces defaultEchoLabels. Labels of
+// every language are checked because models often label in the prompt's
+// language rather than the target's.
+func StripEcho(labels map[string][]string) PostProcessor {
+	merged := maps.Clone(defaultEchoLabels)
+	maps.Copy(merged, labels)
+	var all []string
+	for _, ls := range merged {
+		all = append(all, ls...)
+	}
+	// Longest first, so "Translated text:" wins over a shorter prefix.
+	slices.SortFunc(all, func(a, b string) int { return len(b) - len(a) })
+
+	return func(src, translated string) string {
+		t := stripLabel(strings.TrimSpace(translated), all)
+		if s := strings.TrimSpace(src); s != "" {
+			if rest, ok := strings.CutPrefix(t, s); ok {
+				// Only an echo on a line of its own or followed by a label
+				// counts; otherwise the translation merely starts with the
+				// source, as names and code often do.
+				lineBreak := strings.HasPrefix(strings.TrimLeft(rest, " \t"), "\n") ||
+					strings.HasPrefix(strings.TrimLeft(rest, " \t"), "\r")
+				rest = strings.TrimSpace(rest)
+				if after := stripLabel(rest, all); rest != "" && (lineBreak || after != rest) {
+					t = after
+				}
+			}
+		}
+		if t == "" {
+			return translated
+		}
+		return t
+	}
+}
+
+// stripLabel removes the first of labels that prefixes s, ignoring case.
+func stripLabel(s string, labels []string) string {
+	for _, l := range labels {
+		if len(s) >= len(l) && strings.EqualFold(s[:len(l)], l) {
+			return strings.TrimSpace(s[len(l):])
+		}
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.aimuz.me/transy/cache"
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestStripQuotes(t *testing.T) {
+	tests := []struct {
+		name       string
+		src        string
+		translated string
+		want       string
+	}{
+		{"ascii quotes", "Hello", `"你好"`, "你好"},
+		{"curly quotes", "Hello", "“Bonjour”", "Bonjour"},
+		{"corner brackets", "Hello", "「こんにちは」", "こんにちは"},
+		{"padded", "Hello", "  'Hola'\n", "Hola"},
+		{"source quoted", `"Hello"`, `"你好"`, `"你好"`},
+		{"inner quotes kept", "He said hi", `他说"嗨"`, `他说"嗨"`},
+		{"mismatched", "Hello", "“Hallo\"", "“Hallo\""},
+		{"single quote char", "x", `"`, `"`},
+		{"unquoted", "Hello", "Hallo", "Hallo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuotes(tt.src, tt.translated); got != tt.want {
+				t.Errorf("StripQuotes(%q, %q) = %q, want %q", tt.src, tt.translated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripEcho(t *testing.T) {
+	strip := StripEcho(map[string][]string{"de": {"Deutsch:"}})
+
+	tests := []struct {
+		name       string
+		src        string
+		translated string
+		want       string
+	}{
+		{"clean", "Hello", "Hallo", "Hallo"},
+		{"english label", "Hello", "Translation: 你好", "你好"},
+		{"label case-insensitive", "Hello", "translation:  Hola", "Hola"},
+		{"chinese label", "Good morning", "译文：早上好", "早上好"},
+		{"chinese ascii colon", "Good morning", "翻译: 早上好", "早上好"},
+		{"japanese label", "Thanks", "翻訳：ありがとう", "ありがとう"},
+		{"custom label", "Hello", "Deutsch: Hallo", "Hallo"},
+		{"echo then newline", "Hello", "Hello\nBonjour", "Bonjour"},
+		{"echo then label", "你好", "你好\nTranslation: Hello", "Hello"},
+		{"only echo kept", "OK", "OK", "OK"},
+		{"only label kept", "Hello", "Translation:", "Translation:"},
+		{"label mid-text kept", "Hello", "Die Translation: ist gut", "Die Translation: ist gut"},
+		{"echo then same-line label", "Hello", "Hello Translation: Bonjour", "Bonjour"},
+		{"source as prefix kept", "Apple", "Apple（苹果）", "Apple（苹果）"},
+		{"source word kept", "Go", "Go 语言", "Go 语言"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strip(tt.src, tt.translated); got != tt.want {
+				t.Errorf("StripEcho()(%q, %q) = %q, want %q", tt.src, tt.translated, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostProcessorOrder(t *testing.T) {
+	appendTag := func(tag string) PostProcessor {
+		return func(_, s string) string { return s + tag }
+	}
+	chain := []PostProcessor{TrimSpace, appendTag("[a]"), appendTag("[b]")}
+	if got := applyPostProcessors(chain, "", "  x  "); got != "x[a][b]" {
+		t.Errorf("applyPostProcessors() = %q, want %q", got, "x[a][b]")
+	}
+
+	// Quotes are only visible to the stripper once whitespace is gone.
+	named, err := postProcessorsByName([]string{PostTrimSpace, PostStripQuotes}, nil)
+	if err != nil {
+		t.Fatalf("postProcessorsByName() error = %v", err)
+	}
+	if got := applyPostProcessors(named, "Hi", ` "Hallo" `); got != "Hallo" {
+		t.Errorf("trim+strip = %q, want %q", got, "Hallo")
+	}
+
+	if _, err := postProcessorsByName([]string{"bogus"}, nil); err == nil {
+		t.Error("postProcessorsByName(bogus): want error")
+	}
+}
+
+func TestPostProcessorsID(t *testing.T) {
+	labels := map[string][]string{"zh": {"译文"}}
+	trim := postProcessorsID([]string{PostTrimSpace}, labels)
+	echo := postProcessorsID([]string{PostTrimSpace, PostStripEcho}, labels)
+
+	if trim == echo {
+		t.Errorf("IDs of different chains are equal: %q", trim)
+	}
+	if trim != postProcessorsID([]string{PostTrimSpace}, nil) {
+		t.Error("echo labels changed the ID of a chain without strip_echo")
+	}
+	if echo == postProcessorsID([]string{PostTrimSpace, PostStripEcho}, nil) {
+		t.Error("echo labels did not change the ID of a chain with strip_echo")
+	}
+}
+
+func TestTranslatorPostProcessCached(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+
+	tr := NewTranslator(c)
+	tr.SetPostProcessors("trim,strip_quotes", TrimSpace, StripQuotes)
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	req := types.TranslateRequest{Text: "Hello", SourceLang: "en", TargetLang: "zh"}
+	model := &mockCompleter{response: " \"你好\" "}
+
+	result, err := tr.Translate(context.Background(), model, profile, req)
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	if result.Text != "你好" {
+		t.Errorf("Translate() = %q, want %q", result.Text, "你好")
+	}
+
+	// The cache holds post-processed text.
+	cached, err := tr.Translate(context.Background(), &mockCompleter{err: errors.New("not cached")}, profile, req)
+	if err != nil {
+		t.Fatalf("Translate() cached error = %v", err)
+	}
+	if !cached.Usage.CacheHit || cached.Text != "你好" {
+		t.Errorf("cached = %q (hit %v), want %q", cached.Text, cached.Usage.CacheHit, "你好")
+	}
+
+	// Another chain doesn't reuse it.
+	tr.SetPostProcessors("trim", TrimSpace)
+	result, err = tr.Translate(context.Background(), model, profile, req)
+	if err != nil {
+		t.Fatalf("Translate() new chain error = %v", err)
+	}
+	if result.Usage.CacheHit || result.Text != `"你好"` {
+		t.Errorf("new chain = %q (hit %v), want %q translated afresh", result.Text, result.Usage.CacheHit, `"你好"`)
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// defaultMaxSegments is the in-memory segment limit when none is configured.
+const defaultMaxSegments = 1000
+
+// segmentSpill persists segments evicted from memory during long sessions.
+type segmentSpill interface {
+	// Append stores segs after any stored earlier. A segment stored again
+	// with the same ID replaces the earlier copy in place.
+	Append(segs []types.LiveTranscript) error
+	// Load returns the stored segments in order.
+	Load() ([]types.LiveTranscript, error)
+	// Reset removes all stored segments.
+	Reset() error
+}
+
+// segmentStore keeps the finalized transcripts of the current live session,
+// in arrival order, plus any re-translations of them. With a spill set,
+// segments beyond the in-memory limit are flushed to it oldest first.
+// Safe for concurrent use.
+type segmentStore struct {
+	mu           sync.Mutex
+	segments     []types.LiveTranscript            // In-memory tail of the session
+	index        map[string]int                    // Segment ID -> position
+	retranslated map[string][]types.LiveTranscript // Target language -> segments
+
+	max     int                 // In-memory limit; only used with spill
+	spill   segmentSpill        // Nil keeps every segment in memory
+	flushed map[string]struct{} // IDs of segments moved to spill
+}
+
+func newSegmentStore() *segmentStore {
+	return &segmentStore{
+		index:        make(map[string]int),
+		retranslated: make(map[string][]types.LiveTranscript),
+		flushed:      make(map[string]struct{}),
+	}
+}
+
+// SetRollover keeps at most max segments in memory, flushing older ones to
+// spill. A max of zero or less uses defaultMaxSegments.
+func (ss *segmentStore) SetRollover(max int, spill segmentSpill) {
+	if max <= 0 {
+		max = defaultMaxSegments
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.max = max
+	ss.spill = spill
+}
+
+// Reset drops all segments, e.g. when a new session starts.
+func (ss *segmentStore) Reset() {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.segments = nil
+	clear(ss.index)
+	clear(ss.retranslated)
+	clear(ss.flushed)
+	if ss.spill != nil {
+		if err := ss.spill.Reset(); err != nil {
+			slog.Warn("reset session spill", "error", err)
+		}
+	}
+}
+
+// Put records a final transcript, replacing an earlier version with the
+// same ID (the translation arrives after the source text).
+func (ss *segmentStore) Put(t types.LiveTranscript) {
+	if !t.IsFinal {
+		return
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	if i, ok := ss.index[t.ID]; ok {
+		ss.segments[i] = t
+		return
+	}
+	if _, ok := ss.flushed[t.ID]; ok {
+		// A late update, e.g. the translation, for a flushed segment.
+		if err := ss.spill.Append([]types.LiveTranscript{t}); err != nil {
+			slog.Warn("update flushed segment", "id", t.ID, "error", err)
+		}
+		return
+	}
+	ss.index[t.ID] = len(ss.segments)
+	ss.segments = append(ss.segments, t)
+	ss.rollover()
+}
+
+// rollover flushes the oldest segments beyond the in-memory limit to the
+// spill. On failure they stay in memory and the flush is retried on the
+// next Put. Caller holds mu.
+func (ss *segmentStore) rollover() {
+	n := len(ss.segments) - ss.max
+	if ss.spill == nil || n <= 0 {
+		return
+	}
+	evicted := ss.segments[:n]
+	if err := ss.spill.Append(evicted); err != nil {
+		slog.Warn("flush session segments", "count", n, "error", err)
+		return
+	}
+	for _, t := range evicted {
+		ss.flushed[t.ID] = struct{}{}
+	}
+
+	// Copy so the evicted segments can be freed.
+	ss.segments = slices.Clone(ss.segments[n:])
+	clear(ss.index)
+	for i, t := range ss.segments {
+		ss.index[t.ID] = i
+	}
+}
+
+// Segments returns a copy of every segment of the session, including any
+// flushed to the spill.
+func (ss *segmentStore) Segments() []types.LiveTranscript {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var out []types.LiveTranscript
+	if ss.spill != nil && len(ss.flushed) > 0 {
+		spilled, err := ss.spill.Load()
+		if err != nil {
+			slog.Warn("load flushed segments", "error", err)
+		}
+		out = spilled
+	}
+	return append(out, ss.segments...)
+}
+
+// Import replaces the session with segs, e.g. loaded from a JSON export.
+// Imported segments count as final.
+func (ss *segmentStore) Import(segs []types.LiveTranscript) {
+	ss.Reset()
+	for _, t := range segs {
+		t.IsFinal = true
+		ss.Put(t)
+	}
+}
+
+// Edit replaces the texts of the segment with id, reporting whether it
+// exists. Re-translations made before the edit are kept.
+func (ss *segmentStore) Edit(id, sourceText, targetText string) bool {
+	for _, t := range ss.Segments() {
+		if t.ID == id {
+			t.SourceText = sourceText
+			t.TargetText = targetText
+			ss.Put(t)
+			return true
+		}
+	}
+	return false
+}
+
+// Recent returns a copy of the segments still held in memory, the most
+// recent part of the session.
+func (ss *segmentStore) Recent() []types.LiveTranscript {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return append([]types.LiveTranscript(nil), ss.segments...)
+}
+
+// SetTranslations stores segs as the session re-translated into lang.
+func (ss *segmentStore) SetTranslations(lang string, segs []types.LiveTranscript) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.retranslated[lang] = segs
+}
+
+// Translations returns a copy of the session re-translated into lang.
+func (ss *segmentStore) Translations(lang string) ([]types.LiveTranscript, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	segs, ok := ss.retranslated[lang]
+	return append([]types.LiveTranscript(nil), segs...), ok
+}
+
+// retranslateSegments translates the source text of each segment into
+// targetLang using at most workers concurrent requests. The result keeps the
+// input order; segments that fail keep an empty TargetText and their errors
+// are joined into the returned error.
+func retranslateSegments(ctx context.Context, segs []types.LiveTranscript, targetLang string, workers int, translate translateFunc) ([]types.LiveTranscript, error) {
+	out := make([]types.LiveTranscript, len(segs))
+	errs := make([]error, len(segs))
+	for i, seg := range segs {
+		seg.TargetLang = targetLang
+		seg.TargetText = ""
+		out[i] = seg
+	}
+
+	forEachLimit(len(out), workers, func(i int) {
+		seg := out[i]
+		res, err := translate(ctx, types.TranslateRequest{
+			Text:       seg.SourceText,
+			SourceLang: seg.SourceLang,
+			TargetLang: targetLang,
+		})
+		if err != nil {
+			errs[i] = fmt.Errorf("segment %s: %w", seg.ID, err)
+			return
+		}
+		out[i].TargetText = res.Text
+	})
+
+	return out, errors.Join(errs...)
+}
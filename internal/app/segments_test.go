@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.aimuz.me/transy/cache"
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+func TestSegmentStorePut(t *testing.T) {
+	ss := newSegmentStore()
+	ss.Put(types.LiveTranscript{ID: "a", SourceText: "partial"}) // not final
+	ss.Put(types.LiveTranscript{ID: "a", SourceText: "one", IsFinal: true})
+	ss.Put(types.LiveTranscript{ID: "b", SourceText: "two", IsFinal: true})
+	ss.Put(types.LiveTranscript{ID: "a", SourceText: "one", TargetText: "eins", IsFinal: true})
+
+	got := ss.Segments()
+	if len(got) != 2 {
+		t.Fatalf("Segments() len = %d, want 2", len(got))
+	}
+	if got[0].ID != "a" || got[0].TargetText != "eins" || got[1].ID != "b" {
+		t.Errorf("Segments() = %+v", got)
+	}
+
+	ss.SetTranslations("fr", got)
+	ss.Reset()
+	if n := len(ss.Segments()); n != 0 {
+		t.Errorf("Segments() after Reset len = %d, want 0", n)
+	}
+	if _, ok := ss.Translations("fr"); ok {
+		t.Error("Translations() after Reset: want none")
+	}
+}
+
+// prefixCompleter "translates" by tagging the text and records the peak
+// number of concurrent calls.
+type prefixCompleter struct {
+	calls   atomic.Int32
+	mu      sync.Mutex
+	active  int
+	peak    int
+	failFor string
+}
+
+func (m *prefixCompleter) Complete(_ context.Context, msgs []llm.Message) (string, types.Usage, error) {
+	m.calls.Add(1)
+	m.mu.Lock()
+	m.active++
+	m.peak = max(m.peak, m.active)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.active--
+		m.mu.Unlock()
+	}()
+
+	_, text, _ := strings.Cut(msgs[len(msgs)-1].Content, ":\n\n")
+	if text == m.failFor {
+		return "", types.Usage{}, errors.New("boom")
+	}
+	return "[de] " + text, types.Usage{}, nil
+}
+
+func TestRetranslateSegments(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+
+	tr := NewTranslator(c)
+	completer := &prefixCompleter{}
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	translate := func(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+		return tr.Translate(ctx, completer, profile, req)
+	}
+
+	var segs []types.LiveTranscript
+	for _, text := range []string{"one", "two", "three", "four", "five", "six"} {
+		segs = append(segs, types.LiveTranscript{
+			ID: text, SourceText: text, SourceLang: "en",
+			TargetText: "old", TargetLang: "zh", IsFinal: true,
+		})
+	}
+
+	got, err := retranslateSegments(context.Background(), segs, "de", 2, translate)
+	if err != nil {
+		t.Fatalf("retranslateSegments() error = %v", err)
+	}
+	for i, seg := range got {
+		if want := "[de] " + segs[i].SourceText; seg.TargetText != want || seg.TargetLang != "de" || seg.ID != segs[i].ID {
+			t.Errorf("segment %d = %+v, want target %q in de", i, seg, want)
+		}
+	}
+	if segs[0].TargetText != "old" {
+		t.Error("input segments were modified")
+	}
+	if completer.peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", completer.peak)
+	}
+
+	// A second pass is served from the cache.
+	calls := completer.calls.Load()
+	if _, err := retranslateSegments(context.Background(), segs, "de", 2, translate); err != nil {
+		t.Fatalf("retranslateSegments() error = %v", err)
+	}
+	if n := completer.calls.Load(); n != calls {
+		t.Errorf("completer calls on cached pass = %d, want %d", n-calls, 0)
+	}
+}
+
+func TestRetranslateSegmentsPartialFailure(t *testing.T) {
+	tr := NewTranslator(nil)
+	completer := &prefixCompleter{failFor: "two"}
+	translate := func(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+		return tr.Translate(ctx, completer, TranslateProfile{Name: "test"}, req)
+	}
+	segs := []types.LiveTranscript{
+		{ID: "1", SourceText: "one", IsFinal: true},
+		{ID: "2", SourceText: "two", IsFinal: true},
+		{ID: "3", SourceText: "three", IsFinal: true},
+	}
+
+	got, err := retranslateSegments(context.Background(), segs, "de", 4, translate)
+	if err == nil || !strings.Contains(err.Error(), "segment 2") {
+		t.Errorf("retranslateSegments() error = %v, want failure for segment 2", err)
+	}
+	if got[0].TargetText != "[de] one" || got[1].TargetText != "" || got[2].TargetText != "[de] three" {
+		t.Errorf("retranslateSegments() = %+v", got)
+	}
+}
+
+func TestSegmentStoreRollover(t *testing.T) {
+	spill := fileSpill{path: filepath.Join(t.TempDir(), "session.jsonl")}
+	ss := newSegmentStore()
+	ss.SetRollover(3, spill)
+
+	seg := func(id string) types.LiveTranscript {
+		return types.LiveTranscript{ID: id, SourceText: id, IsFinal: true}
+	}
+	ids := func(segs []types.LiveTranscript) string {
+		var b strings.Builder
+		for _, s := range segs {
+			b.WriteString(s.ID)
+		}
+		return b.String()
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		ss.Put(seg(id))
+	}
+	if got, _ := spill.Load(); len(got) != 0 {
+		t.Fatalf("flushed at the limit: %+v", got)
+	}
+
+	ss.Put(seg("d"))
+	ss.Put(seg("e"))
+	if got := ids(ss.Recent()); got != "cde" {
+		t.Errorf("Recent() = %q, want %q", got, "cde")
+	}
+	if got, _ := spill.Load(); ids(got) != "ab" {
+		t.Errorf("spilled = %q, want %q", ids(got), "ab")
+	}
+	if got := ids(ss.Segments()); got != "abcde" {
+		t.Errorf("Segments() = %q, want %q", got, "abcde")
+	}
+
+	// A late translation for a flushed segment updates it in place.
+	late := seg("a")
+	late.TargetText = "A"
+	ss.Put(late)
+	all := ss.Segments()
+	if ids(all) != "abcde" || all[0].TargetText != "A" {
+		t.Errorf("Segments() after late update = %+v", all)
+	}
+
+	// In-memory updates still work after eviction.
+	c := seg("c")
+	c.TargetText = "C"
+	ss.Put(c)
+	if got := ss.Recent(); got[0].TargetText != "C" {
+		t.Errorf("Recent()[0] = %+v, want updated c", got[0])
+	}
+
+	ss.Reset()
+	if n := len(ss.Segments()); n != 0 {
+		t.Errorf("Segments() after Reset len = %d, want 0", n)
+	}
+	if _, err := os.Stat(spill.path); !os.IsNotExist(err) {
+		t.Errorf("spill file still exists after Reset: %v", err)
+	}
+}
+
+func TestSegmentStoreNoSpillKeepsAll(t *testing.T) {
+	ss := newSegmentStore()
+	for i := range defaultMaxSegments + 5 {
+		ss.Put(types.LiveTranscript{ID: strconv.Itoa(i), IsFinal: true})
+	}
+	if n := len(ss.Recent()); n != defaultMaxSegments+5 {
+		t.Errorf("Recent() len = %d, want all %d", n, defaultMaxSegments+5)
+	}
+}
+
+func TestSegmentStoreImport(t *testing.T) {
+	ss := newSegmentStore()
+	ss.Put(types.LiveTranscript{ID: "live", SourceText: "old", IsFinal: true})
+	ss.SetTranslations("ja", []types.LiveTranscript{{ID: "live"}})
+
+	ss.Import([]types.LiveTranscript{
+		{ID: "a", SourceText: "你好", TargetText: "Hello", IsFinal: true},
+		{ID: "b", SourceText: "再见"}, // Exported while pending
+	})
+
+	got := ss.Segments()
+	if len(got) != 2 || got[0].ID != "a" || got[1].ID != "b" {
+		t.Fatalf("Segments() = %+v, want imported a, b", got)
+	}
+	if !got[1].IsFinal {
+		t.Error("imported segment not marked final")
+	}
+	if _, ok := ss.Translations("ja"); ok {
+		t.Error("re-translations of the replaced session kept")
+	}
+
+	if !ss.Edit("b", "再见！", "Goodbye!") {
+		t.Fatal("Edit(b) = false, want true")
+	}
+	if got := ss.Segments()[1]; got.SourceText != "再见！" || got.TargetText != "Goodbye!" {
+		t.Errorf("edited segment = %+v", got)
+	}
+	if ss.Edit("missing", "", "") {
+		t.Error("Edit(missing) = true, want false")
+	}
+}
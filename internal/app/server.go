@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// maxRequestBody limits the size of a /translate request body.
+const maxRequestBody = 1 << 20
+
+// translateFunc performs a blocking translation with the active profile.
+type translateFunc func(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error)
+
+// localServer is an HTTP server bound to the loopback interface that exposes
+// translation to other local tools.
+type localServer struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// startLocalServer listens on 127.0.0.1:port and serves POST /translate.
+// Requests must carry "Authorization: Bearer <token>".
+func startLocalServer(port int, token string, translate translateFunc) (*localServer, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	srv := &http.Server{
+		Handler:           newLocalHandler(token, translate),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("local server", "error", err)
+		}
+	}()
+
+	slog.Info("local server started", "addr", ln.Addr().String())
+	return &localServer{srv: srv, ln: ln}, nil
+}
+
+// Addr returns the listening address.
+func (ls *localServer) Addr() string {
+	return ls.ln.Addr().String()
+}
+
+// Close shuts the server down, waiting briefly for in-flight requests.
+func (ls *localServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return ls.srv.Shutdown(ctx)
+}
+
+func newLocalHandler(token string, translate translateFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /translate", func(w http.ResponseWriter, r *http.Request) {
+		if !validToken(r, token) {
+			writeJSONError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		var req types.TranslateRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+		if req.Text == "" || req.TargetLang == "" {
+			writeJSONError(w, http.StatusBadRequest, "text and targetLang required")
+			return
+		}
+
+		result, err := translate(r.Context(), req)
+		if err != nil {
+			writeJSONError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+	return mux
+}
+
+func validToken(r *http.Request, token string) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if token == "" || len(auth) <= len(prefix) || auth[:len(prefix)] != prefix {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(token)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
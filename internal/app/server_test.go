@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestLocalHandler(t *testing.T) {
+	translate := func(_ context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+		if req.Text == "fail" {
+			return types.TranslateResult{}, errors.New("boom")
+		}
+		return types.TranslateResult{Text: "[" + req.TargetLang + "] " + req.Text}, nil
+	}
+	srv := httptest.NewServer(newLocalHandler("secret", translate))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		body       string
+		wantStatus int
+		wantText   string
+	}{
+		{"ok", http.MethodPost, "secret", `{"text":"hello","sourceLang":"en","targetLang":"zh"}`, http.StatusOK, "[zh] hello"},
+		{"missing token", http.MethodPost, "", `{"text":"hello","targetLang":"zh"}`, http.StatusUnauthorized, ""},
+		{"wrong token", http.MethodPost, "nope", `{"text":"hello","targetLang":"zh"}`, http.StatusUnauthorized, ""},
+		{"bad json", http.MethodPost, "secret", `{`, http.StatusBadRequest, ""},
+		{"missing text", http.MethodPost, "secret", `{"targetLang":"zh"}`, http.StatusBadRequest, ""},
+		{"translate error", http.MethodPost, "secret", `{"text":"fail","targetLang":"zh"}`, http.StatusBadGateway, ""},
+		{"wrong method", http.MethodGet, "secret", ``, http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+"/translate", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("do request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantText == "" {
+				return
+			}
+			var result types.TranslateResult
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if result.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", result.Text, tt.wantText)
+			}
+		})
+	}
+}
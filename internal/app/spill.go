@@ -0,0 +1,53 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"go.aimuz.me/transy/internal/jsonl"
+	"go.aimuz.me/transy/internal/types"
+)
+
+// fileSpill is a segmentSpill storing one JSON segment per line.
+type fileSpill struct {
+	path string
+}
+
+// Append writes segs to the end of the file, creating it if needed.
+func (f fileSpill) Append(segs []types.LiveTranscript) error {
+	if err := jsonl.Append(f.path, segs...); err != nil {
+		return fmt.Errorf("write spill: %w", err)
+	}
+	return nil
+}
+
+// Load reads the file back. Later lines for an ID replace the earlier one
+// at its original position. A missing file holds no segments.
+func (f fileSpill) Load() ([]types.LiveTranscript, error) {
+	lines, err := jsonl.Load[types.LiveTranscript](f.path, false)
+	if err != nil {
+		err = fmt.Errorf("read spill: %w", err)
+	}
+
+	var segs []types.LiveTranscript
+	index := make(map[string]int)
+	for _, t := range lines {
+		if i, ok := index[t.ID]; ok {
+			segs[i] = t
+			continue
+		}
+		index[t.ID] = len(segs)
+		segs = append(segs, t)
+	}
+	return segs, err
+}
+
+// Reset deletes the file.
+func (f fileSpill) Reset() error {
+	if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove spill: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/subtitle"
+)
+
+// translateCues translates the text of each timed cue into targetLang using
+// at most workers concurrent requests. Each request carries the previous
+// cue's text as context so sentences split across cues stay coherent, and
+// styling tags are protected from the model. Untimed blocks are copied as
+// is. Cues that fail keep their original text and their errors are joined
+// into the returned error.
+func translateCues(ctx context.Context, cues []subtitle.Cue, sourceLang, targetLang string, workers int, translate translateFunc) ([]subtitle.Cue, error) {
+	out := append([]subtitle.Cue(nil), cues...)
+	errs := make([]error, len(cues))
+
+	var idx []int
+	var reqs []types.TranslateRequest
+	var prev string
+	for i, cue := range cues {
+		if !cue.IsCue() || strings.TrimSpace(cue.Text) == "" {
+			continue
+		}
+		idx = append(idx, i)
+		reqs = append(reqs, types.TranslateRequest{
+			Text:           cue.Text,
+			SourceLang:     sourceLang,
+			TargetLang:     targetLang,
+			Context:        prev,
+			PreserveFormat: FormatSubtitle,
+		})
+		prev = cue.Text
+	}
+
+	forEachLimit(len(reqs), workers, func(j int) {
+		i := idx[j]
+		res, err := translate(ctx, reqs[j])
+		if err != nil {
+			errs[i] = fmt.Errorf("cue %s: %w", cueName(cues[i], i), err)
+			return
+		}
+		out[i].Text = strings.TrimSpace(res.Text)
+	})
+
+	return out, errors.Join(errs...)
+}
+
+// cueName identifies a cue in errors by its ID, or its position if it has none.
+func cueName(c subtitle.Cue, i int) string {
+	if c.ID != "" {
+		return c.ID
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
+
+// cueText joins the text of the timed cues, for language detection.
+func cueText(cues []subtitle.Cue) string {
+	var b strings.Builder
+	for _, c := range cues {
+		if c.IsCue() {
+			b.WriteString(c.Text + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/subtitle"
+)
+
+const sampleSRT = `1
+00:00:01,000 --> 00:00:03,000
+Hello there.
+
+2
+00:00:03,500 --> 00:00:06,000
+<i>This line is</i>
+split in two.
+
+3
+00:00:06,500 --> 00:00:08,000
+{\an8}Goodbye.
+`
+
+func TestTranslateCues(t *testing.T) {
+	f, err := subtitle.Parse([]byte(sampleSRT))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	contexts := map[string]string{}
+	translate := func(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+		mu.Lock()
+		contexts[req.Text] = req.Context
+		mu.Unlock()
+		if req.PreserveFormat != FormatSubtitle {
+			t.Errorf("PreserveFormat = %q, want %q", req.PreserveFormat, FormatSubtitle)
+		}
+		return types.TranslateResult{Text: strings.ToUpper(req.Text)}, nil
+	}
+
+	out, err := translateCues(context.Background(), f.Cues, "en", "fr", 2, translate)
+	if err != nil {
+		t.Fatalf("translateCues() error = %v", err)
+	}
+	f.Cues = out
+
+	want := `1
+00:00:01,000 --> 00:00:03,000
+HELLO THERE.
+
+2
+00:00:03,500 --> 00:00:06,000
+<I>THIS LINE IS</I>
+SPLIT IN TWO.
+
+3
+00:00:06,500 --> 00:00:08,000
+{\AN8}GOODBYE.
+
+`
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+
+	if c := contexts["Hello there."]; c != "" {
+		t.Errorf("first cue context = %q, want empty", c)
+	}
+	if c := contexts["{\\an8}Goodbye."]; c != "<i>This line is</i>\nsplit in two." {
+		t.Errorf("third cue context = %q, want previous cue", c)
+	}
+}
+
+func TestTranslateCuesPartialFailure(t *testing.T) {
+	f, err := subtitle.Parse([]byte(sampleSRT))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	translate := func(ctx context.Context, req types.TranslateRequest) (types.TranslateResult, error) {
+		if strings.Contains(req.Text, "Goodbye") {
+			return types.TranslateResult{}, errors.New("boom")
+		}
+		return types.TranslateResult{Text: "ok"}, nil
+	}
+
+	out, err := translateCues(context.Background(), f.Cues, "en", "fr", 1, translate)
+	if err == nil || !strings.Contains(err.Error(), "cue 3") {
+		t.Fatalf("error = %v, want failure for cue 3", err)
+	}
+	if out[0].Text != "ok" || out[2].Text != `{\an8}Goodbye.` {
+		t.Errorf("cues = %q, %q; want translated first cue and original third", out[0].Text, out[2].Text)
+	}
+}
+
+func TestProtectSubtitleTags(t *testing.T) {
+	text := `{\an8}<i>Hello</i> <font color="red">world</font>`
+	protected, slots := protectMarkup(text, FormatSubtitle)
+	for _, k := range []string{`{\an8}`, "<i>", "</i>", `<font color="red">`, "</font>"} {
+		if strings.Contains(protected, k) {
+			t.Errorf("protected text still contains %q: %q", k, protected)
+		}
+	}
+	if got := restoreMarkup(protected, slots); got != text {
+		t.Errorf("restoreMarkup() = %q, want %q", got, text)
+	}
+}
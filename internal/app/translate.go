@@ -2,10 +2,17 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"go.aimuz.me/transy/cache"
+	"go.aimuz.me/transy/config"
 	"go.aimuz.me/transy/internal/types"
 	"go.aimuz.me/transy/llm"
 )
@@ -13,7 +20,9 @@ import (
 // Translator encapsulates translation logic with caching.
 // Zero value is not useful; create via NewTranslator.
 type Translator struct {
-	cache *cache.Cache
+	cache    *cache.Cache
+	post     atomic.Pointer[postChain]
+	estimate atomic.Bool // Estimate usage the provider didn't report
 }
 
 // NewTranslator creates a Translator with optional caching.
@@ -22,6 +31,62 @@ func NewTranslator(c *cache.Cache) *Translator {
 	return &Translator{cache: c}
 }
 
+// postChain is a post-processor chain and the ID distinguishing its
+// output in cache keys.
+type postChain struct {
+	id    string
+	procs []PostProcessor
+}
+
+// SetPostProcessors replaces the chain applied to every translation, in
+// order. The cache holds post-processed text, so id must identify the
+// chain: translations cached under another ID are not reused.
+func (t *Translator) SetPostProcessors(id string, chain ...PostProcessor) {
+	t.post.Store(&postChain{id: id, procs: chain})
+}
+
+// SetEstimateUsage sets whether Translate estimates token usage when a
+// provider reports none, as some OpenAI-compatible endpoints do outside
+// streaming. Estimated usage is marked as such.
+func (t *Translator) SetEstimateUsage(enabled bool) {
+	t.estimate.Store(enabled)
+}
+
+// fillUsage returns u, or an estimate for msgs and output if u is empty and
+// estimation is enabled.
+func (t *Translator) fillUsage(u types.Usage, model string, msgs []llm.Message, output string) types.Usage {
+	if u.TotalTokens > 0 || u.PromptTokens > 0 || u.CompletionTokens > 0 || !t.estimate.Load() {
+		return u
+	}
+	prompt := llm.EstimateMessagesTokens(model, msgs)
+	completion := llm.EstimateTokens(model, output)
+	return types.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+		Estimated:        true,
+	}
+}
+
+// addUsage returns the combined usage of two calls.
+func addUsage(a, b types.Usage) types.Usage {
+	return types.Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+		Estimated:        a.Estimated || b.Estimated,
+	}
+}
+
+// postProcess applies the post-processor chain to translated.
+func (t *Translator) postProcess(src, translated string) string {
+	chain := t.post.Load()
+	if chain == nil {
+		return translated
+	}
+	return applyPostProcessors(chain.procs, src, translated)
+}
+
 // Translate performs translation using the given completer, with cache lookup.
 func (t *Translator) Translate(ctx context.Context, completer llm.Completer, profile TranslateProfile, req types.TranslateRequest) (types.TranslateResult, error) {
 	key := t.cacheKey(profile, req)
@@ -31,19 +96,131 @@ func (t *Translator) Translate(ctx context.Context, completer llm.Completer, pro
 		return result, nil
 	}
 
-	// Build messages
-	msgs := buildTranslateMessages(profile.SystemPrompt, req)
+	src := req.Text
+	msgs, slots := profile.prepare(req)
 
-	// Call LLM
+	// Call LLM, retrying once if the output length looks wrong. Length is
+	// judged on the final text, as in TranslateStream.
 	text, usage, err := completer.Complete(ctx, msgs)
 	if err != nil {
 		return types.TranslateResult{}, fmt.Errorf("translate: %w", err)
 	}
+	usage = t.fillUsage(usage, profile.Model, msgs, text)
+	final := t.postProcess(src, restoreMarkup(text, slots))
+	if !profile.lengthOK(src, final) {
+		slog.Warn("suspicious translation length, retrying", "profile", profile.Name, "source", len(src), "output", len(final))
+		retry, retryUsage, err := completer.Complete(ctx, msgs)
+		if err != nil {
+			return types.TranslateResult{}, fmt.Errorf("translate: %w", err)
+		}
+		// Both calls were billed.
+		usage = addUsage(usage, t.fillUsage(retryUsage, profile.Model, msgs, retry))
+		final = t.postProcess(src, restoreMarkup(retry, slots))
+		if !profile.lengthOK(src, final) {
+			return types.TranslateResult{}, fmt.Errorf("translate: %w", ErrSuspiciousLength)
+		}
+	}
 
 	// Store in cache (best effort)
-	t.setCache(key, text, usage)
+	t.setCache(key, final, usage)
 
-	return types.TranslateResult{Text: text, Usage: usage}, nil
+	return types.TranslateResult{Text: final, Usage: usage}, nil
+}
+
+// ErrStreamNeedsRestore is returned by TranslateStream for requests whose
+// placeholders can only be restored on the full text; use Translate.
+var ErrStreamNeedsRestore = errors.New("request preserves markup and can't be streamed")
+
+// TranslateStream translates req with streamer, sending results as deltas
+// arrive. Each result carries the text so far; the last one has Done set
+// and carries the post-processed text and usage, or Error if the stream
+// broke off. A cached translation arrives as a single final result. Only
+// complete streams of plausible length are cached. The channel is closed
+// after the final result, or early if ctx is cancelled.
+func (t *Translator) TranslateStream(ctx context.Context, streamer llm.StreamCompleter, profile TranslateProfile, req types.TranslateRequest) (<-chan types.TranslateResult, error) {
+	if profile.needsRestore(req) {
+		return nil, ErrStreamNeedsRestore
+	}
+
+	key := t.cacheKey(profile, req)
+	out := make(chan types.TranslateResult, 16)
+	if cached, ok := t.getCached(key); ok {
+		cached.Done = true
+		out <- cached
+		close(out)
+		return out, nil
+	}
+
+	msgs, _ := profile.prepare(req)
+	ch, err := streamer.StreamComplete(ctx, msgs)
+	if err != nil {
+		return nil, fmt.Errorf("stream translate: %w", err)
+	}
+
+	go func() {
+		defer close(out)
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in translate stream", "recover", r)
+			}
+		}()
+		send := func(r types.TranslateResult) bool {
+			select {
+			case out <- r:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var text string
+		for delta := range ch {
+			if delta.Text != "" {
+				text += delta.Text
+				if !send(types.TranslateResult{Text: text}) {
+					return
+				}
+			}
+			if delta.Err != nil {
+				// Partial output is shown but not cached.
+				slog.Warn("translate stream interrupted", "error", delta.Err)
+				send(types.TranslateResult{Text: text, Usage: delta.Usage, Done: true, Error: delta.Err.Error()})
+				return
+			}
+			if delta.Done {
+				usage := t.fillUsage(delta.Usage, profile.Model, msgs, text)
+				final := t.postProcess(req.Text, text)
+				send(types.TranslateResult{Text: final, Usage: usage, Done: true})
+
+				// Streamed output can't be retried; just keep suspicious results out of the cache.
+				if !profile.lengthOK(req.Text, final) {
+					slog.Warn("suspicious translation length, not caching", "profile", profile.Name)
+					return
+				}
+				t.setCache(key, final, usage)
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// PromptPreview describes what would be sent for a translation request.
+type PromptPreview struct {
+	Model           string        `json:"model"`
+	Messages        []llm.Message `json:"messages"`
+	EstimatedTokens int           `json:"estimatedTokens"` // Approximate prompt tokens
+}
+
+// Preview renders the messages for req without calling the LLM, exactly
+// as Translate would send them.
+func (t *Translator) Preview(profile TranslateProfile, req types.TranslateRequest) PromptPreview {
+	msgs, _ := profile.prepare(req)
+	return PromptPreview{
+		Model:           profile.Model,
+		Messages:        msgs,
+		EstimatedTokens: llm.EstimateMessagesTokens(profile.Model, msgs),
+	}
 }
 
 // TranslateProfile holds the minimal config needed for translation.
@@ -51,29 +228,319 @@ type TranslateProfile struct {
 	Name         string
 	Model        string
 	SystemPrompt string
+	UseContext   bool // Include req.Context in the prompt
+	Examples     []types.TranslationExample
+	Emoji        bool   // Preserve emoji via placeholders
+	Units        bool   // Localize units and number formats
+	Instruction  string // Template for each user turn; empty uses translateInstruction
+	Formality    string // types.Formality*; empty leaves the register to the model
+	Tone         string // types.Tone*; empty sends no tone instruction
+
+	// instruction is Instruction parsed by translateProfileOf; nil uses
+	// translateInstruction.
+	instruction *template.Template
+
+	// Glossary holds the profile's terminology rules; request entries
+	// override them term by term.
+	Glossary map[string]string
+
+	// Output/source length ratio bounds; zero disables a bound.
+	MinLengthRatio float64
+	MaxLengthRatio float64
 }
 
-func buildTranslateMessages(systemPrompt string, req types.TranslateRequest) []llm.Message {
-	content := fmt.Sprintf(
-		"please translate the following text from %s to %s:\n\n%s",
-		req.SourceLang, req.TargetLang, req.Text,
-	)
+// ErrSuspiciousLength is returned when a translation stays outside the
+// profile's length ratio bounds after a retry.
+var ErrSuspiciousLength = errors.New("translation length outside expected range")
 
-	if req.Context != "" {
-		content = fmt.Sprintf(
-			"Context (previous sentences): %s\n\n%s",
-			req.Context, content,
+// minRatioCheckTokens is the smallest source, in estimated tokens, whose
+// length ratio is checked. Short inputs vary too much to judge.
+const minRatioCheckTokens = 8
+
+// lengthOK reports whether output is a plausible length for source.
+// Lengths are compared in estimated tokens, which count a CJK character
+// like a short word, so CJK↔Latin pairs don't misfire.
+func (p TranslateProfile) lengthOK(source, output string) bool {
+	if p.MinLengthRatio <= 0 && p.MaxLengthRatio <= 0 {
+		return true
+	}
+	src := llm.EstimateTokens(p.Model, source)
+	if src < minRatioCheckTokens {
+		return true
+	}
+	ratio := float64(llm.EstimateTokens(p.Model, output)) / float64(src)
+	if p.MinLengthRatio > 0 && ratio < p.MinLengthRatio {
+		return false
+	}
+	if p.MaxLengthRatio > 0 && ratio > p.MaxLengthRatio {
+		return false
+	}
+	return true
+}
+
+// translateProfileOf extracts the translation settings from a stored profile.
+func translateProfileOf(p *types.TranslationProfile) TranslateProfile {
+	instruction, err := config.ParseInstructionTemplate(p.InstructionTemplate)
+	if err != nil {
+		// Saved profiles are validated; fall back rather than fail.
+		slog.Warn("ignoring profile instruction template", "profile", p.Name, "error", err)
+	}
+	return TranslateProfile{
+		Name:           p.Name,
+		Model:          p.Model,
+		SystemPrompt:   p.SystemPrompt,
+		UseContext:     p.ContextEnabled(),
+		MinLengthRatio: p.MinLengthRatio,
+		MaxLengthRatio: p.MaxLengthRatio,
+		Examples:       capExamples(p.Examples),
+		Emoji:          p.PreserveEmoji,
+		Units:          p.LocalizeUnits,
+		Instruction:    p.InstructionTemplate,
+		instruction:    instruction,
+		Formality:      p.Formality,
+		Tone:           p.Tone,
+	}
+}
+
+// Few-shot example limits, so examples can't crowd out the real request.
+const (
+	maxExamples     = 8
+	maxExampleRunes = 4000 // Total across sources and targets
+)
+
+// capExamples returns the leading examples that fit within the limits,
+// skipping incomplete pairs.
+func capExamples(examples []types.TranslationExample) []types.TranslationExample {
+	var out []types.TranslationExample
+	budget := maxExampleRunes
+	for _, ex := range examples {
+		if len(out) == maxExamples {
+			break
+		}
+		if strings.TrimSpace(ex.Source) == "" || strings.TrimSpace(ex.Target) == "" {
+			continue
+		}
+		n := utf8.RuneCountInString(ex.Source) + utf8.RuneCountInString(ex.Target)
+		if n > budget {
+			break
+		}
+		budget -= n
+		out = append(out, ex)
+	}
+	return out
+}
+
+// protect replaces the parts of req.Text the model must not touch with
+// placeholders, returning the text and the originals.
+func (p TranslateProfile) protect(req types.TranslateRequest) (string, []string) {
+	text, slots := protectMarkup(req.Text, req.PreserveFormat)
+	if p.Emoji {
+		text, slots = protectEmoji(text, slots)
+	}
+	return text, slots
+}
+
+// prepare shields code, links, tags and emoji in req from the model and
+// builds the messages to send, returning the slots for restoreMarkup.
+func (p TranslateProfile) prepare(req types.TranslateRequest) ([]llm.Message, []string) {
+	protected, slots := p.protect(req)
+	req.Text = protected
+	return p.messages(req), slots
+}
+
+// needsRestore reports whether req's translation can only be finished on the
+// full text, because placeholders must be restored.
+func (p TranslateProfile) needsRestore(req types.TranslateRequest) bool {
+	return formatInstruction(req.PreserveFormat) != "" || (p.Emoji && emojiPattern.MatchString(req.Text))
+}
+
+// request applies the profile's policies to req.
+func (p TranslateProfile) request(req types.TranslateRequest) types.TranslateRequest {
+	if !p.UseContext {
+		req.Context = ""
+	}
+	req.Glossary = mergeGlossary(p.Glossary, req.Glossary)
+	return req
+}
+
+// localizeUnits is appended to the system prompt of profiles with Units set.
+const localizeUnits = "Convert units of measurement to those customary for the target language's locale " +
+	"(e.g. miles to kilometers, °F to °C), rounding sensibly, and format numbers, dates and currencies " +
+	"the way that locale writes them."
+
+// messages builds the LLM messages for req under this profile.
+func (p TranslateProfile) messages(req types.TranslateRequest) []llm.Message {
+	systemPrompt := p.SystemPrompt
+	if p.Units {
+		systemPrompt = strings.TrimSpace(systemPrompt + "\n\n" + localizeUnits)
+	}
+	style := styleInstruction(p.Formality, p.Tone)
+	return buildTranslateMessages(systemPrompt, p.instruction, style, p.Examples, p.request(req))
+}
+
+// Register instructions for TranslateProfile.Formality and Tone.
+const (
+	formalInstruction = "Use a formal register: polite forms and formal address where the target language has them " +
+		"(e.g. Sie in German, です/ます in Japanese, 존댓말 in Korean)."
+	informalInstruction = "Use an informal register: casual forms and familiar address where the target language has them " +
+		"(e.g. du in German, the plain form in Japanese, 반말 in Korean)."
+)
+
+var toneInstructions = map[string]string{
+	types.ToneNeutral:   "Keep the tone neutral.",
+	types.ToneFriendly:  "Use a warm, friendly tone.",
+	types.ToneTechnical: "Use a precise, technical tone and keep domain terminology exact.",
+}
+
+// styleInstruction returns the instruction for a formality and tone, or ""
+// if both are left to the model.
+func styleInstruction(formality, tone string) string {
+	var parts []string
+	switch formality {
+	case types.FormalityFormal:
+		parts = append(parts, formalInstruction)
+	case types.FormalityInformal:
+		parts = append(parts, informalInstruction)
+	}
+	if t := toneInstructions[tone]; t != "" {
+		parts = append(parts, t)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Automatic max_tokens sizing bounds.
+const (
+	minAutoMaxTokens = 256
+	maxAutoMaxTokens = 8192
+)
+
+// maxTokensFor returns the completion budget for text. An explicit profile
+// value is authoritative; zero sizes the budget from the input length,
+// leaving room for target languages that need more tokens than the source.
+func maxTokensFor(profileMax int, model, text string) int {
+	if profileMax > 0 {
+		return profileMax
+	}
+	n := llm.EstimateTokens(model, text)*3 + minAutoMaxTokens
+	return min(max(n, minAutoMaxTokens), maxAutoMaxTokens)
+}
+
+// buildTranslateMessages builds the system prompt, the few-shot examples as
+// prior user/assistant turns, and the request itself. A non-nil
+// instruction renders each user turn, placing the context and glossary
+// itself, in place of the built-in instruction. A non-empty style, from
+// styleInstruction, precedes the request.
+func buildTranslateMessages(systemPrompt string, instruction *template.Template, style string, examples []types.TranslationExample, req types.TranslateRequest) []llm.Message {
+	msgs := []llm.Message{{Role: "system", Content: systemPrompt}}
+	for _, ex := range examples {
+		msgs = append(msgs,
+			llm.Message{Role: "user", Content: renderInstruction(instruction, config.InstructionData{
+				SourceLang: req.SourceLang,
+				TargetLang: req.TargetLang,
+				Text:       ex.Source,
+			})},
+			llm.Message{Role: "assistant", Content: ex.Target},
 		)
 	}
 
-	return []llm.Message{
-		{Role: "system", Content: systemPrompt},
-		{Role: "user", Content: content},
+	var content string
+	if instruction != nil {
+		content = renderInstruction(instruction, config.InstructionData{
+			SourceLang: req.SourceLang,
+			TargetLang: req.TargetLang,
+			Text:       req.Text,
+			Context:    req.Context,
+			Glossary:   glossaryInstruction(req.Glossary),
+		})
+	} else {
+		content = translateInstruction(req.SourceLang, req.TargetLang, req.Text)
+		if req.Context != "" {
+			content = fmt.Sprintf(
+				"Context (previous sentences): %s\n\n%s",
+				req.Context, content,
+			)
+		}
+	}
+
+	if style != "" {
+		content = style + "\n\n" + content
+	}
+	instr := formatInstruction(req.PreserveFormat)
+	if instr == "" && placeholderRe.MatchString(req.Text) {
+		instr = keepPlaceholders
+	}
+	if instr != "" {
+		content = instr + "\n\n" + content
 	}
+	if instruction == nil {
+		if g := glossaryInstruction(req.Glossary); g != "" {
+			content = g + "\n\n" + content
+		}
+	}
+
+	return append(msgs, llm.Message{Role: "user", Content: content})
+}
+
+func translateInstruction(sourceLang, targetLang, text string) string {
+	return fmt.Sprintf(
+		"please translate the following text from %s to %s:\n\n%s",
+		sourceLang, targetLang, text,
+	)
+}
+
+// renderInstruction renders d with tmpl, or with the built-in instruction
+// if tmpl is nil or fails.
+func renderInstruction(tmpl *template.Template, d config.InstructionData) string {
+	if tmpl == nil {
+		return translateInstruction(d.SourceLang, d.TargetLang, d.Text)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, d); err != nil {
+		slog.Warn("render instruction template", "error", err)
+		return translateInstruction(d.SourceLang, d.TargetLang, d.Text)
+	}
+	return b.String()
 }
 
 func (t *Translator) cacheKey(p TranslateProfile, req types.TranslateRequest) string {
-	return cache.GenerateKey(p.Name, p.Model, req.SourceLang, req.TargetLang, req.Text)
+	req = p.request(req)
+	text := req.Text
+	if req.Context != "" {
+		// Context changes the output, so it must be part of the key.
+		text = "context: " + req.Context + "\ntext: " + req.Text
+	}
+	if formatInstruction(req.PreserveFormat) != "" {
+		text = "format: " + req.PreserveFormat + "\n" + text
+	}
+	if p.Emoji {
+		text = "emoji: preserve\n" + text
+	}
+	if p.Units {
+		text = "units: localize\n" + text
+	}
+	if p.Instruction != "" {
+		text = "instruction: " + p.Instruction + "\n" + text
+	}
+	if style := styleInstruction(p.Formality, p.Tone); style != "" {
+		text = "style: " + style + "\n" + text
+	}
+	if len(req.Glossary) > 0 {
+		// Glossary terms change the output, so they must be part of the key.
+		text = glossaryKey(req.Glossary) + text
+	}
+	if len(p.Examples) > 0 {
+		// Examples steer the output, so they must be part of the key.
+		var b strings.Builder
+		for _, ex := range p.Examples {
+			fmt.Fprintf(&b, "example: %q => %q\n", ex.Source, ex.Target)
+		}
+		text = b.String() + text
+	}
+	if chain := t.post.Load(); chain != nil && chain.id != "" {
+		// Cached text is post-processed, so the chain is part of the key.
+		text = "post: " + chain.id + "\n" + text
+	}
+	return cache.GenerateKey(p.Name, p.Model, req.SourceLang, req.TargetLang, text)
 }
 
 func (t *Translator) getCached(key string) (types.TranslateResult, bool) {
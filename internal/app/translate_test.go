@@ -2,8 +2,12 @@ package app
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	"go.aimuz.me/transy/cache"
 	"go.aimuz.me/transy/internal/types"
 	"go.aimuz.me/transy/llm"
 )
@@ -65,11 +69,24 @@ func TestBuildTranslateMessages(t *testing.T) {
 			wantSystem:   "",
 			wantContains: "from auto to en",
 		},
+		{
+			name:         "translation with glossary",
+			systemPrompt: "Translate.",
+			req: types.TranslateRequest{
+				Text:       "Open Transy settings",
+				SourceLang: "en",
+				TargetLang: "zh",
+				Glossary:   map[string]string{"Transy": "Transy"},
+			},
+			wantMsgCount: 2,
+			wantSystem:   "Translate.",
+			wantContains: "Do not translate these terms",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			msgs := buildTranslateMessages(tt.systemPrompt, tt.req)
+			msgs := buildTranslateMessages(tt.systemPrompt, nil, "", nil, tt.req)
 
 			if len(msgs) != tt.wantMsgCount {
 				t.Errorf("got %d messages, want %d", len(msgs), tt.wantMsgCount)
@@ -160,3 +177,515 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestTranslateProfileContext(t *testing.T) {
+	req := types.TranslateRequest{Text: "world", SourceLang: "en", TargetLang: "zh", Context: "Hello,"}
+	tr := NewTranslator(nil)
+
+	with := TranslateProfile{Name: "p", Model: "m", UseContext: true}
+	without := TranslateProfile{Name: "p", Model: "m", UseContext: false}
+
+	if msgs := with.messages(req); !contains(msgs[1].Content, "Context (previous sentences)") {
+		t.Errorf("context missing with UseContext: %q", msgs[1].Content)
+	}
+	if msgs := without.messages(req); contains(msgs[1].Content, "Context") {
+		t.Errorf("context present without UseContext: %q", msgs[1].Content)
+	}
+
+	noCtx := req
+	noCtx.Context = ""
+	if tr.cacheKey(with, req) == tr.cacheKey(with, noCtx) {
+		t.Error("cache key should differ when context is used")
+	}
+	if tr.cacheKey(without, req) != tr.cacheKey(without, noCtx) {
+		t.Error("cache key should ignore context when it is not used")
+	}
+}
+
+func TestTranslateProfileExamples(t *testing.T) {
+	examples := []types.TranslationExample{
+		{Source: "pull request", Target: "拉取请求"},
+		{Source: "merge", Target: "合并"},
+	}
+	p := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m", Examples: examples})
+	req := types.TranslateRequest{Text: "rebase", SourceLang: "en", TargetLang: "zh"}
+
+	msgs := p.messages(req)
+	wantRoles := []string{"system", "user", "assistant", "user", "assistant", "user"}
+	if len(msgs) != len(wantRoles) {
+		t.Fatalf("got %d messages, want %d", len(msgs), len(wantRoles))
+	}
+	for i, role := range wantRoles {
+		if msgs[i].Role != role {
+			t.Errorf("msgs[%d].Role = %q, want %q", i, msgs[i].Role, role)
+		}
+	}
+	if !contains(msgs[1].Content, "pull request") || msgs[2].Content != "拉取请求" {
+		t.Errorf("first example = %q / %q", msgs[1].Content, msgs[2].Content)
+	}
+	if !contains(msgs[5].Content, "rebase") {
+		t.Errorf("request should be last, got %q", msgs[5].Content)
+	}
+
+	tr := NewTranslator(nil)
+	plain := TranslateProfile{Name: "p", Model: "m"}
+	if tr.cacheKey(p, req) == tr.cacheKey(plain, req) {
+		t.Error("cache key should differ when examples are used")
+	}
+	other := p
+	other.Examples = examples[:1]
+	if tr.cacheKey(p, req) == tr.cacheKey(other, req) {
+		t.Error("cache key should differ when examples change")
+	}
+}
+
+func TestCapExamples(t *testing.T) {
+	many := make([]types.TranslationExample, maxExamples+3)
+	for i := range many {
+		many[i] = types.TranslationExample{Source: "a", Target: "b"}
+	}
+	if got := len(capExamples(many)); got != maxExamples {
+		t.Errorf("capExamples(many) kept %d, want %d", got, maxExamples)
+	}
+
+	long := strings.Repeat("x", maxExampleRunes)
+	got := capExamples([]types.TranslationExample{
+		{Source: "", Target: "skipped"},
+		{Source: "short", Target: "ok"},
+		{Source: long, Target: "too long"},
+		{Source: "after", Target: "dropped"},
+	})
+	if len(got) != 1 || got[0].Source != "short" {
+		t.Errorf("capExamples() = %+v, want only the short example", got)
+	}
+}
+
+func TestTranslateProfileOf(t *testing.T) {
+	off := false
+	tests := []struct {
+		name       string
+		useContext *bool
+		want       bool
+	}{
+		{"default enabled", nil, true},
+		{"explicitly disabled", &off, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := translateProfileOf(&types.TranslationProfile{Name: "p", UseContext: tt.useContext})
+			if p.UseContext != tt.want {
+				t.Errorf("UseContext = %v, want %v", p.UseContext, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxTokensFor(t *testing.T) {
+	short := maxTokensFor(0, "gpt-4o", "Hello")
+	long := maxTokensFor(0, "gpt-4o", strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100))
+	huge := maxTokensFor(0, "gpt-4o", strings.Repeat("word ", 100000))
+
+	if short < minAutoMaxTokens {
+		t.Errorf("short input budget = %d, want at least %d", short, minAutoMaxTokens)
+	}
+	if long <= short {
+		t.Errorf("long input budget %d should exceed short input budget %d", long, short)
+	}
+	if huge != maxAutoMaxTokens {
+		t.Errorf("huge input budget = %d, want ceiling %d", huge, maxAutoMaxTokens)
+	}
+	if got := maxTokensFor(500, "gpt-4o", strings.Repeat("word ", 100000)); got != 500 {
+		t.Errorf("explicit MaxTokens = %d, want 500", got)
+	}
+}
+
+func TestLengthOK(t *testing.T) {
+	bounded := TranslateProfile{Model: "gpt-4", MinLengthRatio: 0.3, MaxLengthRatio: 3}
+	english := "The quick brown fox jumps over the lazy dog near the river bank."
+	chinese := "敏捷的棕色狐狸跳过了河岸边那只懒惰的狗。"
+
+	tests := []struct {
+		name    string
+		profile TranslateProfile
+		source  string
+		output  string
+		want    bool
+	}{
+		{"disabled", TranslateProfile{}, english, "", true},
+		{"normal latin", bounded, english, "Der schnelle braune Fuchs springt über den faulen Hund am Flussufer.", true},
+		{"latin to cjk", bounded, english, chinese, true},
+		{"cjk to latin", bounded, chinese, english, true},
+		{"truncated", bounded, english, "Der", false},
+		{"explanation appended", bounded, english, strings.Repeat("Here is the translation with a long explanation. ", 10), false},
+		{"short source skipped", bounded, "Hi", strings.Repeat("Hallo ", 50), true},
+		{"min only", TranslateProfile{MinLengthRatio: 0.5}, english, strings.Repeat("long ", 100), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.lengthOK(tt.source, tt.output); got != tt.want {
+				t.Errorf("lengthOK() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// seqCompleter returns its responses in order, repeating the last one.
+// Each call reports seqUsage.
+type seqCompleter struct {
+	responses []string
+	calls     int
+}
+
+func (m *seqCompleter) Complete(_ context.Context, _ []llm.Message) (string, types.Usage, error) {
+	r := m.responses[min(m.calls, len(m.responses)-1)]
+	m.calls++
+	return r, seqUsage, nil
+}
+
+var seqUsage = types.Usage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30}
+
+func TestTranslatorLengthRetry(t *testing.T) {
+	profile := TranslateProfile{Name: "test", Model: "gpt-4", MinLengthRatio: 0.3, MaxLengthRatio: 3}
+	req := types.TranslateRequest{
+		Text:       "The quick brown fox jumps over the lazy dog near the river bank.",
+		SourceLang: "en",
+		TargetLang: "de",
+	}
+	good := "Der schnelle braune Fuchs springt über den faulen Hund am Flussufer."
+
+	tests := []struct {
+		name      string
+		responses []string
+		want      string
+		wantCalls int
+		wantErr   error
+	}{
+		{"accepted", []string{good}, good, 1, nil},
+		{"retried", []string{"Der", good}, good, 2, nil},
+		{"rejected", []string{"Der"}, "", 2, ErrSuspiciousLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &seqCompleter{responses: tt.responses}
+			result, err := NewTranslator(nil).Translate(context.Background(), c, profile, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Translate() error = %v, want %v", err, tt.wantErr)
+			}
+			if result.Text != tt.want {
+				t.Errorf("Translate() = %q, want %q", result.Text, tt.want)
+			}
+			if c.calls != tt.wantCalls {
+				t.Errorf("completer calls = %d, want %d", c.calls, tt.wantCalls)
+			}
+			// Every call is billed, so a retry adds to the usage.
+			if want := (types.Usage{
+				PromptTokens:     seqUsage.PromptTokens * tt.wantCalls,
+				CompletionTokens: seqUsage.CompletionTokens * tt.wantCalls,
+				TotalTokens:      seqUsage.TotalTokens * tt.wantCalls,
+			}); tt.wantErr == nil && result.Usage != want {
+				t.Errorf("Translate() usage = %+v, want %+v", result.Usage, want)
+			}
+		})
+	}
+}
+
+func TestTranslateProfileLocalizeUnits(t *testing.T) {
+	req := types.TranslateRequest{Text: "It is 5 miles away.", SourceLang: "en", TargetLang: "de"}
+	plain := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m", SystemPrompt: "Translate."})
+	units := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m", SystemPrompt: "Translate.", LocalizeUnits: true})
+
+	if got := plain.messages(req)[0].Content; got != "Translate." {
+		t.Errorf("system prompt without units = %q", got)
+	}
+	got := units.messages(req)[0].Content
+	if !strings.HasPrefix(got, "Translate.") || !contains(got, localizeUnits) {
+		t.Errorf("system prompt with units = %q, want it to end with the units instruction", got)
+	}
+
+	tr := NewTranslator(nil)
+	if tr.cacheKey(plain, req) == tr.cacheKey(units, req) {
+		t.Error("cache key should differ when units are localized")
+	}
+}
+
+func TestTranslatorEstimateUsage(t *testing.T) {
+	reported := types.Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}
+	req := types.TranslateRequest{Text: "Hello, how are you today?", SourceLang: "en", TargetLang: "zh"}
+	profile := TranslateProfile{Name: "p", Model: "gpt-4o-mini"}
+
+	tests := []struct {
+		name          string
+		estimate      bool
+		usage         types.Usage
+		wantEstimated bool
+		wantZero      bool
+	}{
+		{"reported usage kept", true, reported, false, false},
+		{"missing usage estimated", true, types.Usage{}, true, false},
+		{"missing usage without option", false, types.Usage{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTranslator(nil)
+			tr.SetEstimateUsage(tt.estimate)
+
+			res, err := tr.Translate(context.Background(), &mockCompleter{response: "你好，你今天怎么样？", usage: tt.usage}, profile, req)
+			if err != nil {
+				t.Fatalf("Translate() error = %v", err)
+			}
+			u := res.Usage
+			if u.Estimated != tt.wantEstimated {
+				t.Errorf("Estimated = %v, want %v", u.Estimated, tt.wantEstimated)
+			}
+			if (u.TotalTokens == 0) != tt.wantZero {
+				t.Errorf("TotalTokens = %d, want zero %v", u.TotalTokens, tt.wantZero)
+			}
+			if tt.wantEstimated && (u.PromptTokens == 0 || u.CompletionTokens == 0 || u.TotalTokens != u.PromptTokens+u.CompletionTokens) {
+				t.Errorf("estimated usage = %+v, want non-zero parts that add up", u)
+			}
+			if !tt.wantEstimated && !tt.wantZero && u != reported {
+				t.Errorf("usage = %+v, want reported %+v", u, reported)
+			}
+		})
+	}
+}
+
+// mockStreamer implements llm.StreamCompleter, replaying deltas.
+type mockStreamer struct {
+	deltas []llm.StreamDelta
+	calls  int
+}
+
+func (m *mockStreamer) StreamComplete(_ context.Context, _ []llm.Message) (<-chan llm.StreamDelta, error) {
+	m.calls++
+	ch := make(chan llm.StreamDelta, len(m.deltas))
+	for _, d := range m.deltas {
+		ch <- d
+	}
+	close(ch)
+	return ch, nil
+}
+
+// collect drains a result stream.
+func collect(t *testing.T, ch <-chan types.TranslateResult) []types.TranslateResult {
+	t.Helper()
+	var out []types.TranslateResult
+	for r := range ch {
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestTranslatorTranslateStream(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+
+	tr := NewTranslator(c)
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	req := types.TranslateRequest{Text: "Hello world", SourceLang: "en", TargetLang: "zh"}
+	usage := types.Usage{PromptTokens: 10, CompletionTokens: 4, TotalTokens: 14}
+	streamer := &mockStreamer{deltas: []llm.StreamDelta{
+		{Text: "你好"},
+		{Text: "，世界"},
+		{Done: true, Usage: usage},
+	}}
+
+	ch, err := tr.TranslateStream(context.Background(), streamer, profile, req)
+	if err != nil {
+		t.Fatalf("TranslateStream() error = %v", err)
+	}
+	got := collect(t, ch)
+	want := []types.TranslateResult{
+		{Text: "你好"},
+		{Text: "你好，世界"},
+		{Text: "你好，世界", Usage: usage, Done: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("results = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("result %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The completed stream was cached.
+	ch, err = tr.TranslateStream(context.Background(), streamer, profile, req)
+	if err != nil {
+		t.Fatalf("TranslateStream() cached error = %v", err)
+	}
+	got = collect(t, ch)
+	if len(got) != 1 || !got[0].Done || !got[0].Usage.CacheHit || got[0].Text != "你好，世界" {
+		t.Errorf("cached results = %+v, want one final cache hit", got)
+	}
+	if streamer.calls != 1 {
+		t.Errorf("stream calls = %d, want 1", streamer.calls)
+	}
+}
+
+func TestTranslatorTranslateStreamInterrupted(t *testing.T) {
+	c, err := cache.New(t.TempDir(), cache.Options{})
+	if err != nil {
+		t.Fatalf("cache.New() error = %v", err)
+	}
+	defer c.Close()
+
+	tr := NewTranslator(c)
+	profile := TranslateProfile{Name: "test", Model: "gpt-4"}
+	req := types.TranslateRequest{Text: "Hello world", SourceLang: "en", TargetLang: "zh"}
+	streamer := &mockStreamer{deltas: []llm.StreamDelta{
+		{Text: "你好"},
+		{Done: true, Err: llm.ErrStreamTruncated},
+	}}
+
+	ch, err := tr.TranslateStream(context.Background(), streamer, profile, req)
+	if err != nil {
+		t.Fatalf("TranslateStream() error = %v", err)
+	}
+	got := collect(t, ch)
+	last := got[len(got)-1]
+	if !last.Done || last.Error == "" || last.Text != "你好" {
+		t.Errorf("final result = %+v, want partial text with error", last)
+	}
+	if _, ok := tr.getCached(tr.cacheKey(profile, req)); ok {
+		t.Error("interrupted stream was cached")
+	}
+
+	req.PreserveFormat = FormatMarkdown
+	if _, err := tr.TranslateStream(context.Background(), streamer, profile, req); !errors.Is(err, ErrStreamNeedsRestore) {
+		t.Errorf("TranslateStream() markdown error = %v, want ErrStreamNeedsRestore", err)
+	}
+}
+
+func TestTranslateProfileInstruction(t *testing.T) {
+	const tmpl = "{{.Glossary}}\nTranslate this {{.SourceLang}} text to casual {{.TargetLang}}{{if .Context}} (after: {{.Context}}){{end}}:\n{{.Text}}"
+	p := translateProfileOf(&types.TranslationProfile{
+		Name:                "p",
+		Model:               "m",
+		InstructionTemplate: tmpl,
+		Examples:            []types.TranslationExample{{Source: "merge", Target: "合并"}},
+	})
+	req := types.TranslateRequest{
+		Text:       "rebase",
+		SourceLang: "en",
+		TargetLang: "zh",
+		Context:    "pull first",
+		Glossary:   map[string]string{"Transy": "Transy"},
+	}
+
+	msgs := p.messages(req)
+	if len(msgs) != 4 {
+		t.Fatalf("got %d messages, want 4", len(msgs))
+	}
+	if got, want := msgs[1].Content, "\nTranslate this en text to casual zh:\nmerge"; got != want {
+		t.Errorf("example turn = %q, want %q", got, want)
+	}
+	got := msgs[3].Content
+	if !strings.HasPrefix(got, "Do not translate these terms") {
+		t.Errorf("glossary should be placed by the template, got %q", got)
+	}
+	if !strings.HasSuffix(got, "Translate this en text to casual zh (after: pull first):\nrebase") {
+		t.Errorf("request turn = %q", got)
+	}
+	if contains(got, "please translate") || contains(got, "Context (previous sentences)") {
+		t.Errorf("built-in instruction should be replaced, got %q", got)
+	}
+
+	// A template that no longer parses falls back to the built-in one.
+	broken := translateProfileOf(&types.TranslationProfile{
+		Name:                "p",
+		Model:               "m",
+		InstructionTemplate: "{{.Text",
+		Examples:            []types.TranslationExample{{Source: "merge", Target: "合并"}},
+	})
+	if got := broken.messages(req)[3].Content; !contains(got, "please translate the following text from en to zh") {
+		t.Errorf("broken template should fall back, got %q", got)
+	}
+
+	tr := NewTranslator(nil)
+	plain := p
+	plain.Instruction = ""
+	if tr.cacheKey(p, req) == tr.cacheKey(plain, req) {
+		t.Error("cache key should differ when an instruction template is used")
+	}
+}
+
+func TestTranslateProfileStyle(t *testing.T) {
+	req := types.TranslateRequest{Text: "Please sign in.", SourceLang: "en", TargetLang: "ja"}
+	tests := []struct {
+		name      string
+		formality string
+		tone      string
+		want      []string
+		wantNot   []string
+	}{
+		{"default", "", "", nil, []string{"register", "tone"}},
+		{"explicit default", types.FormalityDefault, "", nil, []string{"register"}},
+		{"formal", types.FormalityFormal, "", []string{formalInstruction}, []string{informalInstruction}},
+		{"informal friendly", types.FormalityInformal, types.ToneFriendly, []string{informalInstruction, toneInstructions[types.ToneFriendly]}, nil},
+		{"technical only", "", types.ToneTechnical, []string{toneInstructions[types.ToneTechnical]}, []string{"register"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m", Formality: tt.formality, Tone: tt.tone})
+			got := p.messages(req)[1].Content
+			for _, w := range tt.want {
+				if !contains(got, w) {
+					t.Errorf("user message missing %q, got %q", w, got)
+				}
+			}
+			for _, w := range tt.wantNot {
+				if contains(got, w) {
+					t.Errorf("user message should not contain %q, got %q", w, got)
+				}
+			}
+			if !strings.HasSuffix(got, "Please sign in.") {
+				t.Errorf("request text should stay last, got %q", got)
+			}
+		})
+	}
+
+	tr := NewTranslator(nil)
+	plain := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m"})
+	formal := translateProfileOf(&types.TranslationProfile{Name: "p", Model: "m", Formality: types.FormalityFormal})
+	if tr.cacheKey(plain, req) == tr.cacheKey(formal, req) {
+		t.Error("cache key should differ when formality is set")
+	}
+}
+
+// recordCompleter records the messages it is sent.
+type recordCompleter struct {
+	msgs []llm.Message
+}
+
+func (m *recordCompleter) Complete(_ context.Context, msgs []llm.Message) (string, types.Usage, error) {
+	m.msgs = msgs
+	return "Gut 🎉", types.Usage{}, nil
+}
+
+func TestTranslatorPreviewMatchesTranslate(t *testing.T) {
+	profile := TranslateProfile{Name: "p", Model: "gpt-4o", SystemPrompt: "Translate.", Emoji: true}
+	req := types.TranslateRequest{Text: "Great 🎉", SourceLang: "en", TargetLang: "de", Context: "Earlier."}
+	tr := NewTranslator(nil)
+
+	c := &recordCompleter{}
+	if _, err := tr.Translate(context.Background(), c, profile, req); err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	preview := tr.Preview(profile, req)
+	if !reflect.DeepEqual(preview.Messages, c.msgs) {
+		t.Errorf("Preview() messages = %+v, want those sent %+v", preview.Messages, c.msgs)
+	}
+	for _, msg := range preview.Messages {
+		if strings.Contains(msg.Content, "🎉") || strings.Contains(msg.Content, "Earlier.") {
+			t.Errorf("Preview() message %q has a protected emoji or disabled context", msg.Content)
+		}
+	}
+}
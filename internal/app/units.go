@@ -0,0 +1,55 @@
+package app
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// metricUnit converts one imperial unit to its metric equivalent.
+type metricUnit struct {
+	re      *regexp.Regexp
+	convert func(float64) float64
+	unit    string // Metric unit written after the converted value
+}
+
+// quantity matches a number such as "3", "-4.5" or "1,200".
+const quantity = `(-?\d{1,3}(?:,\d{3})+(?:\.\d+)?|-?\d+(?:\.\d+)?)`
+
+// metricUnits are the conversions applied by MetricUnits. Ambiguous words
+// such as "in" and "oz" are left alone.
+var metricUnits = []metricUnit{
+	{regexp.MustCompile(quantity + `\s?(?:°\s?F|℉)`), func(f float64) float64 { return (f - 32) * 5 / 9 }, "°C"},
+	{regexp.MustCompile(quantity + `\s?(?:mph|miles per hour)\b`), func(v float64) float64 { return v * 1.609344 }, "km/h"},
+	{regexp.MustCompile(quantity + `\s?(?:miles?|mi)\b`), func(v float64) float64 { return v * 1.609344 }, "km"},
+	{regexp.MustCompile(quantity + `\s?(?:feet|foot|ft)\b`), func(v float64) float64 { return v * 0.3048 }, "m"},
+	{regexp.MustCompile(quantity + `\s?(?:inches|inch)\b`), func(v float64) float64 { return v * 2.54 }, "cm"},
+	{regexp.MustCompile(quantity + `\s?(?:pounds?|lbs?)\b`), func(v float64) float64 { return v * 0.45359237 }, "kg"},
+	{regexp.MustCompile(quantity + `\s?(?:gallons?|gal)\b`), func(v float64) float64 { return v * 3.785411784 }, "L"},
+}
+
+// MetricUnits rewrites common imperial quantities in the translation, such
+// as "5 miles" or "72°F", as metric values rounded to one decimal place.
+func MetricUnits(_, translated string) string {
+	for _, u := range metricUnits {
+		translated = u.re.ReplaceAllStringFunc(translated, func(m string) string {
+			num := u.re.FindStringSubmatch(m)[1]
+			v, err := strconv.ParseFloat(strings.ReplaceAll(num, ",", ""), 64)
+			if err != nil {
+				return m
+			}
+			return formatQuantity(u.convert(v)) + " " + u.unit
+		})
+	}
+	return translated
+}
+
+// formatQuantity rounds v to one decimal place, dropping a trailing ".0".
+func formatQuantity(v float64) string {
+	v = math.Round(v*10) / 10
+	if v == 0 {
+		v = 0 // Avoid "-0"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,29 @@
+package app
+
+import "testing"
+
+func TestMetricUnits(t *testing.T) {
+	tests := []struct {
+		name       string
+		translated string
+		want       string
+	}{
+		{"miles", "Es sind 5 Meilen, also 5 miles.", "Es sind 5 Meilen, also 8 km."},
+		{"fahrenheit", "今天 72°F", "今天 22.2 °C"},
+		{"below zero", "-40 °F outside", "-40 °C outside"},
+		{"speed before distance", "65 mph", "104.6 km/h"},
+		{"thousands separator", "1,200 ft", "365.8 m"},
+		{"pounds", "10 lbs and 1 pound", "4.5 kg and 0.5 kg"},
+		{"ambiguous in left alone", "3 in the box", "3 in the box"},
+		{"word prefix not matched", "5 minutes", "5 minutes"},
+		{"no quantities", "no units here", "no units here"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MetricUnits("", tt.translated); got != tt.want {
+				t.Errorf("MetricUnits(%q) = %q, want %q", tt.translated, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.aimuz.me/transy/internal/jsonl"
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/llm"
+)
+
+// UsageRecord is the token usage of one translation sent to a model.
+// Cache hits are not recorded.
+type UsageRecord struct {
+	Time             time.Time `json:"time"`
+	Profile          string    `json:"profile"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"promptTokens"`
+	CompletionTokens int       `json:"completionTokens"`
+	TotalTokens      int       `json:"totalTokens"`
+	Estimated        bool      `json:"estimated,omitempty"` // Token counts estimated locally
+}
+
+// usageLog persists usage records as one JSON object per line.
+type usageLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Record appends r to the log, creating it if needed.
+func (l *usageLog) Record(r UsageRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := jsonl.Append(l.path, r); err != nil {
+		return fmt.Errorf("write usage log: %w", err)
+	}
+	return nil
+}
+
+// Load returns the records with from <= Time < to, in logged order.
+// A zero bound is open. A missing log holds no records.
+func (l *usageLog) Load(from, to time.Time) ([]UsageRecord, error) {
+	l.mu.Lock()
+	all, err := jsonl.Load[UsageRecord](l.path, false)
+	l.mu.Unlock()
+	if err != nil {
+		err = fmt.Errorf("read usage log: %w", err)
+	}
+
+	records := slices.DeleteFunc(all, func(r UsageRecord) bool {
+		return (!from.IsZero() && r.Time.Before(from)) || (!to.IsZero() && !r.Time.Before(to))
+	})
+	return records, err
+}
+
+// modelPrice is a model's list price in USD per million tokens.
+type modelPrice struct {
+	prefix             string
+	prompt, completion float64
+}
+
+// modelPrices are matched by the longest prefix of the model name, so dated
+// snapshots such as "gpt-4o-mini-2024-07-18" resolve. Prices change; the
+// cost column is only an estimate.
+var modelPrices = []modelPrice{
+	{"gpt-4.1-nano", 0.10, 0.40},
+	{"gpt-4.1-mini", 0.40, 1.60},
+	{"gpt-4.1", 2.00, 8.00},
+	{"gpt-4o-mini", 0.15, 0.60},
+	{"gpt-4o", 2.50, 10.00},
+	{"gemini-2.0-flash", 0.10, 0.40},
+	{"claude-3-5-haiku", 0.80, 4.00},
+}
+
+// priceFor returns the price of model, or false if it is unknown. Custom
+// prices take precedence over the built-in list; both match by the
+// longest prefix.
+func priceFor(model string, custom map[string]types.ModelPrice) (types.ModelPrice, bool) {
+	best := ""
+	for prefix := range custom {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best != "" {
+		return custom[best], true
+	}
+
+	var list *modelPrice
+	for i, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) && (list == nil || len(p.prefix) > len(list.prefix)) {
+			list = &modelPrices[i]
+		}
+	}
+	if list == nil {
+		return types.ModelPrice{}, false
+	}
+	return types.ModelPrice{Prompt: list.prompt, Completion: list.completion}, true
+}
+
+// estimateCost returns the estimated cost of r in USD, or false if the
+// model's price is unknown.
+func estimateCost(r UsageRecord, custom map[string]types.ModelPrice) (float64, bool) {
+	p, ok := priceFor(r.Model, custom)
+	if !ok {
+		return 0, false
+	}
+	return (float64(r.PromptTokens)*p.Prompt + float64(r.CompletionTokens)*p.Completion) / 1e6, true
+}
+
+// CostEstimate is the approximate size and cost of a translation before it
+// is sent.
+type CostEstimate struct {
+	Model            string  `json:"model"`
+	PromptTokens     int     `json:"promptTokens"`
+	CompletionTokens int     `json:"completionTokens"` // Assumes the translation is about as long as the source
+	Cost             float64 `json:"cost"`             // USD; zero if PriceKnown is false
+	PriceKnown       bool    `json:"priceKnown"`
+}
+
+// estimateTranslation estimates the tokens and cost of translating req
+// with profile. Models without a known tokenizer ratio are counted at four
+// characters per token, and CJK characters at one token each.
+func estimateTranslation(profile TranslateProfile, req types.TranslateRequest, custom map[string]types.ModelPrice) CostEstimate {
+	e := CostEstimate{
+		Model:            profile.Model,
+		PromptTokens:     llm.EstimateMessagesTokens(profile.Model, profile.messages(req)),
+		CompletionTokens: llm.EstimateTokens(profile.Model, req.Text),
+	}
+	e.Cost, e.PriceKnown = estimateCost(UsageRecord{
+		Model:            e.Model,
+		PromptTokens:     e.PromptTokens,
+		CompletionTokens: e.CompletionTokens,
+	}, custom)
+	return e
+}
+
+// usageCSVHeader is the header row of the usage export.
+var usageCSVHeader = []string{
+	"timestamp", "profile", "model",
+	"prompt_tokens", "completion_tokens", "total_tokens", "estimated_cost_usd", "tokens_estimated",
+}
+
+// usageCSV renders records as CSV with a header row, pricing them with
+// custom over the built-in list. Times are RFC 3339 in UTC; the cost is
+// empty for models without a known price.
+func usageCSV(records []UsageRecord, custom map[string]types.ModelPrice) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(usageCSVHeader); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	for _, r := range records {
+		cost := ""
+		if c, ok := estimateCost(r, custom); ok {
+			cost = strconv.FormatFloat(c, 'f', 6, 64)
+		}
+		row := []string{
+			r.Time.UTC().Format(time.RFC3339),
+			r.Profile,
+			r.Model,
+			strconv.Itoa(r.PromptTokens),
+			strconv.Itoa(r.CompletionTokens),
+			strconv.Itoa(r.TotalTokens),
+			cost,
+			strconv.FormatBool(r.Estimated),
+		}
+		if err := w.Write(row); err != nil {
+			return nil, fmt.Errorf("write csv: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestUsageCSV(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	records := []UsageRecord{
+		{Time: at, Profile: "Work, \"formal\"", Model: "gpt-4o-mini-2024-07-18", PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		{Time: at, Profile: "Local", Model: "qwen2.5", PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, Estimated: true},
+	}
+
+	got, err := usageCSV(records, nil)
+	if err != nil {
+		t.Fatalf("usageCSV() error = %v", err)
+	}
+	want := "timestamp,profile,model,prompt_tokens,completion_tokens,total_tokens,estimated_cost_usd,tokens_estimated\n" +
+		"2026-03-01T08:30:00Z,\"Work, \"\"formal\"\"\",gpt-4o-mini-2024-07-18,1000,500,1500,0.000450,false\n" +
+		"2026-03-01T08:30:00Z,Local,qwen2.5,10,5,15,,true\n"
+	if string(got) != want {
+		t.Errorf("usageCSV() =\n%s\nwant\n%s", got, want)
+	}
+
+	empty, err := usageCSV(nil, nil)
+	if err != nil {
+		t.Fatalf("usageCSV(nil, nil) error = %v", err)
+	}
+	if string(empty) != "timestamp,profile,model,prompt_tokens,completion_tokens,total_tokens,estimated_cost_usd,tokens_estimated\n" {
+		t.Errorf("usageCSV(nil, nil) = %q, want header only", empty)
+	}
+}
+
+func TestEstimateCostLongestPrefix(t *testing.T) {
+	// gpt-4o-mini must not be priced as gpt-4o.
+	cost, ok := estimateCost(UsageRecord{Model: "gpt-4o-mini", PromptTokens: 1_000_000}, nil)
+	if !ok || cost != 0.15 {
+		t.Errorf("estimateCost(gpt-4o-mini) = %v, %v, want 0.15, true", cost, ok)
+	}
+}
+
+func TestPriceForCustom(t *testing.T) {
+	custom := map[string]types.ModelPrice{
+		"gpt-4o":  {Prompt: 1, Completion: 2},
+		"my-llm-": {Prompt: 0.5, Completion: 0.5},
+	}
+	tests := []struct {
+		model  string
+		want   types.ModelPrice
+		wantOK bool
+	}{
+		{"gpt-4o-2024-08-06", types.ModelPrice{Prompt: 1, Completion: 2}, true},
+		{"gpt-4o-mini", types.ModelPrice{Prompt: 1, Completion: 2}, true}, // Custom prefix wins over the longer built-in one
+		{"my-llm-7b", types.ModelPrice{Prompt: 0.5, Completion: 0.5}, true},
+		{"gpt-4.1-nano", types.ModelPrice{Prompt: 0.10, Completion: 0.40}, true},
+		{"qwen2.5", types.ModelPrice{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := priceFor(tt.model, custom)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("priceFor(%q) = %v, %v, want %v, %v", tt.model, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestEstimateTranslation(t *testing.T) {
+	profile := TranslateProfile{Model: "gpt-4o-mini", SystemPrompt: "Translate."}
+	req := types.TranslateRequest{Text: strings.Repeat("word ", 400), SourceLang: "en", TargetLang: "de"}
+
+	e := estimateTranslation(profile, req, nil)
+	if e.CompletionTokens != 500 {
+		t.Errorf("CompletionTokens = %d, want 500 (2000 chars / 4)", e.CompletionTokens)
+	}
+	if e.PromptTokens <= e.CompletionTokens {
+		t.Errorf("PromptTokens = %d, want the source plus instructions (> %d)", e.PromptTokens, e.CompletionTokens)
+	}
+	want := (float64(e.PromptTokens)*0.15 + float64(e.CompletionTokens)*0.60) / 1e6
+	if !e.PriceKnown || math.Abs(e.Cost-want) > 1e-12 {
+		t.Errorf("Cost = %v (known %v), want %v", e.Cost, e.PriceKnown, want)
+	}
+
+	profile.Model = "local-model"
+	if e := estimateTranslation(profile, req, nil); e.PriceKnown || e.Cost != 0 || e.CompletionTokens != 500 {
+		t.Errorf("unknown model estimate = %+v, want tokens at chars/4 and no price", e)
+	}
+	free := map[string]types.ModelPrice{"local-": {}}
+	if e := estimateTranslation(profile, req, free); !e.PriceKnown || e.Cost != 0 {
+		t.Errorf("custom free model estimate = %+v, want known zero cost", e)
+	}
+}
+
+func TestUsageLogLoadRange(t *testing.T) {
+	log := &usageLog{path: filepath.Join(t.TempDir(), "usage.jsonl")}
+
+	if got, err := log.Load(time.Time{}, time.Time{}); err != nil || len(got) != 0 {
+		t.Fatalf("Load() on missing log = %v, %v, want empty", got, err)
+	}
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		if err := log.Record(UsageRecord{Time: day.AddDate(0, 0, i), Model: "m", TotalTokens: i}); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []int // TotalTokens of the records returned
+	}{
+		{"open range", time.Time{}, time.Time{}, []int{0, 1, 2}},
+		{"from inclusive", day.AddDate(0, 0, 1), time.Time{}, []int{1, 2}},
+		{"to exclusive", time.Time{}, day.AddDate(0, 0, 1), []int{0}},
+		{"single day", day.AddDate(0, 0, 1), day.AddDate(0, 0, 2), []int{1}},
+		{"empty range", day.AddDate(0, 0, 5), day.AddDate(0, 0, 6), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := log.Load(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Load() returned %d records, want %d", len(got), len(tt.want))
+			}
+			for i, r := range got {
+				if r.TotalTokens != tt.want[i] {
+					t.Errorf("record %d TotalTokens = %d, want %d", i, r.TotalTokens, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package jsonl reads and writes files holding one JSON value per line.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// maxLine bounds a single line; values can hold whole translations.
+const maxLine = 4 << 20
+
+// Append writes each value on its own line at the end of the file at
+// path, creating the file and its directory if needed.
+func Append[T any](path string, values ...T) error {
+	data, err := encode(values)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Replace writes values to the file at path in place of its contents. The
+// file is swapped in whole, so a crash leaves either the old or new file.
+func Replace[T any](path string, values []T) error {
+	data, err := encode(values)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Load decodes the file at path, in file order. A missing file holds no
+// values. With skipBad, lines that don't decode, such as one cut short by
+// a crash, are skipped; otherwise the values before the bad line are
+// returned with the error.
+func Load[T any](path string, skipBad bool) ([]T, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []T
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, maxLine)
+	for n := 1; sc.Scan(); n++ {
+		var v T
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+			if skipBad {
+				continue
+			}
+			return values, fmt.Errorf("line %d: %w", n, err)
+		}
+		values = append(values, v)
+	}
+	return values, sc.Err()
+}
+
+func encode[T any](values []T) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, v := range values {
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package jsonl
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+type item struct {
+	N int `json:"n"`
+}
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "items.jsonl")
+
+	if got, err := Load[item](path, false); err != nil || got != nil {
+		t.Fatalf("Load() on missing file = %v, %v, want nil, nil", got, err)
+	}
+	if err := Append(path, item{1}, item{2}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := Append(path, item{3}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	got, err := Load[item](path, false)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []item{{1}, {2}, {3}}; !slices.Equal(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadBadLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	if err := os.WriteFile(path, []byte("{\"n\":1}\n{\"n\":\n{\"n\":3}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load[item](path, false)
+	if err == nil || !slices.Equal(got, []item{{1}}) {
+		t.Errorf("Load(strict) = %v, %v, want [{1}] and an error", got, err)
+	}
+	got, err = Load[item](path, true)
+	if err != nil || !slices.Equal(got, []item{{1}, {3}}) {
+		t.Errorf("Load(skipBad) = %v, %v, want [{1} {3}]", got, err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "items.jsonl")
+	if err := Append(path, item{1}, item{2}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := Replace(path, []item{{9}}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	got, err := Load[item](path, false)
+	if err != nil || !slices.Equal(got, []item{{9}}) {
+		t.Errorf("Load() after Replace = %v, %v, want [{9}]", got, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 // Package types provides shared type definitions for the application.
 package types
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Provider represents an LLM provider configuration.
 // Deprecated: Use APICredential + TranslationProfile instead.
@@ -27,9 +30,26 @@ type Provider struct {
 type APICredential struct {
 	ID      string `json:"id"`                 // UUID for reference
 	Name    string `json:"name"`               // Display name, e.g., "My OpenAI"
-	Type    string `json:"type"`               // "openai", "openai-compatible", "gemini", "claude"
-	BaseURL string `json:"base_url,omitempty"` // Custom endpoint (required for openai-compatible)
+	Type    string `json:"type"`               // "openai", "openai-compatible", "azure-openai", "gemini", "claude"
+	BaseURL string `json:"base_url,omitempty"` // Custom endpoint (required for openai-compatible and azure-openai); see llm.ChatCompletionsURL
 	APIKey  string `json:"api_key"`
+
+	// APIVersion is the Azure OpenAI api-version, e.g. "2024-10-21".
+	// Required for "azure-openai", where the profile model names the
+	// deployment.
+	APIVersion string `json:"api_version,omitempty"`
+
+	// OmitStreamOptions disables stream_options for openai-compatible and
+	// azure-openai endpoints that reject it. Streamed usage is then zero.
+	OmitStreamOptions bool `json:"omit_stream_options,omitempty"`
+
+	// OpenAI organization and project for billing attribution, sent as the
+	// OpenAI-Organization and OpenAI-Project headers. Only valid for "openai".
+	OrgID     string `json:"org_id,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+
+	// AllowUnusualValues skips the check for a key that looks like a model name.
+	AllowUnusualValues bool `json:"allow_unusual_values,omitempty"`
 }
 
 // TranslationProfile represents a translation configuration bound to an API credential.
@@ -39,22 +59,163 @@ type TranslationProfile struct {
 	CredentialID    string  `json:"credential_id"` // Reference to APICredential.ID
 	Model           string  `json:"model"`         // Model to use
 	SystemPrompt    string  `json:"system_prompt,omitempty"`
-	MaxTokens       int     `json:"max_tokens,omitempty"`
+	MaxTokens       int     `json:"max_tokens,omitempty"` // 0 sizes the budget from the input length
 	Temperature     float64 `json:"temperature,omitempty"`
 	Active          bool    `json:"active"` // Currently active profile
 	DisableThinking bool    `json:"disable_thinking,omitempty"`
+	UseContext      *bool   `json:"use_context,omitempty"` // Include previous sentences as context; nil means true
+
+	// Translation/source length ratio bounds, measured in estimated tokens so
+	// CJK and Latin text compare fairly. Outputs outside the range are retried
+	// once, then rejected. Zero disables the corresponding bound.
+	MinLengthRatio float64 `json:"min_length_ratio,omitempty"`
+	MaxLengthRatio float64 `json:"max_length_ratio,omitempty"`
+
+	// Examples are sample translations sent as prior turns (few-shot) to
+	// keep terminology and style consistent. Only the first few are used.
+	Examples []TranslationExample `json:"examples,omitempty"`
+
+	// PreserveEmoji keeps emoji verbatim and in place; the model only sees
+	// placeholders for them.
+	PreserveEmoji bool `json:"preserve_emoji,omitempty"`
+
+	// LocalizeUnits asks the model to convert units and format numbers for
+	// the target locale. The metric_units post-processor converts common
+	// imperial units deterministically instead.
+	LocalizeUnits bool `json:"localize_units,omitempty"`
+
+	// Formality and Tone steer the register of translations. Empty means
+	// FormalityDefault and no tone instruction.
+	Formality string `json:"formality,omitempty"` // FormalityDefault, FormalityFormal or FormalityInformal
+	Tone      string `json:"tone,omitempty"`      // ToneNeutral, ToneFriendly or ToneTechnical
+
+	// GlossaryID references a config glossary whose terms are applied to
+	// every request translated with this profile.
+	GlossaryID string `json:"glossary_id,omitempty"`
+
+	// InstructionTemplate replaces the built-in "please translate the
+	// following text from X to Y" request with a text/template using
+	// {{.SourceLang}}, {{.TargetLang}}, {{.Text}}, {{.Context}} and
+	// {{.Glossary}}. Context and glossary are only sent where the template
+	// places them. Empty uses the built-in instruction.
+	InstructionTemplate string `json:"instruction_template,omitempty"`
+
+	// AllowUnusualValues skips the check for a model that looks like an API key.
+	AllowUnusualValues bool `json:"allow_unusual_values,omitempty"`
+}
+
+// TranslationExample is a sample source/target pair for few-shot prompting.
+type TranslationExample struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Formality levels of TranslationProfile.
+const (
+	FormalityDefault  = "default"  // Leave the register to the model
+	FormalityFormal   = "formal"   // Polite forms and formal address, e.g. Sie, です/ます
+	FormalityInformal = "informal" // Casual forms and familiar address, e.g. du, plain form
+)
+
+// Tones of TranslationProfile.
+const (
+	ToneNeutral   = "neutral"
+	ToneFriendly  = "friendly"
+	ToneTechnical = "technical"
+)
+
+// ContextEnabled reports whether translation context should be sent.
+func (p *TranslationProfile) ContextEnabled() bool {
+	return p.UseContext == nil || *p.UseContext
 }
 
 // SpeechConfig represents speech service configuration (STT, speech translation, etc).
 // Requires an OpenAI-compatible API credential.
 type SpeechConfig struct {
-	Enabled      bool   `json:"enabled"`       // Whether speech API is enabled
-	CredentialID string `json:"credential_id"` // Reference to APICredential.ID
-	Model        string `json:"model"`         // e.g., "whisper-1" or "gpt-4o-realtime-preview"
-	Mode         string `json:"mode"`          // "transcription" (default) or "realtime"
+	Enabled      bool   `json:"enabled"`            // Whether speech API is enabled
+	CredentialID string `json:"credential_id"`      // Reference to APICredential.ID
+	Model        string `json:"model"`              // e.g., "whisper-1" or "gpt-4o-realtime-preview"
+	Mode         string `json:"mode"`               // "transcription" (default) or "realtime"
+	Provider     string `json:"provider,omitempty"` // Preferred live provider name; empty selects the default
+
+	// Live translation degradation: after TranslateMaxFailures consecutive
+	// failures, captions fall back to source text for TranslateCooldown seconds.
+	// Zero selects the defaults.
+	TranslateMaxFailures int `json:"translate_max_failures,omitempty"`
+	TranslateCooldown    int `json:"translate_cooldown,omitempty"`
+
+	// IdleTimeout auto-stops a live session after this many seconds without speech.
+	// Zero disables auto-stop.
+	IdleTimeout int `json:"idle_timeout,omitempty"`
+
+	// NormalizeText restores sentence casing and terminal punctuation in
+	// transcripts that arrive lowercased or unpunctuated. CJK text is skipped.
+	NormalizeText bool `json:"normalize_text,omitempty"`
+
+	// LockDirection translates every segment with the session's source and
+	// target languages, ignoring the language detected per segment.
+	LockDirection bool `json:"lock_direction,omitempty"`
+
+	// Prompt lists domain terms and names to bias live transcription, e.g.
+	// "Kubernetes, gRPC, Ana Souza". At most MaxSpeechPromptRunes long.
+	Prompt string `json:"prompt,omitempty"`
+
+	// PromptContext adds the most recent transcripts to the live prompt so
+	// names and terms stay consistent across segments.
+	PromptContext bool `json:"prompt_context,omitempty"`
+
+	// Diarize transcribes with a diarization model so live transcripts
+	// carry speaker labels. It replaces Model for transcription.
+	Diarize bool `json:"diarize,omitempty"`
+
+	// MaxSessionSegments caps the finalized segments kept in memory; older
+	// ones are flushed to disk and still included in exports. Zero selects
+	// the default.
+	MaxSessionSegments int `json:"max_session_segments,omitempty"`
+
+	// MaxSpeakingSeconds resets the voice activity indicator to listening
+	// when it has shown speaking this long without any update, e.g. after a
+	// dropped speech-stopped event. Zero selects the default.
+	MaxSpeakingSeconds int `json:"max_speaking_seconds,omitempty"`
+
+	// AudioSource selects what live translation listens to: "system"
+	// (default), "microphone", or "both" mixed together.
+	AudioSource string `json:"audio_source,omitempty"`
+
+	// AudioDeviceID is the microphone to record, an ID from
+	// GetAudioDevices. Empty, or a device no longer connected, uses the
+	// default input.
+	AudioDeviceID string `json:"audio_device_id,omitempty"`
+
+	// VAD tunes how speech is split into segments; nil keeps the defaults.
+	VAD *VADSettings `json:"vad,omitempty"`
+
+	// FinalizeAfterSilence marks the last pending segment final, and
+	// translates it, after this many seconds without updates or speech.
+	// Zero disables it.
+	FinalizeAfterSilence int `json:"finalize_after_silence,omitempty"`
+
+	// MaxCaptionChars shortens captions sent to the overlay to this many
+	// characters, keeping the newest words. Stored segments and exports keep
+	// the full text. Zero disables it.
+	MaxCaptionChars int `json:"max_caption_chars,omitempty"`
+}
+
+// MaxSpeechPromptRunes is the maximum length of a live transcription prompt.
+const MaxSpeechPromptRunes = 1000
+
+// LocalServerConfig configures the loopback HTTP translation server.
+type LocalServerConfig struct {
+	Enabled bool   `json:"enabled"`
+	Port    int    `json:"port"`
+	Token   string `json:"token"` // Required as "Authorization: Bearer <token>"
 }
 
-// DefaultMaxTokens is the default max tokens if not specified.
+// DefaultLocalServerPort is the default port for the local server.
+const DefaultLocalServerPort = 17890
+
+// DefaultMaxTokens is the max tokens legacy providers were given when
+// unset. Migration treats it as unset.
 const DefaultMaxTokens = 1000
 
 // DefaultTemperature is the default temperature if not specified.
@@ -66,6 +227,14 @@ type TranslateRequest struct {
 	SourceLang string `json:"sourceLang"`
 	TargetLang string `json:"targetLang"`
 	Context    string `json:"context,omitempty"` // Previous context for better coherence
+
+	// PreserveFormat keeps markup intact: "none" (default), "markdown", "html"
+	// or "subtitle".
+	PreserveFormat string `json:"preserveFormat,omitempty"`
+
+	// Glossary maps source terms to their required translations. A term
+	// mapped to itself or to "" must be kept untranslated.
+	Glossary map[string]string `json:"glossary,omitempty"`
 }
 
 // DetectResult represents the result of language detection.
@@ -73,6 +242,7 @@ type DetectResult struct {
 	Code          string `json:"code"`
 	Name          string `json:"name"`
 	DefaultTarget string `json:"defaultTarget"`
+	Supported     bool   `json:"supported"` // False if the language settings don't cover Code
 }
 
 // Usage represents token usage statistics from LLM API calls.
@@ -81,12 +251,24 @@ type Usage struct {
 	CompletionTokens int  `json:"completionTokens"`
 	TotalTokens      int  `json:"totalTokens"`
 	CacheHit         bool `json:"cacheHit"`
+	Estimated        bool `json:"estimated,omitempty"` // Counts estimated locally; the provider reported none
+}
+
+// ModelPrice is a model's price in USD per million tokens.
+type ModelPrice struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
 }
 
 // TranslateResult represents the result of a translation request.
 type TranslateResult struct {
 	Text  string `json:"text"`
 	Usage Usage  `json:"usage"`
+
+	// Set on streamed results only: Done marks the final result, and
+	// Error is set on it if the stream broke off.
+	Done  bool   `json:"done,omitempty"`
+	Error string `json:"error,omitempty"`
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -105,15 +287,55 @@ type LiveTranscript struct {
 	Timestamp  int64   `json:"timestamp"`  // Unix timestamp in milliseconds (creation time)
 	IsFinal    bool    `json:"isFinal"`    // Whether this is the final result
 	Confidence float64 `json:"confidence"` // Recognition confidence 0-1
+
+	SessionStart int64  `json:"sessionStart,omitempty"` // Session start, Unix milliseconds; 0 if unknown
+	Speaker      string `json:"speaker,omitempty"`      // Diarization label, e.g. "A"; empty if unknown
+}
+
+// WallTime converts an offset in milliseconds since session start, such as
+// StartTime or EndTime, to wall-clock time. It returns the zero time if the
+// session start is unknown.
+func (t LiveTranscript) WallTime(offset int64) time.Time {
+	if t.SessionStart == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(t.SessionStart + offset)
+}
+
+// VAD modes of VADSettings.
+const (
+	VADModeSemantic = "semantic" // Segments at the end of a thought; tuned by Eagerness
+	VADModeServer   = "server"   // Segments after a silence; tuned by the other fields
+)
+
+// VADSettings tunes the voice activity detection that splits live speech
+// into segments. Zero fields keep the provider defaults. In noisy rooms, a
+// higher Threshold avoids false triggers; a longer SilenceDurationMs
+// avoids cutting speakers off mid-sentence.
+type VADSettings struct {
+	Mode      string `json:"mode,omitempty"`      // VADModeSemantic (default) or VADModeServer
+	Eagerness string `json:"eagerness,omitempty"` // Semantic mode: "low", "medium", "high" (default) or "auto"
+
+	// Server mode only.
+	Threshold         float64 `json:"threshold,omitempty"`           // Speech activation threshold, 0-1
+	PrefixPaddingMs   int     `json:"prefix_padding_ms,omitempty"`   // Audio kept from before speech starts
+	SilenceDurationMs int     `json:"silence_duration_ms,omitempty"` // Silence that ends a segment
+}
+
+// VADConfigurer is implemented by live translators that can apply new VAD
+// settings to a running session.
+type VADConfigurer interface {
+	SetVADConfig(v VADSettings) error
 }
 
 // VADState represents the current voice activity state.
 type VADState string
 
 const (
-	VADStateListening  VADState = "listening"
-	VADStateSpeaking   VADState = "speaking"
-	VADStateProcessing VADState = "processing"
+	VADStateListening    VADState = "listening"
+	VADStateSpeaking     VADState = "speaking"
+	VADStateProcessing   VADState = "processing"
+	VADStateReconnecting VADState = "reconnecting" // Connection lost, re-dialling
 )
 
 // LiveStatus represents the status of live translation.
@@ -125,6 +347,7 @@ type LiveStatus struct {
 	STTProvider     string   `json:"sttProvider"`     // Current STT provider name
 	TranscriptCount int      `json:"transcriptCount"` // Number of transcribed segments
 	VADState        VADState `json:"vadState"`        // Current VAD state
+	SessionStart    int64    `json:"sessionStart"`    // Session start, Unix milliseconds; 0 if none
 }
 
 // STTProviderInfo represents information about an STT provider.
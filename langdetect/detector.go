@@ -76,3 +76,13 @@ func Detect(text string) (code, name string) {
 
 	return info.code, info.name
 }
+
+// IsSupported reports whether code is one of the supported language codes.
+func IsSupported(code string) bool {
+	for _, info := range languageMap {
+		if info.code == code {
+			return true
+		}
+	}
+	return false
+}
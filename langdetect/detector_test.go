@@ -32,3 +32,24 @@ func TestDetect(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSupported(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"en", true},
+		{"zh", true},
+		{"ar", true},
+		{"auto", false},
+		{"EN", false},
+		{"xx", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSupported(tt.code); got != tt.want {
+			t.Errorf("IsSupported(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package langdetect
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// SystemLanguage returns the supported language code of the user's
+// preferred system language, or "" if it is unknown or unsupported. It is
+// read once per process.
+var SystemLanguage = sync.OnceValue(func() string {
+	for _, tag := range append(platformLanguages(), os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")) {
+		if code := localeLanguage(tag); code != "" {
+			return code
+		}
+	}
+	return ""
+})
+
+// localeLanguage returns the supported language code for a locale or
+// language tag such as "zh-Hans-CN" or "de_DE.UTF-8", or "" if there is none.
+func localeLanguage(tag string) string {
+	end := strings.IndexAny(tag, "-_.@")
+	if end < 0 {
+		end = len(tag)
+	}
+	code := strings.ToLower(tag[:end])
+	if code == "" || !IsSupported(code) {
+		return ""
+	}
+	return code
+}
+
+// parseAppleLanguages parses the property list array printed by
+// "defaults read -g AppleLanguages", e.g. ("zh-Hans-CN", "en-CN").
+func parseAppleLanguages(out string) []string {
+	out = strings.TrimSpace(out)
+	out = strings.TrimSuffix(strings.TrimPrefix(out, "("), ")")
+	var langs []string
+	for _, item := range strings.Split(out, ",") {
+		if item = strings.Trim(strings.TrimSpace(item), `"`); item != "" {
+			langs = append(langs, item)
+		}
+	}
+	return langs
+}
@@ -0,0 +1,13 @@
+package langdetect
+
+import "os/exec"
+
+// platformLanguages returns the user's preferred languages from macOS
+// settings, most preferred first. Apps launched from Finder don't get LANG.
+func platformLanguages() []string {
+	out, err := exec.Command("defaults", "read", "-g", "AppleLanguages").Output()
+	if err != nil {
+		return nil
+	}
+	return parseAppleLanguages(string(out))
+}
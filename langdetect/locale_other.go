@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package langdetect
+
+// platformLanguages returns the user's preferred languages from platform
+// settings; other platforms rely on the locale environment.
+func platformLanguages() []string {
+	return nil
+}
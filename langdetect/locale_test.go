@@ -0,0 +1,39 @@
+package langdetect
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLocaleLanguage(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"zh-Hans-CN", "zh"},
+		{"de_DE.UTF-8", "de"},
+		{"ja", "ja"},
+		{"EN_us", "en"},
+		{"fr_FR@euro", "fr"},
+		{"C", ""},
+		{"POSIX", ""},
+		{"nl_NL.UTF-8", ""}, // Not supported
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := localeLanguage(tt.tag); got != tt.want {
+			t.Errorf("localeLanguage(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestParseAppleLanguages(t *testing.T) {
+	out := "(\n    \"zh-Hans-CN\",\n    \"en-CN\",\n    ja\n)\n"
+	want := []string{"zh-Hans-CN", "en-CN", "ja"}
+	if got := parseAppleLanguages(out); !slices.Equal(got, want) {
+		t.Errorf("parseAppleLanguages() = %q, want %q", got, want)
+	}
+	if got := parseAppleLanguages(""); len(got) != 0 {
+		t.Errorf("parseAppleLanguages(\"\") = %q, want none", got)
+	}
+}
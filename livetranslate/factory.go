@@ -4,7 +4,9 @@ package livetranslate
 
 import (
 	"errors"
+	"time"
 
+	"go.aimuz.me/transy/audiocapture"
 	"go.aimuz.me/transy/internal/types"
 	"go.aimuz.me/transy/livetranslate/openai"
 )
@@ -13,9 +15,35 @@ import (
 // Zero values are replaced with sensible defaults.
 type Config struct {
 	APIKey       string
+	OrgID        string // Optional OpenAI-Organization header
+	ProjectID    string // Optional OpenAI-Project header
 	Model        string // Default: "gpt-4o-realtime-preview"
 	SystemPrompt string
 	Temperature  float64 // Default: 0.6
+	Normalize    bool    // Restore casing and punctuation in final transcripts
+
+	// Prompt biases transcription toward domain terms and names.
+	// PromptContext also feeds recent transcripts into the prompt.
+	Prompt        string
+	PromptContext bool
+
+	// Diarize labels transcripts with their speaker using the diarization
+	// model, in place of Model.
+	Diarize bool
+
+	// MaxSpeaking is how long the VAD state may stay speaking without
+	// updates before it is reset to listening. Default: 30s.
+	MaxSpeaking time.Duration
+
+	// AudioSource selects system audio, the microphone or both.
+	// Default: audiocapture.SourceSystem.
+	AudioSource audiocapture.Source
+
+	// AudioDeviceID selects the microphone; empty uses the default input.
+	AudioDeviceID string
+
+	// VAD tunes voice activity detection. Default: semantic VAD.
+	VAD types.VADSettings
 }
 
 // New creates a new LiveTranslator using OpenAI Realtime API.
@@ -31,11 +59,29 @@ func New(cfg Config) (types.LiveTranslator, error) {
 	if cfg.Temperature == 0 {
 		cfg.Temperature = 0.6
 	}
+	if cfg.MaxSpeaking <= 0 {
+		cfg.MaxSpeaking = openai.DefaultMaxSpeaking
+	}
 
-	return openai.NewService(openai.ServiceConfig{
+	svcCfg := openai.ServiceConfig{
 		APIKey:       cfg.APIKey,
+		OrgID:        cfg.OrgID,
+		ProjectID:    cfg.ProjectID,
 		Model:        cfg.Model,
 		SystemPrompt: cfg.SystemPrompt,
 		Temperature:  cfg.Temperature,
-	})
+
+		Prompt:        cfg.Prompt,
+		ContextPrompt: cfg.PromptContext,
+		Diarize:       cfg.Diarize,
+		MaxSpeaking:   cfg.MaxSpeaking,
+		AudioSource:   cfg.AudioSource,
+		AudioDeviceID: cfg.AudioDeviceID,
+		VAD:           cfg.VAD,
+	}
+	if cfg.Normalize {
+		svcCfg.Normalize = Normalize
+	}
+
+	return openai.NewService(svcCfg)
 }
@@ -0,0 +1,194 @@
+package livetranslate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// MockProvider is the name of the scripted provider. The app registers it
+// when TRANSY_MOCK_STT=1.
+const MockProvider = "mock"
+
+// mockInterval is the pause before each scripted line.
+var mockInterval = 800 * time.Millisecond
+
+// NewMockProvider returns a provider for tests and demos that needs no
+// audio hardware, API key or setup. Each session emits script as
+// successive final transcripts, one line per interval, with the VAD
+// updates a real speaker would produce, then keeps listening until
+// stopped.
+func NewMockProvider(script []string) Provider {
+	return mockProvider{script: append([]string(nil), script...)}
+}
+
+type mockProvider struct {
+	script []string
+}
+
+func (mockProvider) Name() string { return MockProvider }
+
+func (p mockProvider) New(Config) (types.LiveTranslator, error) {
+	return newMockTranslator(p.script, mockInterval), nil
+}
+
+func (mockProvider) Local() bool { return true }
+
+func (mockProvider) Info(Config) types.STTProviderInfo {
+	return types.STTProviderInfo{
+		Name:          MockProvider,
+		DisplayName:   "Mock (scripted)",
+		IsLocal:       true,
+		SetupProgress: -1,
+		IsReady:       true,
+	}
+}
+
+// mockTranslator replays a script as a LiveTranslator.
+type mockTranslator struct {
+	script   []string
+	interval time.Duration
+
+	transcripts chan types.LiveTranscript
+	errs        chan error
+	vad         chan types.VADState
+
+	mu         sync.Mutex
+	running    bool
+	started    bool
+	sourceLang string
+	targetLang string
+	start      time.Time
+	count      int
+	vadState   types.VADState
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+func newMockTranslator(script []string, interval time.Duration) *mockTranslator {
+	return &mockTranslator{
+		script:      script,
+		interval:    interval,
+		transcripts: make(chan types.LiveTranscript, len(script)+1),
+		errs:        make(chan error, 1),
+		vad:         make(chan types.VADState, 2*len(script)+1),
+		vadState:    types.VADStateListening,
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins replaying the script. A translator runs once, like the
+// real providers.
+func (m *mockTranslator) Start(ctx context.Context, sourceLang, targetLang string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.started {
+		return errors.New("livetranslate: mock translator already started")
+	}
+	m.started = true
+	m.running = true
+	m.sourceLang = sourceLang
+	m.targetLang = targetLang
+	m.start = time.Now()
+
+	m.wg.Go(func() { m.run(ctx) })
+	return nil
+}
+
+func (m *mockTranslator) run(ctx context.Context) {
+	defer func() {
+		close(m.transcripts)
+		close(m.vad)
+		close(m.errs)
+	}()
+
+	timer := time.NewTimer(m.interval)
+	defer timer.Stop()
+	for i, line := range m.script {
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return
+		case <-m.done:
+			return
+		}
+		m.setVAD(types.VADStateSpeaking)
+		m.vad <- types.VADStateSpeaking
+
+		m.mu.Lock()
+		m.count++
+		start := m.start
+		sourceLang, targetLang := m.sourceLang, m.targetLang
+		m.mu.Unlock()
+
+		now := time.Now()
+		m.transcripts <- types.LiveTranscript{
+			ID:           fmt.Sprintf("mock-%d", i+1),
+			SourceText:   line,
+			SourceLang:   sourceLang,
+			TargetLang:   targetLang,
+			StartTime:    now.Add(-m.interval / 2).Sub(start).Milliseconds(),
+			EndTime:      now.Sub(start).Milliseconds(),
+			Timestamp:    now.UnixMilli(),
+			IsFinal:      true,
+			Confidence:   1,
+			SessionStart: start.UnixMilli(),
+		}
+
+		m.setVAD(types.VADStateListening)
+		m.vad <- types.VADStateListening
+		timer.Reset(m.interval)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-m.done:
+	}
+}
+
+func (m *mockTranslator) setVAD(state types.VADState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vadState = state
+}
+
+// Stop ends the session and closes the output channels.
+func (m *mockTranslator) Stop() error {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return nil
+	}
+	m.running = false
+	close(m.done)
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	return nil
+}
+
+func (m *mockTranslator) Transcripts() <-chan types.LiveTranscript { return m.transcripts }
+func (m *mockTranslator) Errors() <-chan error                     { return m.errs }
+func (m *mockTranslator) VADUpdates() <-chan types.VADState        { return m.vad }
+
+func (m *mockTranslator) Status() types.LiveStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := types.LiveStatus{
+		Active:          m.running,
+		SourceLang:      m.sourceLang,
+		TargetLang:      m.targetLang,
+		STTProvider:     MockProvider,
+		TranscriptCount: m.count,
+		VADState:        m.vadState,
+	}
+	if m.running {
+		status.Duration = int64(time.Since(m.start).Seconds())
+		status.SessionStart = m.start.UnixMilli()
+	}
+	return status
+}
@@ -0,0 +1,92 @@
+package livetranslate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestMockProvider(t *testing.T) {
+	old := mockInterval
+	mockInterval = time.Millisecond
+	t.Cleanup(func() { mockInterval = old })
+
+	script := []string{"hello", "world"}
+	p := NewMockProvider(script)
+	script[0] = "changed"
+
+	if !IsLocal(p) {
+		t.Error("mock provider should be local")
+	}
+	if info := Describe(p, Config{}); !info.IsReady || info.Name != MockProvider {
+		t.Errorf("Describe() = %+v, want ready %q", info, MockProvider)
+	}
+
+	svc, err := p.New(Config{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := svc.Start(context.Background(), "en", "zh"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := svc.Start(context.Background(), "en", "zh"); err == nil {
+		t.Error("second Start() should fail")
+	}
+
+	for i, want := range []string{"hello", "world"} {
+		select {
+		case tr := <-svc.Transcripts():
+			if tr.SourceText != want || !tr.IsFinal || tr.SourceLang != "en" || tr.TargetLang != "zh" {
+				t.Errorf("transcript %d = %+v, want final %q en→zh", i, tr, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("transcript %d not emitted", i)
+		}
+	}
+
+	if st := svc.Status(); !st.Active || st.TranscriptCount != 2 || st.STTProvider != MockProvider {
+		t.Errorf("Status() = %+v, want active with 2 transcripts", st)
+	}
+
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := svc.Stop(); err != nil {
+		t.Errorf("second Stop() error = %v", err)
+	}
+	if _, ok := <-svc.Transcripts(); ok {
+		t.Error("Transcripts() not closed after Stop")
+	}
+
+	var states []types.VADState
+	for s := range svc.VADUpdates() {
+		states = append(states, s)
+	}
+	want := []types.VADState{types.VADStateSpeaking, types.VADStateListening, types.VADStateSpeaking, types.VADStateListening}
+	if len(states) != len(want) {
+		t.Fatalf("VAD updates = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("VAD update %d = %v, want %v", i, states[i], want[i])
+		}
+	}
+}
+
+func TestMockProviderStopEarly(t *testing.T) {
+	svc := newMockTranslator([]string{"never"}, time.Hour)
+	if err := svc.Start(context.Background(), "en", "zh"); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, ok := <-svc.Transcripts(); ok {
+		t.Error("transcript emitted after Stop")
+	}
+	if svc.Status().Active {
+		t.Error("Status().Active after Stop")
+	}
+}
@@ -0,0 +1,79 @@
+package livetranslate
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// caselessLangs are languages whose scripts have no letter case or use
+// their own punctuation, so Normalize leaves them untouched.
+var caselessLangs = map[string]bool{
+	"zh": true,
+	"ja": true,
+	"ko": true,
+	"ar": true,
+}
+
+// Normalize heuristically restores sentence casing and terminal punctuation
+// in a final transcript. Sentence starts are capitalized and a period is
+// appended when the text ends without punctuation. Text in caseless
+// languages, or containing CJK characters, is returned trimmed but otherwise
+// unchanged.
+func Normalize(text, lang string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || caselessLangs[lang] || hasCJK(text) {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) + 1)
+	start := true
+	for _, r := range text {
+		switch {
+		case start && unicode.IsLetter(r):
+			r = unicode.ToUpper(r)
+			start = false
+		case isSentenceEnd(r):
+			start = true
+		case !unicode.IsSpace(r) && !unicode.IsPunct(r):
+			start = false
+		}
+		b.WriteRune(r)
+	}
+
+	out := b.String()
+	if lang == "en" {
+		out = capitalizeI(out)
+	}
+	if last, _ := utf8.DecodeLastRuneInString(out); !unicode.IsPunct(last) {
+		out += "."
+	}
+	return out
+}
+
+// isSentenceEnd reports whether r terminates a sentence.
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// capitalizeI upper-cases the English pronoun "i" and its contractions.
+func capitalizeI(s string) string {
+	words := strings.Split(s, " ")
+	for i, w := range words {
+		if w == "i" || strings.HasPrefix(w, "i'") {
+			words[i] = "I" + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// hasCJK reports whether s contains Han, Kana or Hangul characters.
+func hasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package livetranslate
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		lang string
+		want string
+	}{
+		{"empty", "  ", "en", ""},
+		{"capitalize and terminate", "hello world", "en", "Hello world."},
+		{"keeps punctuation", "is it raining?", "en", "Is it raining?"},
+		{"sentence starts", "it works. does it? yes! great", "en", "It works. Does it? Yes! Great."},
+		{"pronoun i", "i think i'm right", "en", "I think I'm right."},
+		{"leading quote", "\"quoted start\"", "en", "\"Quoted start\""},
+		{"already normalized", "Fine.", "en", "Fine."},
+		{"other latin", "bonjour tout le monde", "fr", "Bonjour tout le monde."},
+		{"pronoun only for english", "je pense que i", "fr", "Je pense que i."},
+		{"cyrillic", "привет мир", "ru", "Привет мир."},
+		{"chinese noop", "你好世界", "zh", "你好世界"},
+		{"japanese noop", "こんにちは", "ja", "こんにちは"},
+		{"cjk text in auto mode", "hello 世界", "auto", "hello 世界"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.text, tt.lang); got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.text, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
@@ -33,22 +33,53 @@ const (
 	VADEagernessAuto   VADEagerness = "auto"
 )
 
-// TurnDetection configures voice activity detection.
+// TurnDetection configures voice activity detection. Eagerness applies to
+// semantic VAD; Threshold and the durations to server VAD.
 type TurnDetection struct {
 	Type              VADType      `json:"type"`
 	Eagerness         VADEagerness `json:"eagerness,omitempty"`
 	CreateResponse    bool         `json:"create_response,omitempty"`
 	InterruptResponse bool         `json:"interrupt_response,omitempty"`
+	Threshold         float64      `json:"threshold,omitempty"`
+	PrefixPaddingMs   int          `json:"prefix_padding_ms,omitempty"`
+	SilenceDurationMs int          `json:"silence_duration_ms,omitempty"`
 }
 
-// SessionUpdate is a client event to update session configuration.
-type SessionUpdate struct {
+// TurnDetectionUpdate is a client event that replaces the voice activity
+// detection of a transcription session.
+type TurnDetectionUpdate struct {
 	Type    string `json:"type"`
 	Session struct {
-		TurnDetection *TurnDetection `json:"turn_detection,omitempty"`
+		Type  string `json:"type"`
+		Audio struct {
+			Input struct {
+				TurnDetection TurnDetection `json:"turn_detection"`
+			} `json:"input"`
+		} `json:"audio"`
 	} `json:"session"`
 }
 
+// TranscriptionUpdate is a client event that replaces the input
+// transcription settings of a transcription session.
+type TranscriptionUpdate struct {
+	Type    string `json:"type"`
+	Session struct {
+		Type  string `json:"type"`
+		Audio struct {
+			Input struct {
+				Transcription TranscriptionSettings `json:"transcription"`
+			} `json:"input"`
+		} `json:"audio"`
+	} `json:"session"`
+}
+
+// TranscriptionSettings configures input audio transcription.
+type TranscriptionSettings struct {
+	Model    string `json:"model"`
+	Language string `json:"language,omitempty"`
+	Prompt   string `json:"prompt,omitempty"`
+}
+
 // Event is a discriminated union for Realtime API events.
 // Check the concrete type via type switch.
 type Event interface {
@@ -110,6 +141,8 @@ type TranscriptEvent struct {
 	EventID    string `json:"event_id"`
 	ItemID     string `json:"item_id"`
 	Transcript string `json:"transcript"`
+	Speaker    string `json:"speaker,omitempty"`  // Set by diarization models
+	Language   string `json:"language,omitempty"` // Detected language, from servers that report it
 }
 
 func (TranscriptEvent) eventType() string { return EventTranscriptionCompleted }
@@ -120,6 +153,7 @@ type TranscriptDeltaEvent struct {
 	ItemID     string `json:"item_id"`
 	ContentIdx int    `json:"content_index"`
 	Delta      string `json:"delta"`
+	Speaker    string `json:"speaker,omitempty"` // Set by diarization models
 }
 
 func (TranscriptDeltaEvent) eventType() string { return EventTranscriptionDelta }
@@ -54,7 +54,54 @@ func TestParseEvent(t *testing.T) {
 				}
 			},
 		},
-
+		{
+			name: "TranscriptCompletedDiarized",
+			json: `{
+				"type": "conversation.item.input_audio_transcription.completed",
+				"event_id": "evt_125",
+				"item_id": "item_123",
+				"transcript": "Hello world",
+				"speaker": "B"
+			}`,
+			wantType: EventTranscriptionCompleted,
+			checkFunc: func(t *testing.T, e Event) {
+				if got := e.(TranscriptEvent).Speaker; got != "B" {
+					t.Errorf("Speaker = %q, want %q", got, "B")
+				}
+			},
+		},
+		{
+			name: "TranscriptCompletedWithLanguage",
+			json: `{
+				"type": "conversation.item.input_audio_transcription.completed",
+				"event_id": "evt_127",
+				"item_id": "item_123",
+				"transcript": "Guten Morgen",
+				"language": "de"
+			}`,
+			wantType: EventTranscriptionCompleted,
+			checkFunc: func(t *testing.T, e Event) {
+				if got := e.(TranscriptEvent).Language; got != "de" {
+					t.Errorf("Language = %q, want %q", got, "de")
+				}
+			},
+		},
+		{
+			name: "TranscriptionDeltaDiarized",
+			json: `{
+				"type": "conversation.item.input_audio_transcription.delta",
+				"event_id": "evt_126",
+				"item_id": "item_123",
+				"delta": "Hello",
+				"speaker": "A"
+			}`,
+			wantType: EventTranscriptionDelta,
+			checkFunc: func(t *testing.T, e Event) {
+				if got := e.(TranscriptDeltaEvent).Speaker; got != "A" {
+					t.Errorf("Speaker = %q, want %q", got, "A")
+				}
+			},
+		},
 		{
 			name: "Error",
 			json: `{
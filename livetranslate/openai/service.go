@@ -4,21 +4,70 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.aimuz.me/transy/audiocapture"
 	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/langdetect"
 )
 
 // ServiceConfig holds configuration for the Realtime Service.
 // Immutable once created.
 type ServiceConfig struct {
 	APIKey       string
+	OrgID        string
+	ProjectID    string
 	Model        string
 	SystemPrompt string
 	Temperature  float64
+
+	// Prompt holds biasing phrases for transcription. If ContextPrompt is
+	// set, recent transcripts are appended as the session progresses.
+	Prompt        string
+	ContextPrompt bool
+
+	// Diarize transcribes with DiarizeModel so transcripts carry speaker labels.
+	Diarize bool
+
+	// Normalize, if set, rewrites each final transcript given its source language.
+	Normalize func(text, lang string) string
+
+	// MaxSpeaking resets a VAD state stuck at speaking, e.g. after a dropped
+	// speech-stopped event, once no event has arrived for this long.
+	// Zero disables the reset.
+	MaxSpeaking time.Duration
+
+	// AudioSource selects the captured audio; empty captures system audio.
+	AudioSource   audiocapture.Source
+	AudioDeviceID string // Microphone ID from audiocapture.ListDevices; empty uses the default
+
+	// VAD tunes voice activity detection; see SetVADConfig to change it
+	// mid-session.
+	VAD types.VADSettings
+}
+
+// DefaultMaxSpeaking is the suggested ServiceConfig.MaxSpeaking.
+const DefaultMaxSpeaking = 30 * time.Second
+
+// maxReconnectAttempts bounds how often a lost connection is re-dialled
+// before the session gives up.
+const maxReconnectAttempts = 5
+
+// reconnectBackoff is the delay before the first reconnect attempt; it
+// doubles per attempt.
+var reconnectBackoff = time.Second
+
+// conn is the connection the service drives. *Client implements it.
+type conn interface {
+	Messages() <-chan Event
+	Errors() <-chan error
+	SendAudio(samples []float32) error
+	UpdateTranscription(ts TranscriptionSettings) error
+	ConfigureVAD(td TurnDetection) error
+	Close() error
 }
 
 // sessionState holds mutable state for a single running session.
@@ -45,6 +94,8 @@ type itemState struct {
 	EndTime     int64 // Set when SpeechStopped
 	SourceFinal bool
 	TargetFinal bool
+	Lang        string // Detected source language, set once the transcript is final
+	Speaker     string // Diarization label, if the model provides one
 }
 
 // Service provides real-time speech-to-speech/text execution using OpenAI Realtime API.
@@ -53,8 +104,12 @@ type Service struct {
 	config ServiceConfig
 
 	// Dependencies
-	client *Client
-	audio  audiocapture.Capturer
+	audio audiocapture.Capturer
+	dial  func(ctx context.Context) (conn, error) // Opens a connection for the current session
+
+	clientMu sync.Mutex
+	client   conn              // Nil while reconnecting
+	vad      types.VADSettings // Current VAD settings, for new connections
 
 	// State - atomic for lock-free reads
 	running atomic.Bool
@@ -72,20 +127,102 @@ type Service struct {
 	// Item State - Mutex protected for concurrent updates
 	muItems     sync.Mutex
 	activeItems map[string]*itemState // Map[ItemID]*itemState
+	recent      []string              // Latest final transcripts for the prompt
 }
 
+// promptContextSize is the number of recent transcripts added to the prompt.
+const promptContextSize = 3
+
 // NewService creates a new Realtime Service.
 func NewService(cfg ServiceConfig) (*Service, error) {
 	// WebRTC Opus uses 48kHz - capture at native rate
-	audioCap, err := audiocapture.New(48000)
+	audioCap, err := audiocapture.NewWithConfig(audiocapture.Config{
+		SampleRate: 48000,
+		Source:     cfg.AudioSource,
+		DeviceID:   cfg.AudioDeviceID,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("create audio capture: %w", err)
 	}
 
-	return &Service{
+	s := &Service{
 		config: cfg,
 		audio:  audioCap,
-	}, nil
+		vad:    cfg.VAD,
+	}
+	s.dial = s.dialClient
+	return s, nil
+}
+
+// dialClient creates and connects a client for the current session,
+// seeding the prompt with the recent transcripts.
+func (s *Service) dialClient(ctx context.Context) (conn, error) {
+	var lang string
+	if sess := s.sess.Load(); sess != nil {
+		lang = sessionLanguage(sess.sourceLang)
+	}
+	s.muItems.Lock()
+	recent := strings.Join(s.recent, " ")
+	s.muItems.Unlock()
+
+	s.clientMu.Lock()
+	vad := s.vad
+	s.clientMu.Unlock()
+
+	client, err := NewClient(Config{
+		APIKey: s.config.APIKey,
+		Session: SessionConfig{
+			OrgID:     s.config.OrgID,
+			ProjectID: s.config.ProjectID,
+			Model:     s.config.Model,
+			Diarize:   s.config.Diarize,
+			Language:  lang,
+			Prompt:    livePrompt(s.config.Prompt, recent),
+			VAD:       vad,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+	client.OnDataChannelOpen(func() {
+		slog.Info("data channel ready")
+	})
+	if err := client.Connect(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect client: %w", err)
+	}
+	return client, nil
+}
+
+// conn returns the current connection, or nil while reconnecting.
+func (s *Service) conn() conn {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	return s.client
+}
+
+func (s *Service) setConn(c conn) {
+	s.clientMu.Lock()
+	s.client = c
+	s.clientMu.Unlock()
+}
+
+// SetVADConfig applies new voice activity detection settings. A running
+// session is updated in place, without restarting capture; otherwise they
+// take effect when the next connection is made.
+func (s *Service) SetVADConfig(v types.VADSettings) error {
+	s.clientMu.Lock()
+	s.vad = v
+	c := s.client
+	s.clientMu.Unlock()
+
+	if c == nil {
+		return nil
+	}
+	if err := c.ConfigureVAD(turnDetection(v)); err != nil {
+		return fmt.Errorf("update VAD: %w", err)
+	}
+	return nil
 }
 
 // Start begins the realtime session.
@@ -115,41 +252,24 @@ func (s *Service) Start(ctx context.Context, sourceLang, targetLang string) erro
 
 	// Initialize state maps
 	s.activeItems = make(map[string]*itemState)
+	s.recent = nil
 
-	// Create client
-	client, err := NewClient(Config{
-		APIKey: s.config.APIKey,
-		Session: SessionConfig{
-			Model:  s.config.Model,
-			Prompt: s.config.SystemPrompt,
-		},
-	})
+	client, err := s.dial(ctx)
 	if err != nil {
 		cancel()
-		return fmt.Errorf("create client: %w", err)
-	}
-	s.client = client
-
-	// Setup callbacks
-	s.client.OnDataChannelOpen(func() {
-		slog.Info("data channel ready")
-	})
-
-	// Connect
-	if err := s.client.Connect(ctx); err != nil {
-		cancel()
-		return fmt.Errorf("connect client: %w", err)
+		return err
 	}
+	s.setConn(client)
 
 	// Start Audio with handler
 	if err := s.audio.Start(s.handleAudio); err != nil {
-		s.client.Close()
+		client.Close()
 		cancel()
 		return fmt.Errorf("start audio: %w", err)
 	}
 
 	s.running.Store(true)
-	go s.processEvents()
+	go s.runEvents(ctx, client)
 
 	slog.Info("realtime service started")
 	return nil
@@ -171,44 +291,161 @@ func (s *Service) Stop() error {
 	if s.audio != nil {
 		_ = s.audio.Stop()
 	}
-	if s.client != nil {
-		_ = s.client.Close()
+	if c := s.conn(); c != nil {
+		_ = c.Close()
 	}
 
 	return nil
 }
 
 func (s *Service) handleAudio(samples []float32) {
-	if err := s.client.SendAudio(samples); err != nil {
+	c := s.conn()
+	if c == nil {
+		return // Reconnecting
+	}
+	if err := c.SendAudio(samples); err != nil {
 		slog.Warn("failed to send audio", "error", err)
 	}
 }
 
-func (s *Service) processEvents() {
+// runEvents handles events from c until the session stops, then closes
+// the output channels. A connection that fails while running is replaced
+// via reconnect. It also runs the VAD watchdog, so the reset can't race
+// with the handlers or the close.
+func (s *Service) runEvents(ctx context.Context, c conn) {
 	defer func() {
 		close(s.transcriptChan)
 		close(s.vadChan)
 		close(s.errorChan)
 	}()
 
-	for event := range s.client.Messages() {
-		switch e := event.(type) {
-		case TranscriptEvent:
-			s.handleTranscript(e)
-		case TranscriptDeltaEvent:
-			s.handleTranscriptDelta(e)
-		case SpeechStartedEvent:
-			s.handleSpeechStarted(e)
-		case SpeechStoppedEvent:
-			s.handleSpeechStopped(e)
-		case ItemDoneEvent:
-			if e.Item.Role == "assistant" {
+	watchdog := time.NewTimer(time.Hour)
+	watchdog.Stop()
+	defer watchdog.Stop()
+
+	msgs, errs := c.Messages(), c.Errors()
+	for {
+		select {
+		case event, ok := <-msgs:
+			if ok {
+				s.handleEvent(event)
+				break
+			}
+			if !s.running.Load() {
+				return
+			}
+			if c = s.reconnect(ctx, c, ErrClosed); c == nil {
+				return
+			}
+			msgs, errs = c.Messages(), c.Errors()
+		case err := <-errs:
+			if !s.running.Load() {
+				continue // Stopping; msgs closes next
+			}
+			if c = s.reconnect(ctx, c, err); c == nil {
+				return
+			}
+			msgs, errs = c.Messages(), c.Errors()
+		case <-watchdog.C:
+			if sess := s.sess.Load(); sess != nil && sess.vadState == types.VADStateSpeaking {
+				slog.Warn("VAD stuck at speaking, resetting", "after", s.config.MaxSpeaking)
 				s.updateVAD(types.VADStateListening)
 			}
-		case ErrorEvent:
-			s.sendError(fmt.Errorf("api error: %s (%s)", e.Error.Message, e.Error.Code))
+		}
+
+		// Any event counts as activity; only a silent speaking state is stuck.
+		if sess := s.sess.Load(); s.config.MaxSpeaking > 0 && sess != nil && sess.vadState == types.VADStateSpeaking {
+			watchdog.Reset(s.config.MaxSpeaking)
+		} else {
+			watchdog.Stop()
+		}
+	}
+}
+
+// reconnect replaces the failed connection old, re-dialling with
+// exponential backoff. The UI sees the reconnecting VAD state meanwhile.
+// Items in flight are finalized with the text received so far. It returns
+// the new connection, or nil if the session stopped or every attempt
+// failed, in which case the session is stopped.
+func (s *Service) reconnect(ctx context.Context, old conn, cause error) conn {
+	slog.Warn("realtime connection lost, reconnecting", "error", cause)
+	s.setConn(nil)
+	_ = old.Close()
+	s.updateVAD(types.VADStateReconnecting)
+	s.resetItems()
+
+	delay := reconnectBackoff
+	var err error
+	for attempt := 1; attempt <= maxReconnectAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay *= 2
+
+		var c conn
+		c, err = s.dial(ctx)
+		if err != nil {
+			slog.Warn("realtime reconnect failed", "attempt", attempt, "error", err)
+			continue
+		}
+
+		// Hold mu so a concurrent Stop either sees the new connection
+		// and closes it, or has already stopped the session.
+		s.mu.Lock()
+		running := s.running.Load()
+		if running {
+			s.setConn(c)
+		}
+		s.mu.Unlock()
+		if !running {
+			_ = c.Close()
+			return nil
+		}
+		slog.Info("realtime connection restored", "attempt", attempt)
+		s.updateVAD(types.VADStateListening)
+		return c
+	}
+
+	s.sendError(fmt.Errorf("reconnect failed after %d attempts: %w", maxReconnectAttempts, err))
+	_ = s.Stop()
+	return nil
+}
+
+// resetItems finalizes items cut off by a lost connection, so their
+// captions don't stay pending, and clears the item state.
+func (s *Service) resetItems() {
+	s.muItems.Lock()
+	defer s.muItems.Unlock()
+
+	sess := s.sess.Load()
+	for _, item := range s.activeItems {
+		if item.SourceText != "" && !item.SourceFinal {
+			item.SourceFinal = true
+			s.emit(item, sess)
 		}
 	}
+	s.activeItems = make(map[string]*itemState)
+}
+
+func (s *Service) handleEvent(event Event) {
+	switch e := event.(type) {
+	case TranscriptEvent:
+		s.handleTranscript(e)
+	case TranscriptDeltaEvent:
+		s.handleTranscriptDelta(e)
+	case SpeechStartedEvent:
+		s.handleSpeechStarted(e)
+	case SpeechStoppedEvent:
+		s.handleSpeechStopped(e)
+	case ItemDoneEvent:
+		if e.Item.Role == "assistant" {
+			s.updateVAD(types.VADStateListening)
+		}
+	case ErrorEvent:
+		s.sendError(fmt.Errorf("api error: %s (%s)", e.Error.Message, e.Error.Code))
+	}
 }
 
 // handleSpeechStarted handles VAD speech start event.
@@ -295,9 +532,53 @@ func (s *Service) handleTranscript(e TranscriptEvent) {
 
 	item.SourceText = e.Transcript
 	item.SourceFinal = true
+	if e.Speaker != "" {
+		item.Speaker = e.Speaker
+	}
+	if code := reportedLang(e.Language); code != "" {
+		item.Lang = code
+	}
+	if sess := s.sess.Load(); sess != nil && s.config.Normalize != nil {
+		item.SourceText = s.config.Normalize(item.SourceText, sourceLangOf(item, sess))
+	}
 
 	// OpenAI guarantees this event comes after speech stopped and audio is processed.
 	s.emit(item, s.sess.Load())
+
+	if s.config.ContextPrompt {
+		s.updatePrompt(item.SourceText)
+	}
+}
+
+// updatePrompt records a final transcript and refreshes the session's
+// transcription prompt with the recent context. Caller holds muItems.
+func (s *Service) updatePrompt(text string) {
+	if text == "" {
+		return
+	}
+	s.recent = append(s.recent, text)
+	if len(s.recent) > promptContextSize {
+		s.recent = s.recent[len(s.recent)-promptContextSize:]
+	}
+
+	lang := ""
+	if sess := s.sess.Load(); sess != nil {
+		lang = sessionLanguage(sess.sourceLang)
+	}
+	ts := TranscriptionSettings{
+		Model:    SessionConfig{Model: s.config.Model, Diarize: s.config.Diarize}.transcriptionModel(),
+		Language: lang,
+		Prompt:   livePrompt(s.config.Prompt, strings.Join(s.recent, " ")),
+	}
+	client := s.conn()
+	if client == nil {
+		return
+	}
+	go func() {
+		if err := client.UpdateTranscription(ts); err != nil {
+			slog.Debug("update transcription prompt failed", "error", err)
+		}
+	}()
 }
 
 func (s *Service) handleTranscriptDelta(e TranscriptDeltaEvent) {
@@ -310,6 +591,9 @@ func (s *Service) handleTranscriptDelta(e TranscriptDeltaEvent) {
 	}
 
 	item.SourceText += e.Delta
+	if e.Speaker != "" {
+		item.Speaker = e.Speaker
+	}
 	s.emit(item, s.sess.Load())
 }
 
@@ -372,13 +656,16 @@ func (s *Service) emit(item *itemState, sess *sessionState) {
 		ID:         item.ID,
 		SourceText: item.SourceText,
 		TargetText: item.TargetText,
-		SourceLang: sess.sourceLang,
+		SourceLang: sourceLangOf(item, sess),
 		TargetLang: sess.targetLang,
 		StartTime:  item.StartTime,
 		EndTime:    end,
 		Timestamp:  time.Now().UnixMilli(),
 		IsFinal:    isFinal,
 		Confidence: 1.0,
+		Speaker:    item.Speaker,
+
+		SessionStart: sess.startTime.UnixMilli(),
 	}
 
 	slog.Debug("emit", "data", t)
@@ -390,6 +677,50 @@ func (s *Service) emit(item *itemState, sess *sessionState) {
 	}
 }
 
+// isAutoLang reports whether lang asks for automatic detection.
+func isAutoLang(lang string) bool {
+	return lang == "" || lang == "auto"
+}
+
+// sessionLanguage returns the transcription language hint for the session.
+// Auto mode leaves it empty so the model detects the spoken language.
+func sessionLanguage(sourceLang string) string {
+	if isAutoLang(sourceLang) {
+		return ""
+	}
+	return sourceLang
+}
+
+// sourceLangOf returns the source language to report for item.
+// In auto mode the language is detected from the final transcript;
+// otherwise, or if detection fails, the configured source is used.
+func sourceLangOf(item *itemState, sess *sessionState) string {
+	if !isAutoLang(sess.sourceLang) {
+		return sess.sourceLang
+	}
+	if item.Lang == "" && item.SourceFinal {
+		if code, _ := langdetect.Detect(item.SourceText); code != "auto" {
+			item.Lang = code
+		}
+	}
+	if item.Lang != "" {
+		return item.Lang
+	}
+	return sess.sourceLang
+}
+
+// reportedLang normalizes a language reported by the server, such as
+// "en" or "pt-BR", to a supported code. It returns "" if the server
+// reported none or one we can't translate from, so detection falls back
+// to the transcript text.
+func reportedLang(lang string) string {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+	if isAutoLang(code) || !langdetect.IsSupported(code) {
+		return ""
+	}
+	return code
+}
+
 func (s *Service) sendError(err error) {
 	select {
 	case s.errorChan <- err:
@@ -418,8 +749,10 @@ func (s *Service) Status() types.LiveStatus {
 	var duration int64
 	var sourceLang, targetLang string
 	var count int
+	var sessionStart int64
 
 	if sess != nil {
+		sessionStart = sess.startTime.UnixMilli()
 		if s.running.Load() {
 			duration = int64(time.Since(sess.startTime).Seconds())
 		}
@@ -436,6 +769,7 @@ func (s *Service) Status() types.LiveStatus {
 		Duration:        duration,
 		TranscriptCount: count,
 		VADState:        sess.vadState,
+		SessionStart:    sessionStart,
 	}
 }
 
@@ -0,0 +1,294 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestSourceLangOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		item    itemState
+		want    string
+	}{
+		{"configured source wins", "ja", itemState{SourceText: "Hello, how are you today?", SourceFinal: true}, "ja"},
+		{"auto detects final text", "auto", itemState{SourceText: "Hello, how are you today?", SourceFinal: true}, "en"},
+		{"auto waits for final", "auto", itemState{SourceText: "Hello, how are you today?"}, "auto"},
+		{"auto falls back when undetectable", "auto", itemState{SourceText: "", SourceFinal: true}, "auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := tt.item
+			got := sourceLangOf(&item, &sessionState{sourceLang: tt.session})
+			if got != tt.want {
+				t.Errorf("sourceLangOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportedLanguage(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"de", "de"},
+		{" pt-BR ", "pt"},
+		{"EN", "en"},
+		{"", ""},
+		{"auto", ""},
+		{"xx", ""},
+	}
+	for _, tt := range tests {
+		if got := reportedLang(tt.lang); got != tt.want {
+			t.Errorf("reportedLang(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+
+	// In auto mode the reported language wins over text detection, which
+	// would take this transcript for English.
+	s := newTestService(ServiceConfig{})
+	s.sess.Store(&sessionState{sourceLang: "auto", targetLang: "zh", startTime: time.Now()})
+	c := newFakeConn()
+	go s.runEvents(context.Background(), c)
+
+	c.msgs <- SpeechStartedEvent{ItemID: "a"}
+	c.msgs <- TranscriptEvent{ItemID: "a", Transcript: "Hello, how are you today?", Language: "fr"}
+	c.Close()
+
+	for tr := range s.transcriptChan {
+		if tr.IsFinal {
+			if tr.SourceLang != "fr" {
+				t.Errorf("SourceLang = %q, want %q", tr.SourceLang, "fr")
+			}
+			return
+		}
+	}
+	t.Fatal("no final transcript")
+}
+
+// fakeConn is a conn whose events and errors are fed by the test.
+type fakeConn struct {
+	msgs      chan Event
+	errs      chan error
+	vad       []TurnDetection // Sent by ConfigureVAD
+	closeOnce sync.Once
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{msgs: make(chan Event), errs: make(chan error, 1)}
+}
+
+func (c *fakeConn) Messages() <-chan Event                          { return c.msgs }
+func (c *fakeConn) Errors() <-chan error                            { return c.errs }
+func (c *fakeConn) SendAudio([]float32) error                       { return nil }
+func (c *fakeConn) UpdateTranscription(TranscriptionSettings) error { return nil }
+
+func (c *fakeConn) ConfigureVAD(td TurnDetection) error {
+	c.vad = append(c.vad, td)
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	c.closeOnce.Do(func() { close(c.msgs) })
+	return nil
+}
+
+// newTestService returns a Service ready to run events without a client.
+func newTestService(cfg ServiceConfig) *Service {
+	s := &Service{
+		config:         cfg,
+		transcriptChan: make(chan types.LiveTranscript, 100),
+		vadChan:        make(chan types.VADState, 100),
+		errorChan:      make(chan error, 10),
+		activeItems:    make(map[string]*itemState),
+	}
+	s.sess.Store(&sessionState{sourceLang: "en", targetLang: "zh", startTime: time.Now()})
+	return s
+}
+
+func TestSpeakerLabels(t *testing.T) {
+	s := newTestService(ServiceConfig{})
+	c := newFakeConn()
+	go s.runEvents(context.Background(), c)
+
+	c.msgs <- SpeechStartedEvent{ItemID: "a"}
+	c.msgs <- TranscriptDeltaEvent{ItemID: "a", Delta: "Hi", Speaker: "A"}
+	c.msgs <- TranscriptEvent{ItemID: "a", Transcript: "Hi there"} // Label kept from the delta
+	c.msgs <- SpeechStartedEvent{ItemID: "b"}
+	c.msgs <- TranscriptEvent{ItemID: "b", Transcript: "Hello", Speaker: "B"}
+	c.Close()
+
+	want := map[string]string{"a": "A", "b": "B"}
+	for tr := range s.transcriptChan {
+		if tr.IsFinal && tr.Speaker != want[tr.ID] {
+			t.Errorf("transcript %s speaker = %q, want %q", tr.ID, tr.Speaker, want[tr.ID])
+		}
+		if tr.IsFinal {
+			delete(want, tr.ID)
+		}
+	}
+	if len(want) != 0 {
+		t.Errorf("missing final transcripts for %v", want)
+	}
+}
+
+func TestVADWatchdog(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []Event
+		want   []types.VADState
+	}{
+		{
+			name:   "missing stop resets to listening",
+			events: []Event{SpeechStartedEvent{ItemID: "a"}},
+			want:   []types.VADState{types.VADStateSpeaking, types.VADStateListening},
+		},
+		{
+			name:   "stop received",
+			events: []Event{SpeechStartedEvent{ItemID: "a"}, SpeechStoppedEvent{ItemID: "a"}},
+			want:   []types.VADState{types.VADStateSpeaking, types.VADStateProcessing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(ServiceConfig{MaxSpeaking: 20 * time.Millisecond})
+			c := newFakeConn()
+			go s.runEvents(context.Background(), c)
+
+			for _, e := range tt.events {
+				c.msgs <- e
+			}
+			// Leave time for the watchdog to fire before closing.
+			time.Sleep(100 * time.Millisecond)
+			c.Close()
+
+			var got []types.VADState
+			for state := range s.vadChan {
+				got = append(got, state)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("VAD states = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("VAD states = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestVADWatchdogDisabled(t *testing.T) {
+	s := newTestService(ServiceConfig{})
+	c := newFakeConn()
+	go s.runEvents(context.Background(), c)
+
+	c.msgs <- SpeechStartedEvent{ItemID: "a"}
+	time.Sleep(50 * time.Millisecond)
+	c.Close()
+
+	var got []types.VADState
+	for state := range s.vadChan {
+		got = append(got, state)
+	}
+	if len(got) != 1 || got[0] != types.VADStateSpeaking {
+		t.Errorf("VAD states = %v, want [speaking]", got)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	defer func(d time.Duration) { reconnectBackoff = d }(reconnectBackoff)
+	reconnectBackoff = time.Millisecond
+
+	s := newTestService(ServiceConfig{})
+	first, second := newFakeConn(), newFakeConn()
+	dials := 0
+	s.dial = func(context.Context) (conn, error) {
+		dials++
+		if dials == 1 {
+			return nil, errors.New("network down") // First retry fails
+		}
+		return second, nil
+	}
+	s.setConn(first)
+	s.running.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.runEvents(ctx, first)
+
+	// A partial transcript is cut off by the connection failure.
+	first.msgs <- SpeechStartedEvent{ItemID: "a"}
+	first.msgs <- TranscriptDeltaEvent{ItemID: "a", Delta: "hel"}
+	first.errs <- errors.New("ICE connection failed")
+
+	// Events from the new connection are handled.
+	second.msgs <- SpeechStartedEvent{ItemID: "b"}
+	if got := s.conn(); got != second {
+		t.Errorf("conn() = %v, want the reconnected conn", got)
+	}
+	s.Stop()
+
+	if dials != 2 {
+		t.Errorf("dial called %d times, want 2", dials)
+	}
+	var states []types.VADState
+	for state := range s.vadChan {
+		states = append(states, state)
+	}
+	want := []types.VADState{types.VADStateSpeaking, types.VADStateReconnecting, types.VADStateListening, types.VADStateSpeaking}
+	if len(states) != len(want) {
+		t.Fatalf("VAD states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("VAD states = %v, want %v", states, want)
+		}
+	}
+
+	var partial *types.LiveTranscript
+	for tr := range s.transcriptChan {
+		if tr.ID == "a" && tr.IsFinal {
+			partial = &tr
+		}
+	}
+	if partial == nil || partial.SourceText != "hel" {
+		t.Errorf("cut-off item not finalized, got %+v", partial)
+	}
+	if _, ok := s.activeItems["a"]; ok {
+		t.Error("cut-off item still active after reconnect")
+	}
+}
+
+func TestSetVADConfig(t *testing.T) {
+	s := newTestService(ServiceConfig{})
+
+	// Without a connection the settings are kept for the next one.
+	noisy := types.VADSettings{Mode: types.VADModeServer, Threshold: 0.8, SilenceDurationMs: 900}
+	if err := s.SetVADConfig(noisy); err != nil {
+		t.Fatalf("SetVADConfig() without conn error = %v", err)
+	}
+	if s.vad != noisy {
+		t.Errorf("stored VAD = %+v, want %+v", s.vad, noisy)
+	}
+
+	// A running session is updated in place.
+	c := newFakeConn()
+	s.setConn(c)
+	if err := s.SetVADConfig(types.VADSettings{Eagerness: "low"}); err != nil {
+		t.Fatalf("SetVADConfig() error = %v", err)
+	}
+	want := TurnDetection{Type: VADTypeSemanticVAD, Eagerness: VADEagernessLow}
+	if len(c.vad) != 1 || c.vad[0] != want {
+		t.Errorf("sent turn detection = %+v, want [%+v]", c.vad, want)
+	}
+}
@@ -3,15 +3,21 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/realtime"
+
+	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/transport"
 )
 
 const (
@@ -25,68 +31,175 @@ type SessionToken struct {
 	ExpiresAt int64
 }
 
-// httpClient is a package-level client with connection reuse.
+// httpClient is a package-level client with connection reuse, used for
+// session creation and the SDP exchange. It follows the app's proxy setting.
 var httpClient = &http.Client{
-	Timeout: 30 * time.Second,
+	Transport: transport.New(),
+	Timeout:   30 * time.Second,
 }
 
+// DiarizeModel is the transcription model that labels speakers.
+const DiarizeModel = "gpt-4o-transcribe-diarize"
+
 // SessionConfig holds configuration for creating a transcription session.
 type SessionConfig struct {
-	Model    string // Transcription model, e.g. "gpt-4o-transcribe-diarize"
-	Language string // Language code, e.g. "en"
-	Prompt   string // Optional transcription prompt
+	OrgID     string // Optional OpenAI-Organization header
+	ProjectID string // Optional OpenAI-Project header
+	Model     string // Transcription model, e.g. "gpt-4o-transcribe"
+	Diarize   bool   // Use DiarizeModel instead of Model, labelling speakers
+	Language  string // Language code, e.g. "en"; empty lets the model detect it
+	Prompt    string // Optional transcription prompt; see livePrompt
+
+	VAD types.VADSettings // Voice activity detection; zero uses semantic VAD
+}
+
+// transcriptionModel returns the model that transcribes the session.
+func (cfg SessionConfig) transcriptionModel() string {
+	switch {
+	case cfg.Diarize:
+		return DiarizeModel
+	case cfg.Model != "":
+		return cfg.Model
+	default:
+		return string(realtime.AudioTranscriptionModelGPT4oTranscribe)
+	}
 }
 
 // CreateSession creates a new ephemeral WebRTC transcription session token.
 func CreateSession(ctx context.Context, apiKey string, cfg SessionConfig) (*SessionToken, error) {
-	language := cfg.Language
-	if language == "" {
-		language = "en"
-	}
-	model := cfg.Model
-	if model == "" {
-		model = string(realtime.AudioTranscriptionModelGPT4oTranscribe)
+	client := openai.NewClient(clientOptions(apiKey, cfg)...)
+	resp, err := client.Realtime.ClientSecrets.New(ctx, buildSessionParams(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("create client secret: %w", err)
 	}
 
-	client := openai.NewClient(option.WithAPIKey(apiKey))
+	return &SessionToken{
+		Value:     resp.Value,
+		ExpiresAt: resp.ExpiresAt,
+	}, nil
+}
 
+// buildSessionParams returns the client secret request for a transcription
+// session configured by cfg.
+func buildSessionParams(cfg SessionConfig) realtime.ClientSecretNewParams {
 	transcription := realtime.AudioTranscriptionParam{
-		Model:    realtime.AudioTranscriptionModel(model),
-		Language: openai.String(language),
+		Model: realtime.AudioTranscriptionModel(cfg.transcriptionModel()),
+	}
+	if cfg.Language != "" {
+		transcription.Language = openai.String(cfg.Language)
 	}
 	if cfg.Prompt != "" {
 		transcription.Prompt = openai.String(cfg.Prompt)
 	}
 
-	params := realtime.ClientSecretNewParams{
+	return realtime.ClientSecretNewParams{
 		Session: realtime.ClientSecretNewParamsSessionUnion{
 			OfTranscription: &realtime.RealtimeTranscriptionSessionCreateRequestParam{
 				Audio: realtime.RealtimeTranscriptionSessionAudioParam{
 					Input: realtime.RealtimeTranscriptionSessionAudioInputParam{
-						TurnDetection: realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionUnionParam{
-							OfSemanticVad: &realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionSemanticVadParam{
-								Type:      "semantic_vad",
-								Eagerness: string(VADEagernessHigh),
-							},
-						},
+						TurnDetection: turnDetectionParam(turnDetection(cfg.VAD)),
 						Transcription: transcription,
 					},
 				},
 			},
 		},
 	}
-	resp, err := client.Realtime.ClientSecrets.New(ctx, params)
-	if err != nil {
-		return nil, fmt.Errorf("create client secret: %w", err)
+}
+
+// livePrompt combines the user's biasing phrases with recent transcript
+// context into a transcription prompt of at most types.MaxSpeechPromptRunes.
+// Phrases take priority; the oldest context is dropped first.
+func livePrompt(phrases, recent string) string {
+	phrases = strings.TrimSpace(phrases)
+	recent = strings.TrimSpace(recent)
+	if r := []rune(phrases); len(r) > types.MaxSpeechPromptRunes {
+		phrases = string(r[:types.MaxSpeechPromptRunes])
+	}
+	if recent == "" {
+		return phrases
 	}
 
-	return &SessionToken{
-		Value:     resp.Value,
-		ExpiresAt: resp.ExpiresAt,
-	}, nil
+	budget := types.MaxSpeechPromptRunes - utf8.RuneCountInString(phrases)
+	if phrases != "" {
+		budget-- // Separator
+	}
+	if budget <= 0 {
+		return phrases
+	}
+	if r := []rune(recent); len(r) > budget {
+		recent = string(r[len(r)-budget:])
+	}
+	if phrases == "" {
+		return recent
+	}
+	return phrases + "\n" + recent
+}
+
+// clientOptions returns the API client options for apiKey and cfg.
+func clientOptions(apiKey string, cfg SessionConfig) []option.RequestOption {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey), option.WithHTTPClient(httpClient)}
+	if cfg.OrgID != "" {
+		opts = append(opts, option.WithOrganization(cfg.OrgID))
+	}
+	if cfg.ProjectID != "" {
+		opts = append(opts, option.WithProject(cfg.ProjectID))
+	}
+	return opts
+}
+
+// SDP exchange retry policy. sdpBackoff doubles after each failed attempt.
+const sdpAttempts = 3
+
+var sdpBackoff = 500 * time.Millisecond
+
+// SDPError is a failed SDP exchange response. Code and Message come from
+// the OpenAI JSON error body when one was returned.
+type SDPError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *SDPError) Error() string {
+	switch {
+	case e.Code != "" && e.Message != "":
+		return fmt.Sprintf("API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	case e.Message != "":
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+	default:
+		return fmt.Sprintf("API error (status %d)", e.StatusCode)
+	}
+}
+
+// Auth reports whether the key was rejected. Such errors are not retried.
+func (e *SDPError) Auth() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// retryable reports whether the request may succeed if sent again.
+func (e *SDPError) retryable() bool {
+	return e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode >= 500
+}
+
+// parseSDPError builds an SDPError from a non-success response body.
+func parseSDPError(status int, body []byte) *SDPError {
+	var payload struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error.Message != "" {
+		return &SDPError{StatusCode: status, Code: payload.Error.Code, Message: payload.Error.Message}
+	}
+	return &SDPError{StatusCode: status, Message: strings.TrimSpace(string(body))}
 }
 
 // ExchangeSDP sends the local SDP offer to OpenAI and receives the SDP answer.
+// Network errors and 408, 429 and 5xx responses are retried with backoff;
+// other failures are returned as *SDPError.
 func ExchangeSDP(ctx context.Context, offer, ephemeralKey string) (string, error) {
 	if slog.Default().Enabled(ctx, slog.LevelDebug) {
 		keyPreview := ephemeralKey
@@ -95,10 +208,36 @@ func ExchangeSDP(ctx context.Context, offer, ephemeralKey string) (string, error
 		}
 		slog.Debug("ExchangeSDP", "ephemeralKeyLen", len(ephemeralKey), "keyPreview", keyPreview)
 	}
+	return exchangeSDP(ctx, RealtimeEndpoint, offer, ephemeralKey)
+}
+
+func exchangeSDP(ctx context.Context, endpoint, offer, ephemeralKey string) (string, error) {
+	delay := sdpBackoff
+	for attempt := 1; ; attempt++ {
+		answer, retry, err := postSDP(ctx, endpoint, offer, ephemeralKey)
+		if err == nil {
+			return answer, nil
+		}
+		if !retry || attempt == sdpAttempts {
+			return "", err
+		}
+
+		slog.Warn("SDP exchange failed, retrying", "attempt", attempt, "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, RealtimeEndpoint, bytes.NewBufferString(offer))
+// postSDP performs a single SDP exchange and reports whether a failure is
+// worth retrying.
+func postSDP(ctx context.Context, endpoint, offer, ephemeralKey string) (answer string, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBufferString(offer))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return "", false, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+ephemeralKey)
@@ -106,19 +245,20 @@ func ExchangeSDP(ctx context.Context, offer, ephemeralKey string) (string, error
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("execute request: %w", err)
+		return "", ctx.Err() == nil, fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return "", ctx.Err() == nil, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		slog.Error("SDP exchange failed", "status", resp.StatusCode, "body", string(body))
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, body)
+		apiErr := parseSDPError(resp.StatusCode, body)
+		return "", apiErr.retryable(), apiErr
 	}
 
-	return string(body), nil
+	return string(body), false, nil
 }
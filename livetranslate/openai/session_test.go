@@ -0,0 +1,236 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/realtime"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestClientOptionsScopeHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         SessionConfig
+		wantOrg     string
+		wantProject string
+	}{
+		{"unset", SessionConfig{}, "", ""},
+		{"org and project", SessionConfig{OrgID: "org-1", ProjectID: "proj-1"}, "org-1", "proj-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"value":"ek","expires_at":1}`))
+			}))
+			defer srv.Close()
+
+			opts := append(clientOptions("key", tt.cfg), option.WithBaseURL(srv.URL), option.WithMaxRetries(0))
+			client := openai.NewClient(opts...)
+			if _, err := client.Realtime.ClientSecrets.New(context.Background(), realtime.ClientSecretNewParams{}); err != nil {
+				t.Fatalf("ClientSecrets.New() error = %v", err)
+			}
+
+			if v := got.Get("OpenAI-Organization"); v != tt.wantOrg {
+				t.Errorf("OpenAI-Organization = %q, want %q", v, tt.wantOrg)
+			}
+			if v := got.Get("OpenAI-Project"); v != tt.wantProject {
+				t.Errorf("OpenAI-Project = %q, want %q", v, tt.wantProject)
+			}
+		})
+	}
+}
+
+func TestBuildSessionParamsPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+	}{
+		{"unset", ""},
+		{"bias phrases", "Kubernetes, gRPC, Ana Souza"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := buildSessionParams(SessionConfig{Language: "en", Prompt: tt.prompt})
+			tr := params.Session.OfTranscription.Audio.Input.Transcription
+
+			if got := tr.Prompt.Value; got != tt.prompt {
+				t.Errorf("Prompt = %q, want %q", got, tt.prompt)
+			}
+			if tr.Prompt.Valid() != (tt.prompt != "") {
+				t.Errorf("Prompt set = %v, want %v", tr.Prompt.Valid(), tt.prompt != "")
+			}
+			if tr.Model != realtime.AudioTranscriptionModelGPT4oTranscribe {
+				t.Errorf("Model = %q, want default", tr.Model)
+			}
+		})
+	}
+}
+
+func TestBuildSessionParamsModel(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SessionConfig
+		want string
+	}{
+		{"default", SessionConfig{}, string(realtime.AudioTranscriptionModelGPT4oTranscribe)},
+		{"explicit", SessionConfig{Model: "gpt-4o-mini-transcribe"}, "gpt-4o-mini-transcribe"},
+		{"diarize", SessionConfig{Model: "gpt-4o-mini-transcribe", Diarize: true}, DiarizeModel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := buildSessionParams(tt.cfg)
+			if got := params.Session.OfTranscription.Audio.Input.Transcription.Model; string(got) != tt.want {
+				t.Errorf("Model = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLivePrompt(t *testing.T) {
+	long := strings.Repeat("a", types.MaxSpeechPromptRunes+10)
+	tests := []struct {
+		name    string
+		phrases string
+		recent  string
+		want    string
+	}{
+		{"empty", "", "", ""},
+		{"phrases only", " gRPC ", "", "gRPC"},
+		{"context only", "", "we deployed it", "we deployed it"},
+		{"both", "gRPC", "we deployed it", "gRPC\nwe deployed it"},
+		{"phrases truncated", long, "dropped", long[:types.MaxSpeechPromptRunes]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := livePrompt(tt.phrases, tt.recent); got != tt.want {
+				t.Errorf("livePrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Context keeps its most recent end when over budget.
+	got := livePrompt("terms", long+"END")
+	if n := utf8.RuneCountInString(got); n != types.MaxSpeechPromptRunes {
+		t.Errorf("len(livePrompt()) = %d, want %d", n, types.MaxSpeechPromptRunes)
+	}
+	if !strings.HasPrefix(got, "terms\n") || !strings.HasSuffix(got, "END") {
+		t.Errorf("livePrompt() should keep phrases and newest context, got %q...", got[:20])
+	}
+}
+
+func TestExchangeSDPRetry(t *testing.T) {
+	defer func(d time.Duration) { sdpBackoff = d }(sdpBackoff)
+	sdpBackoff = time.Millisecond
+
+	const authBody = `{"error":{"message":"Invalid ephemeral key","type":"invalid_request_error","code":"invalid_api_key"}}`
+
+	type reply struct {
+		status int
+		body   string
+	}
+	tests := []struct {
+		name      string
+		replies   []reply
+		want      string
+		wantCalls int
+		wantErr   string
+		wantAuth  bool
+	}{
+		{
+			name:      "retries 503 then succeeds",
+			replies:   []reply{{http.StatusServiceUnavailable, "busy"}, {http.StatusCreated, "v=0 answer"}},
+			want:      "v=0 answer",
+			wantCalls: 2,
+		},
+		{
+			name:      "auth failure not retried",
+			replies:   []reply{{http.StatusUnauthorized, authBody}},
+			wantCalls: 1,
+			wantErr:   "API error (status 401, invalid_api_key): Invalid ephemeral key",
+			wantAuth:  true,
+		},
+		{
+			name:      "bad request not retried",
+			replies:   []reply{{http.StatusBadRequest, "bad offer"}},
+			wantCalls: 1,
+			wantErr:   "API error (status 400): bad offer",
+		},
+		{
+			name:      "gives up after max attempts",
+			replies:   []reply{{http.StatusBadGateway, ""}},
+			wantCalls: sdpAttempts,
+			wantErr:   "API error (status 502)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				rep := tt.replies[min(calls, len(tt.replies)-1)]
+				calls++
+				w.WriteHeader(rep.status)
+				w.Write([]byte(rep.body))
+			}))
+			defer srv.Close()
+
+			got, err := exchangeSDP(context.Background(), srv.URL, "v=0 offer", "ek")
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("exchangeSDP() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("exchangeSDP() = %q, want %q", got, tt.want)
+				}
+				return
+			}
+
+			var apiErr *SDPError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("exchangeSDP() error = %v, want *SDPError", err)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("exchangeSDP() error = %q, want %q", err, tt.wantErr)
+			}
+			if apiErr.Auth() != tt.wantAuth {
+				t.Errorf("Auth() = %v, want %v", apiErr.Auth(), tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestExchangeSDPContextCanceled(t *testing.T) {
+	defer func(d time.Duration) { sdpBackoff = d }(sdpBackoff)
+	sdpBackoff = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	if _, err := exchangeSDP(ctx, srv.URL, "v=0 offer", "ek"); !errors.Is(err, context.Canceled) {
+		t.Errorf("exchangeSDP() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,52 @@
+package openai
+
+import (
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/realtime"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// turnDetection converts VAD settings to the API's turn detection. The
+// zero value selects semantic VAD with high eagerness, so segments end
+// promptly for captions.
+func turnDetection(v types.VADSettings) TurnDetection {
+	if v.Mode == types.VADModeServer {
+		return TurnDetection{
+			Type:              VADTypeServerVAD,
+			Threshold:         v.Threshold,
+			PrefixPaddingMs:   v.PrefixPaddingMs,
+			SilenceDurationMs: v.SilenceDurationMs,
+		}
+	}
+	eagerness := VADEagerness(v.Eagerness)
+	if eagerness == "" {
+		eagerness = VADEagernessHigh
+	}
+	return TurnDetection{Type: VADTypeSemanticVAD, Eagerness: eagerness}
+}
+
+// turnDetectionParam converts td to the client secret request parameter.
+// Zero server VAD fields are left for the API to default.
+func turnDetectionParam(td TurnDetection) realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionUnionParam {
+	if td.Type != VADTypeServerVAD {
+		return realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionUnionParam{
+			OfSemanticVad: &realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionSemanticVadParam{
+				Type:      "semantic_vad",
+				Eagerness: string(td.Eagerness),
+			},
+		}
+	}
+
+	p := &realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionServerVadParam{}
+	if td.Threshold > 0 {
+		p.Threshold = openai.Float(td.Threshold)
+	}
+	if td.PrefixPaddingMs > 0 {
+		p.PrefixPaddingMs = openai.Int(int64(td.PrefixPaddingMs))
+	}
+	if td.SilenceDurationMs > 0 {
+		p.SilenceDurationMs = openai.Int(int64(td.SilenceDurationMs))
+	}
+	return realtime.RealtimeTranscriptionSessionAudioInputTurnDetectionUnionParam{OfServerVad: p}
+}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestTurnDetection(t *testing.T) {
+	tests := []struct {
+		name string
+		v    types.VADSettings
+		want TurnDetection
+	}{
+		{"default", types.VADSettings{}, TurnDetection{Type: VADTypeSemanticVAD, Eagerness: VADEagernessHigh}},
+		{"semantic low", types.VADSettings{Mode: types.VADModeSemantic, Eagerness: "low"}, TurnDetection{Type: VADTypeSemanticVAD, Eagerness: VADEagernessLow}},
+		{
+			"server",
+			types.VADSettings{Mode: types.VADModeServer, Eagerness: "low", Threshold: 0.7, PrefixPaddingMs: 300, SilenceDurationMs: 800},
+			TurnDetection{Type: VADTypeServerVAD, Threshold: 0.7, PrefixPaddingMs: 300, SilenceDurationMs: 800},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := turnDetection(tt.v); got != tt.want {
+				t.Errorf("turnDetection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSessionParamsVAD(t *testing.T) {
+	tests := []struct {
+		name string
+		vad  types.VADSettings
+		want string // turn_detection JSON
+	}{
+		{"default", types.VADSettings{}, `{"eagerness":"high","type":"semantic_vad"}`},
+		{
+			"noisy room",
+			types.VADSettings{Mode: types.VADModeServer, Threshold: 0.85, SilenceDurationMs: 1200},
+			`{"silence_duration_ms":1200,"threshold":0.85,"type":"server_vad"}`,
+		},
+		{"server defaults", types.VADSettings{Mode: types.VADModeServer}, `{"type":"server_vad"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := buildSessionParams(SessionConfig{VAD: tt.vad})
+			got, err := json.Marshal(params.Session.OfTranscription.Audio.Input.TurnDetection)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("turn_detection = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -283,8 +283,30 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// ConfigureVAD sends a session.update to configure voice activity detection.
+// ConfigureVAD sends a session.update replacing the voice activity
+// detection settings.
 func (c *Client) ConfigureVAD(td TurnDetection) error {
+	msg := TurnDetectionUpdate{Type: "session.update"}
+	msg.Session.Type = "transcription"
+	msg.Session.Audio.Input.TurnDetection = td
+
+	slog.Debug("sending session.update", "turn_detection", td)
+	return c.sendEvent(msg)
+}
+
+// UpdateTranscription sends a session.update replacing the transcription
+// settings, e.g. to refresh the prompt mid-session.
+func (c *Client) UpdateTranscription(ts TranscriptionSettings) error {
+	msg := TranscriptionUpdate{Type: "session.update"}
+	msg.Session.Type = "transcription"
+	msg.Session.Audio.Input.Transcription = ts
+
+	slog.Debug("sending session.update", "transcription_prompt_len", len(ts.Prompt))
+	return c.sendEvent(msg)
+}
+
+// sendEvent marshals v and sends it over the data channel.
+func (c *Client) sendEvent(v any) error {
 	c.mu.Lock()
 	if c.closed {
 		c.mu.Unlock()
@@ -297,14 +319,9 @@ func (c *Client) ConfigureVAD(td TurnDetection) error {
 		return ErrNotReady
 	}
 
-	msg := SessionUpdate{Type: "session.update"}
-	msg.Session.TurnDetection = &td
-
-	data, err := json.Marshal(msg)
+	data, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("marshal session update: %w", err)
 	}
-
-	slog.Debug("sending session.update", "turn_detection", td)
 	return dc.SendText(string(data))
 }
@@ -0,0 +1,186 @@
+package livetranslate
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// DefaultProvider is the name of the built-in OpenAI Realtime provider.
+const DefaultProvider = "openai"
+
+// Provider creates LiveTranslators for one speech backend.
+//
+// Name must be stable and unique within a Registry; it is what users store
+// in their config to prefer the provider. New is called once per live
+// session and must return a translator that has not been started yet.
+type Provider interface {
+	Name() string
+	New(cfg Config) (types.LiveTranslator, error)
+}
+
+// Describer is implemented by providers that can report their display
+// name and readiness for a config. Providers without it are described by
+// name only and assumed ready.
+type Describer interface {
+	Info(cfg Config) types.STTProviderInfo
+}
+
+// Local is implemented by providers that run entirely on the machine, such
+// as on-device speech recognition. Providers without it are taken to need
+// the network and are hidden in offline mode.
+type Local interface {
+	Local() bool
+}
+
+// IsLocal reports whether p runs without the network.
+func IsLocal(p Provider) bool {
+	l, ok := p.(Local)
+	return ok && l.Local()
+}
+
+// ErrNoLocalProvider is returned by Select in offline mode when no local
+// provider is registered.
+var ErrNoLocalProvider = errors.New("livetranslate: offline mode needs a local provider, none is registered")
+
+// Describe returns display information for p under cfg.
+func Describe(p Provider, cfg Config) types.STTProviderInfo {
+	if d, ok := p.(Describer); ok {
+		return d.Info(cfg)
+	}
+	return types.STTProviderInfo{
+		Name:          p.Name(),
+		DisplayName:   p.Name(),
+		SetupProgress: -1,
+		IsReady:       true,
+	}
+}
+
+// Registry holds the available providers in registration order.
+// Safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+	order     []string
+	offline   bool // Hide providers that aren't Local
+}
+
+// NewRegistry creates a Registry with the built-in providers registered.
+func NewRegistry() *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	_ = r.Register(openaiProvider{})
+	return r
+}
+
+// Register adds p to the registry. Names must be unique.
+func (r *Registry) Register(p Provider) error {
+	if p == nil || p.Name() == "" {
+		return errors.New("livetranslate: provider name required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := p.Name()
+	if _, ok := r.providers[name]; ok {
+		return fmt.Errorf("livetranslate: provider %q already registered", name)
+	}
+	r.providers[name] = p
+	r.order = append(r.order, name)
+	return nil
+}
+
+// SetOffline sets whether providers that need the network are hidden from
+// Get, Names and Select.
+func (r *Registry) SetOffline(offline bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offline = offline
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[name]
+	if ok && r.offline && !IsLocal(p) {
+		return nil, false
+	}
+	return p, ok
+}
+
+// Names returns the registered provider names in registration order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.offline {
+		return append([]string(nil), r.order...)
+	}
+	var names []string
+	for _, name := range r.order {
+		if IsLocal(r.providers[name]) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Select returns the preferred provider if it is registered, otherwise
+// the default provider. In offline mode the fallback is the first local
+// provider instead.
+func (r *Registry) Select(preferred string) (Provider, error) {
+	if preferred != "" {
+		if p, ok := r.Get(preferred); ok {
+			return p, nil
+		}
+	}
+	if p, ok := r.Get(DefaultProvider); ok {
+		return p, nil
+	}
+	if names := r.Names(); len(names) > 0 {
+		if p, ok := r.Get(names[0]); ok {
+			return p, nil
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.offline {
+		return nil, ErrNoLocalProvider
+	}
+	return nil, errors.New("livetranslate: no provider available")
+}
+
+// Effective describes the provider Select would choose for preferred,
+// using the same fallback. It fails if no provider is available or the
+// chosen one is not ready under cfg; the description is returned either way
+// when a provider was found.
+func (r *Registry) Effective(preferred string, cfg Config) (types.STTProviderInfo, error) {
+	p, err := r.Select(preferred)
+	if err != nil {
+		return types.STTProviderInfo{}, err
+	}
+	info := Describe(p, cfg)
+	if !info.IsReady {
+		return info, fmt.Errorf("livetranslate: provider %q is not ready", info.Name)
+	}
+	return info, nil
+}
+
+// openaiProvider is the built-in OpenAI Realtime provider.
+type openaiProvider struct{}
+
+func (openaiProvider) Name() string { return DefaultProvider }
+
+func (openaiProvider) New(cfg Config) (types.LiveTranslator, error) { return New(cfg) }
+
+func (openaiProvider) Info(cfg Config) types.STTProviderInfo {
+	return types.STTProviderInfo{
+		Name:          DefaultProvider,
+		DisplayName:   "OpenAI Realtime",
+		RequiresSetup: cfg.APIKey == "",
+		SetupProgress: -1,
+		IsReady:       cfg.APIKey != "",
+	}
+}
@@ -0,0 +1,193 @@
+package livetranslate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// fakeTranslator is a no-op LiveTranslator.
+type fakeTranslator struct{ types.LiveTranslator }
+
+// fakeProvider is an external provider used to exercise the registry.
+type fakeProvider struct {
+	name string
+	cfg  Config
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) New(cfg Config) (types.LiveTranslator, error) {
+	p.cfg = cfg
+	return fakeTranslator{}, nil
+}
+
+func TestRegistrySelect(t *testing.T) {
+	r := NewRegistry()
+	fake := &fakeProvider{name: "local-whisper"}
+	if err := r.Register(fake); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if got, want := r.Names(), []string{DefaultProvider, "local-whisper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		preferred string
+		want      string
+	}{
+		{"local-whisper", "local-whisper"},
+		{"", DefaultProvider},
+		{"missing", DefaultProvider},
+	}
+	for _, tt := range tests {
+		p, err := r.Select(tt.preferred)
+		if err != nil {
+			t.Fatalf("Select(%q) error = %v", tt.preferred, err)
+		}
+		if p.Name() != tt.want {
+			t.Errorf("Select(%q) = %q, want %q", tt.preferred, p.Name(), tt.want)
+		}
+	}
+
+	p, _ := r.Select("local-whisper")
+	if _, err := p.New(Config{Model: "tiny"}); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if fake.cfg.Model != "tiny" {
+		t.Errorf("provider got model %q, want %q", fake.cfg.Model, "tiny")
+	}
+}
+
+func TestRegistryRegisterErrors(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(&fakeProvider{name: DefaultProvider}); err == nil {
+		t.Error("Register() duplicate name: want error")
+	}
+	if err := r.Register(&fakeProvider{}); err == nil {
+		t.Error("Register() empty name: want error")
+	}
+	if err := r.Register(nil); err == nil {
+		t.Error("Register(nil): want error")
+	}
+}
+
+// describedProvider reports readiness like a local engine needing a model.
+type describedProvider struct {
+	fakeProvider
+	ready bool
+}
+
+func (p *describedProvider) Info(Config) types.STTProviderInfo {
+	return types.STTProviderInfo{Name: p.name, DisplayName: "macOS Speech", IsLocal: true, IsReady: p.ready}
+}
+
+func TestRegistryEffective(t *testing.T) {
+	withKey := Config{APIKey: "sk-test"}
+
+	tests := []struct {
+		name      string
+		register  []Provider
+		preferred string
+		cfg       Config
+		want      string
+		wantLocal bool
+		wantErr   bool
+	}{
+		{name: "default ready", cfg: withKey, want: DefaultProvider},
+		{name: "default without key", want: DefaultProvider, wantErr: true},
+		{name: "missing preference falls back", preferred: "gone", cfg: withKey, want: DefaultProvider},
+		{
+			name:      "preferred local",
+			register:  []Provider{&describedProvider{fakeProvider{name: "speech"}, true}},
+			preferred: "speech",
+			want:      "speech",
+			wantLocal: true,
+		},
+		{
+			name:      "preferred not ready",
+			register:  []Provider{&describedProvider{fakeProvider{name: "speech"}, false}},
+			preferred: "speech",
+			cfg:       withKey,
+			want:      "speech",
+			wantLocal: true,
+			wantErr:   true,
+		},
+		{
+			name:      "plain provider assumed ready",
+			register:  []Provider{&fakeProvider{name: "custom"}},
+			preferred: "custom",
+			want:      "custom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for _, p := range tt.register {
+				if err := r.Register(p); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+			}
+
+			info, err := r.Effective(tt.preferred, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Effective() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if info.Name != tt.want || info.IsLocal != tt.wantLocal {
+				t.Errorf("Effective() = %+v, want %q (local %v)", info, tt.want, tt.wantLocal)
+			}
+			if info.IsReady == tt.wantErr {
+				t.Errorf("Effective().IsReady = %v with err = %v", info.IsReady, err)
+			}
+		})
+	}
+}
+
+// localProvider is an on-device provider.
+type localProvider struct{ fakeProvider }
+
+func (*localProvider) Local() bool { return true }
+
+func TestRegistryOffline(t *testing.T) {
+	r := NewRegistry()
+	r.SetOffline(true)
+
+	if got := r.Names(); len(got) != 0 {
+		t.Errorf("Names() offline = %v, want none", got)
+	}
+	if _, ok := r.Get(DefaultProvider); ok {
+		t.Error("Get() offline returned the network provider")
+	}
+	if _, err := r.Select(DefaultProvider); !errors.Is(err, ErrNoLocalProvider) {
+		t.Errorf("Select() offline without local provider error = %v, want ErrNoLocalProvider", err)
+	}
+
+	if err := r.Register(&fakeProvider{name: "cloud"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := r.Register(&localProvider{fakeProvider{name: "on-device"}}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if got, want := r.Names(), []string{"on-device"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() offline = %v, want %v", got, want)
+	}
+	for _, preferred := range []string{"", DefaultProvider, "cloud", "on-device"} {
+		p, err := r.Select(preferred)
+		if err != nil {
+			t.Fatalf("Select(%q) error = %v", preferred, err)
+		}
+		if p.Name() != "on-device" {
+			t.Errorf("Select(%q) offline = %q, want on-device", preferred, p.Name())
+		}
+	}
+
+	r.SetOffline(false)
+	if got, want := r.Names(), []string{DefaultProvider, "cloud", "on-device"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() online = %v, want %v", got, want)
+	}
+}
@@ -132,7 +132,7 @@ func (c *claudeCompleter) Complete(ctx context.Context, messages []Message) (str
 		return "", types.Usage{}, err
 	}
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, false)
 	if err != nil {
 		return "", types.Usage{}, fmt.Errorf("do request: %w", err)
 	}
@@ -145,11 +145,14 @@ func (c *claudeCompleter) Complete(ctx context.Context, messages []Message) (str
 
 	var claudeResp claudeResponse
 	if err := json.Unmarshal(body, &claudeResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", types.Usage{}, newAPIError(resp, string(body))
+		}
 		return "", types.Usage{}, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if claudeResp.Error != nil {
-		return "", types.Usage{}, fmt.Errorf("api error: %s - %s", claudeResp.Error.Type, claudeResp.Error.Message)
+		return "", types.Usage{}, newAPIError(resp, claudeResp.Error.Type+" - "+claudeResp.Error.Message)
 	}
 
 	if len(claudeResp.Content) == 0 {
@@ -173,7 +176,7 @@ func (c *claudeCompleter) StreamComplete(ctx context.Context, messages []Message
 		return nil, err
 	}
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, true)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -181,7 +184,7 @@ func (c *claudeCompleter) StreamComplete(ctx context.Context, messages []Message
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("api error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, string(body))
 	}
 
 	ch := make(chan StreamDelta, 16)
@@ -223,18 +226,17 @@ func (c *claudeCompleter) StreamComplete(ctx context.Context, messages []Message
 					}
 				}
 			case "message_stop":
-				select {
-				case ch <- StreamDelta{Done: true, Usage: usage}:
-				case <-ctx.Done():
-				}
+				endStream(ctx, ch, usage, nil)
 				return
 			}
 		}
 
-		select {
-		case ch <- StreamDelta{Done: true, Usage: usage}:
-		case <-ctx.Done():
+		// The stream must end with message_stop; anything else was cut short.
+		err := scanner.Err()
+		if err == nil {
+			err = io.ErrUnexpectedEOF
 		}
+		endStream(ctx, ch, usage, err)
 	}()
 
 	return ch, nil
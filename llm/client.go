@@ -2,10 +2,16 @@
 package llm
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"go.aimuz.me/transy/internal/types"
+	"go.aimuz.me/transy/transport"
 )
 
 // Message represents a chat message.
@@ -19,6 +25,31 @@ type Options struct {
 	MaxTokens       int
 	Temperature     float64
 	DisableThinking bool // For Gemini: set thinkingBudget to 0
+
+	// OmitStreamOptions drops stream_options for OpenAI-compatible gateways
+	// that reject it. Streamed usage is then reported as zero.
+	OmitStreamOptions bool
+
+	// OpenAI organization and project headers; ignored by other providers.
+	OrgID     string
+	ProjectID string
+
+	// APIVersion is the Azure OpenAI api-version query parameter, e.g.
+	// "2024-10-21"; ignored by other providers.
+	APIVersion string
+
+	// Timeout bounds each request attempt; for streams only the wait for
+	// the response to start. Zero selects DefaultTimeout, negative disables.
+	Timeout time.Duration
+
+	// MaxRetries is how often a request is retried after a 429 or 5xx
+	// response or a timeout. Zero selects DefaultMaxRetries, negative
+	// disables retries.
+	MaxRetries int
+
+	// Debug logs each request and response body at debug level, with API
+	// keys redacted from headers and query parameters.
+	Debug bool
 }
 
 // Completer performs chat completions.
@@ -26,11 +57,63 @@ type Completer interface {
 	Complete(ctx context.Context, messages []Message) (string, types.Usage, error)
 }
 
+// ErrStreamTruncated indicates a stream ended before the provider signalled
+// completion, e.g. because the connection dropped.
+var ErrStreamTruncated = errors.New("stream truncated")
+
+// requestIDHeaders are the response headers providers use for the request
+// ID that support asks for: OpenAI and compatible gateways send
+// x-request-id, Anthropic sends request-id.
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id"}
+
+// APIError is an error response from a provider. Match it with errors.As to
+// get the request ID for a support ticket.
+type APIError struct {
+	StatusCode int
+	Message    string // Provider error message, or the raw response body
+	RequestID  string // Empty if the provider sent none
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api error: %d - %s", e.StatusCode, e.Message)
+	if e.RequestID != "" {
+		msg += " (request id: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// newAPIError returns the error for a failed response with the given message.
+func newAPIError(resp *http.Response, message string) *APIError {
+	e := &APIError{StatusCode: resp.StatusCode, Message: message}
+	for _, h := range requestIDHeaders {
+		if id := resp.Header.Get(h); id != "" {
+			e.RequestID = id
+			break
+		}
+	}
+	slog.Debug("llm api error", "status", e.StatusCode, "request_id", e.RequestID)
+	return e
+}
+
 // StreamDelta represents a streaming chunk from LLM.
 type StreamDelta struct {
 	Text  string      // Incremental text content
 	Done  bool        // True if this is the final chunk
 	Usage types.Usage // Populated only when Done is true
+	Err   error       // Set on the final chunk if the stream broke off; wraps ErrStreamTruncated
+}
+
+// endStream sends the final delta: Done on a clean finish, or Done with Err
+// if the stream broke off with err.
+func endStream(ctx context.Context, ch chan<- StreamDelta, usage types.Usage, err error) {
+	delta := StreamDelta{Done: true, Usage: usage}
+	if err != nil {
+		delta.Err = fmt.Errorf("%w: %w", ErrStreamTruncated, err)
+	}
+	select {
+	case ch <- delta:
+	case <-ctx.Done():
+	}
 }
 
 // StreamCompleter performs streaming chat completions.
@@ -38,28 +121,59 @@ type StreamCompleter interface {
 	StreamComplete(ctx context.Context, messages []Message) (<-chan StreamDelta, error)
 }
 
+// httpClient is shared by all completers so connections, and their TLS
+// sessions, are reused across translations and profiles. Its transport
+// follows the app's proxy setting. There is no client timeout because
+// responses may stream; requests are bounded by their context.
+var httpClient = &http.Client{Transport: newTransport()}
+
+// newTransport returns the transport used for LLM API calls.
+func newTransport() *http.Transport {
+	t := transport.New()
+	t.MaxIdleConns = 64
+	t.MaxIdleConnsPerHost = 8 // Default of 2 forces reconnects under concurrent translations
+	t.IdleConnTimeout = 90 * time.Second
+	return t
+}
+
 // completerConfig holds all parameters needed by completers.
 // Memory layout optimized: pointers/slices first, then 64-bit, then smaller.
 type completerConfig struct {
-	http            *http.Client
-	apiKey          string
-	baseURL         string
-	model           string
-	maxTokens       int
-	temperature     float64
-	disableThinking bool
+	http              *http.Client
+	apiKey            string
+	baseURL           string
+	model             string
+	maxTokens         int
+	temperature       float64
+	disableThinking   bool
+	omitStreamOptions bool
+	orgID             string
+	projectID         string
+	apiVersion        string
+	timeout           time.Duration
+	maxRetries        int
 }
 
 // NewCompleter creates a Completer for the given provider type.
 func NewCompleter(apiType, apiKey, baseURL, model string, opts Options) Completer {
 	cfg := completerConfig{
-		http:            &http.Client{},
-		apiKey:          apiKey,
-		baseURL:         baseURL,
-		model:           model,
-		maxTokens:       opts.MaxTokens,
-		temperature:     opts.Temperature,
-		disableThinking: opts.DisableThinking,
+		http:              httpClient,
+		apiKey:            apiKey,
+		baseURL:           baseURL,
+		model:             model,
+		maxTokens:         opts.MaxTokens,
+		temperature:       opts.Temperature,
+		disableThinking:   opts.DisableThinking,
+		omitStreamOptions: opts.OmitStreamOptions,
+		orgID:             opts.OrgID,
+		projectID:         opts.ProjectID,
+		apiVersion:        opts.APIVersion,
+		timeout:           cmp.Or(opts.Timeout, DefaultTimeout),
+		maxRetries:        cmp.Or(opts.MaxRetries, DefaultMaxRetries),
+	}
+
+	if opts.Debug {
+		cfg.http = &http.Client{Transport: debugTransport{base: httpClient.Transport}}
 	}
 
 	switch apiType {
@@ -69,6 +183,8 @@ func NewCompleter(apiType, apiKey, baseURL, model string, opts Options) Complete
 		return &claudeCompleter{cfg: cfg}
 	case "openai", "openai-compatible":
 		return &openaiCompleter{cfg: cfg, isCompatible: apiType == "openai-compatible"}
+	case "azure-openai":
+		return &openaiCompleter{cfg: cfg, isAzure: true}
 	default:
 		// Default to OpenAI format
 		return &openaiCompleter{cfg: cfg}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const chatResponse = `{"choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"total_tokens":1}}`
+
+func TestCompleterAPIErrorRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiType string
+		header  string
+		body    string
+		stream  bool
+	}{
+		{"openai", "openai-compatible", "x-request-id", `{"error":{"message":"rate limited"}}`, false},
+		{"openai stream", "openai-compatible", "x-request-id", `{"error":{"message":"rate limited"}}`, true},
+		{"claude", "claude", "request-id", `{"type":"error","error":{"type":"rate_limit_error","message":"rate limited"}}`, false},
+		{"claude stream", "claude", "request-id", `{"type":"error","error":{"type":"rate_limit_error","message":"rate limited"}}`, true},
+		{"claude html body", "claude", "request-id", `<html>bad gateway</html>`, false},
+		{"gemini", "gemini", "x-request-id", `{"error":{"code":429,"message":"rate limited"}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(tt.header, "req_123")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := NewCompleter(tt.apiType, "key", srv.URL, "model", Options{MaxRetries: -1})
+			var err error
+			if tt.stream {
+				_, err = c.(StreamCompleter).StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+			} else {
+				_, _, err = c.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *APIError", err)
+			}
+			if apiErr.RequestID != "req_123" || apiErr.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("APIError = %+v, want request ID req_123 and status 429", apiErr)
+			}
+			if !strings.Contains(err.Error(), "req_123") {
+				t.Errorf("error %q does not mention the request ID", err)
+			}
+		})
+	}
+}
+
+func TestCompleterReusesConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	// Separate completers, as created per translation.
+	for i := range 3 {
+		c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{})
+		if _, _, err := c.Complete(context.Background(), []Message{{Role: "user", Content: "hello"}}); err != nil {
+			t.Fatalf("call %d: Complete() error = %v", i, err)
+		}
+	}
+
+	if n := conns.Load(); n != 1 {
+		t.Errorf("opened %d connections for sequential calls, want 1", n)
+	}
+}
+
+func BenchmarkCompleterSequential(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse))
+	}))
+	defer srv.Close()
+
+	msgs := []Message{{Role: "user", Content: "hello"}}
+	for b.Loop() {
+		c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{})
+		if _, _, err := c.Complete(context.Background(), msgs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
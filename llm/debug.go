@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxDebugBody caps how much of each request and response body is logged.
+const maxDebugBody = 8 << 10
+
+const redacted = "[REDACTED]"
+
+// secretHeaders carry API keys: OpenAI, Azure, Claude and Gemini.
+var secretHeaders = []string{"Authorization", "Api-Key", "X-Api-Key", "X-Goog-Api-Key"}
+
+// secretParams are query parameters that carry API keys, as Gemini's key=.
+var secretParams = []string{"key", "api_key", "api-key"}
+
+// debugTransport logs requests and responses at debug level with their
+// API keys redacted. Response bodies are logged as they are read, once
+// closed, so streams are not held back.
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+func (t debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := redactURL(req.URL)
+	slog.Debug("llm request",
+		"method", req.Method,
+		"url", target,
+		"header", redactHeader(req.Header),
+		"body", requestBody(req))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		slog.Debug("llm request error", "url", target, "error", err)
+		return nil, err
+	}
+	resp.Body = &loggedBody{ReadCloser: resp.Body, url: target, status: resp.StatusCode}
+	return resp, nil
+}
+
+// requestBody returns the start of req's body without consuming it.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	var body io.ReadCloser
+	if req.GetBody != nil {
+		b, err := req.GetBody()
+		if err != nil {
+			return ""
+		}
+		body = b
+	} else {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		if err != nil {
+			return ""
+		}
+		body = io.NopCloser(bytes.NewReader(data))
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, maxDebugBody+1))
+	return truncateDebug(data)
+}
+
+// loggedBody records what is read from a response body and logs it on
+// Close.
+type loggedBody struct {
+	io.ReadCloser
+	url    string
+	status int
+
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	once sync.Once
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	if room := maxDebugBody + 1 - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	b.mu.Unlock()
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	b.once.Do(func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		slog.Debug("llm response", "url", b.url, "status", b.status, "body", truncateDebug(b.buf.Bytes()))
+	})
+	return b.ReadCloser.Close()
+}
+
+func truncateDebug(data []byte) string {
+	if len(data) > maxDebugBody {
+		return string(data[:maxDebugBody]) + "…(truncated)"
+	}
+	return string(data)
+}
+
+// redactURL returns u as a string with API key query parameters redacted.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	q := u.Query()
+	changed := false
+	for name := range q {
+		for _, secret := range secretParams {
+			if strings.EqualFold(name, secret) {
+				q[name] = []string{redacted}
+				changed = true
+			}
+		}
+	}
+	c := *u
+	c.User = nil
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+// redactHeader returns a copy of h with API key headers redacted.
+func redactHeader(h http.Header) http.Header {
+	c := h.Clone()
+	for _, name := range secretHeaders {
+		if c.Get(name) != "" {
+			c.Set(name, redacted)
+		}
+	}
+	return c
+}
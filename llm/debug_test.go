@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testKey = "sk-secret-123"
+
+func TestRedact(t *testing.T) {
+	u, err := url.Parse("https://generativelanguage.googleapis.com/v1beta/models/gemini:streamGenerateContent?alt=sse&key=" + testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := redactURL(u)
+	if strings.Contains(got, testKey) || !strings.Contains(got, "alt=sse") {
+		t.Errorf("redactURL() = %q, want key redacted and other params kept", got)
+	}
+
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+testKey)
+	h.Set("x-api-key", testKey)
+	h.Set("api-key", testKey)
+	h.Set("Content-Type", "application/json")
+	r := redactHeader(h)
+	for name, values := range r {
+		for _, v := range values {
+			if strings.Contains(v, testKey) {
+				t.Errorf("header %s = %q leaks the key", name, v)
+			}
+		}
+	}
+	if r.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q, want it kept", r.Get("Content-Type"))
+	}
+	if h.Get("Authorization") != "Bearer "+testKey {
+		t.Error("redactHeader() modified the original header")
+	}
+}
+
+func TestDebugLogging(t *testing.T) {
+	var logs bytes.Buffer
+	old := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	t.Cleanup(func() { slog.SetDefault(old) })
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "Hola") {
+			t.Errorf("server got body %q, want the request intact", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":{"message":"model not found"}}`)
+	}))
+	defer srv.Close()
+
+	c := NewCompleter("gemini", testKey, srv.URL, "gemini-x", Options{Debug: true, MaxRetries: -1})
+	_, _, err := c.Complete(context.Background(), []Message{{Role: "user", Content: "Hola"}})
+	if err == nil {
+		t.Fatal("Complete() error = nil, want the 400")
+	}
+
+	out := logs.String()
+	if strings.Contains(out, testKey) {
+		t.Errorf("debug log leaks the API key:\n%s", out)
+	}
+	for _, want := range []string{"llm request", "Hola", "llm response", "status=400", "model not found"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug log missing %q:\n%s", want, out)
+		}
+	}
+}
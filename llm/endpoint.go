@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const chatCompletionsPath = "/chat/completions"
+
+// versionSuffix matches a trailing API version segment such as "/v1".
+var versionSuffix = regexp.MustCompile(`/v\d+(beta\d*)?$`)
+
+// ChatCompletionsURL returns the chat completions endpoint for an
+// OpenAI-compatible base URL:
+//
+//	https://host                     → https://host/v1/chat/completions
+//	https://host/v1                  → https://host/v1/chat/completions
+//	https://host/v1/chat/completions → unchanged
+//	https://host/custom/path         → unchanged
+//
+// Only a bare host or a path ending in an API version gets a suffix; any
+// other path is taken to be the full endpoint.
+func ChatCompletionsURL(baseURL string) string {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	switch {
+	case path == "":
+		path = "/v1" + chatCompletionsPath
+	case versionSuffix.MatchString(path):
+		path += chatCompletionsPath
+	}
+	u.Path = path
+	return u.String()
+}
+
+// azureDeploymentsPath is the path segment preceding an Azure OpenAI
+// deployment name.
+const azureDeploymentsPath = "/openai/deployments/"
+
+// AzureChatCompletionsURL returns the Azure OpenAI chat completions
+// endpoint for a resource URL, deployment and API version:
+//
+//	https://res.openai.azure.com                          → https://res.openai.azure.com/openai/deployments/{deployment}/chat/completions?api-version={v}
+//	https://res.openai.azure.com/openai/deployments/d     → https://res.openai.azure.com/openai/deployments/d/chat/completions?api-version={v}
+//	https://res.openai.azure.com/openai/deployments/d/... → unchanged apart from api-version
+//
+// A URL that already names a deployment keeps it. A non-empty apiVersion
+// replaces any api-version already in the URL.
+func AzureChatCompletionsURL(baseURL, deployment, apiVersion string) string {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+
+	path := strings.TrimRight(u.Path, "/")
+	i := strings.Index(path, azureDeploymentsPath)
+	switch {
+	case i == -1:
+		path += azureDeploymentsPath + deployment + chatCompletionsPath
+	case !strings.Contains(path[i+len(azureDeploymentsPath):], "/"):
+		path += chatCompletionsPath
+	}
+	u.Path = path
+	u.RawPath = ""
+
+	if apiVersion != "" {
+		q := u.Query()
+		q.Set("api-version", apiVersion)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
@@ -0,0 +1,56 @@
+package llm
+
+import "testing"
+
+func TestChatCompletionsURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"https://api.example.com", "https://api.example.com/v1/chat/completions"},
+		{"https://api.example.com/", "https://api.example.com/v1/chat/completions"},
+		{"https://api.example.com/v1", "https://api.example.com/v1/chat/completions"},
+		{"https://api.example.com/v1/", "https://api.example.com/v1/chat/completions"},
+		{"http://localhost:11434/v1", "http://localhost:11434/v1/chat/completions"},
+		{"https://api.example.com/v1/chat/completions", "https://api.example.com/v1/chat/completions"},
+		{"https://gw.example.com/openai/v1beta", "https://gw.example.com/openai/v1beta/chat/completions"},
+		{"https://gw.example.com/proxy/chat", "https://gw.example.com/proxy/chat"},
+		{"https://gw.example.com/api/chat/completions?key=x", "https://gw.example.com/api/chat/completions?key=x"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			if got := ChatCompletionsURL(tt.base); got != tt.want {
+				t.Errorf("ChatCompletionsURL(%q) = %q, want %q", tt.base, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAzureChatCompletionsURL(t *testing.T) {
+	const full = "https://res.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=2024-10-21"
+	tests := []struct {
+		base       string
+		deployment string
+		version    string
+		want       string
+	}{
+		{"https://res.openai.azure.com", "gpt4o", "2024-10-21", full},
+		{"https://res.openai.azure.com/", "gpt4o", "2024-10-21", full},
+		{"https://res.openai.azure.com/openai/deployments/gpt4o", "other", "2024-10-21", full},
+		{"https://res.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=2023-05-15", "gpt4o", "2024-10-21", full},
+		{"https://res.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=2024-10-21", "gpt4o", "", full},
+		{"https://gw.example.com/azure", "my deployment", "2024-10-21",
+			"https://gw.example.com/azure/openai/deployments/my%20deployment/chat/completions?api-version=2024-10-21"},
+		{"not a url", "gpt4o", "2024-10-21", "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			if got := AzureChatCompletionsURL(tt.base, tt.deployment, tt.version); got != tt.want {
+				t.Errorf("AzureChatCompletionsURL(%q, %q, %q) = %q, want %q", tt.base, tt.deployment, tt.version, got, tt.want)
+			}
+		})
+	}
+}
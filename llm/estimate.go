@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// messageOverhead approximates the per-message framing tokens
+// (role markers, separators) added by chat APIs.
+const messageOverhead = 4
+
+// charsPerToken returns the average number of non-CJK characters per token
+// for the model family. Values are rough averages for English-like text.
+func charsPerToken(model string) float64 {
+	m := strings.ToLower(model)
+	switch {
+	case strings.Contains(m, "claude"):
+		return 3.5
+	default:
+		return 4 // OpenAI and Gemini
+	}
+}
+
+// EstimateTokens returns a rough token count for text under the given model.
+// CJK characters are counted as one token each; everything else is divided
+// by the model family's average characters per token. No network call is made.
+func EstimateTokens(model, text string) int {
+	var cjk, other int
+	for _, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	n := cjk
+	if other > 0 {
+		n += int(float64(other)/charsPerToken(model) + 0.999)
+	}
+	return n
+}
+
+// EstimateMessagesTokens returns a rough prompt token count for messages.
+func EstimateMessagesTokens(model string, messages []Message) int {
+	n := 0
+	for _, msg := range messages {
+		n += messageOverhead + EstimateTokens(model, msg.Content)
+	}
+	return n
+}
+
+// isCJK reports whether r is a Han, Hiragana, Katakana or Hangul character.
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
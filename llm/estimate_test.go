@@ -0,0 +1,31 @@
+package llm
+
+import "testing"
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		text  string
+		want  int
+	}{
+		{"empty", "gpt-4o", "", 0},
+		{"english", "gpt-4o", "Hello, world!", 4},                     // 13 chars / 4, rounded up
+		{"claude ratio", "claude-3-5-haiku", "Hello, world!", 4},      // 13 / 3.5
+		{"unknown model", "my-local-model", "The quick brown fox", 5}, // 19 / 4
+		{"cjk", "gpt-4o", "你好世界", 4},
+		{"mixed", "gpt-4o", "東京 Tokyo", 4}, // 2 CJK + 6 others / 4
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateTokens(tt.model, tt.text); got != tt.want {
+				t.Errorf("EstimateTokens(%q, %q) = %d, want %d", tt.model, tt.text, got, tt.want)
+			}
+		})
+	}
+
+	msgs := []Message{{Role: "system", Content: "Translate."}, {Role: "user", Content: "你好"}}
+	if got, want := EstimateMessagesTokens("gpt-4o", msgs), 2*messageOverhead+3+2; got != want {
+		t.Errorf("EstimateMessagesTokens() = %d, want %d", got, want)
+	}
+}
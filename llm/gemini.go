@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,15 @@ import (
 
 const defaultGeminiBaseURL = "https://generativelanguage.googleapis.com/v1beta/models"
 
+// Sentinel errors for Gemini responses that carry no usable text.
+var (
+	// ErrContentBlocked indicates the prompt or output was blocked by safety filters.
+	ErrContentBlocked = errors.New("content blocked")
+	// ErrTruncated indicates the output hit the token limit. The partial text
+	// is returned alongside this error.
+	ErrTruncated = errors.New("output truncated")
+)
+
 // geminiCompleter implements Completer for Gemini API.
 type geminiCompleter struct {
 	cfg completerConfig
@@ -51,13 +61,19 @@ type geminiSystemInst struct {
 }
 
 type geminiResponse struct {
-	Candidates    []geminiCandidate `json:"candidates"`
-	UsageMetadata *geminiUsage      `json:"usageMetadata,omitempty"`
-	Error         *geminiError      `json:"error,omitempty"`
+	Candidates     []geminiCandidate     `json:"candidates"`
+	PromptFeedback *geminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *geminiUsage          `json:"usageMetadata,omitempty"`
+	Error          *geminiError          `json:"error,omitempty"`
 }
 
 type geminiCandidate struct {
-	Content geminiContent `json:"content"`
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"`
+}
+
+type geminiPromptFeedback struct {
+	BlockReason string `json:"blockReason,omitempty"`
 }
 
 type geminiUsage struct {
@@ -140,7 +156,7 @@ func (c *geminiCompleter) Complete(ctx context.Context, messages []Message) (str
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, false)
 	if err != nil {
 		return "", types.Usage{}, fmt.Errorf("do request: %w", err)
 	}
@@ -153,18 +169,48 @@ func (c *geminiCompleter) Complete(ctx context.Context, messages []Message) (str
 
 	var geminiResp geminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", types.Usage{}, newAPIError(resp, string(body))
+		}
 		return "", types.Usage{}, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if geminiResp.Error != nil {
-		return "", types.Usage{}, fmt.Errorf("api error: %d - %s", geminiResp.Error.Code, geminiResp.Error.Message)
+		return "", types.Usage{}, newAPIError(resp, geminiResp.Error.Message)
+	}
+
+	text, err := geminiResp.text()
+	return text, geminiToUsage(geminiResp.UsageMetadata), err
+}
+
+// text extracts the first candidate's text, mapping block and finish reasons
+// to ErrContentBlocked or ErrTruncated. On truncation the partial text is
+// returned together with the error.
+func (r *geminiResponse) text() (string, error) {
+	if r.PromptFeedback != nil && r.PromptFeedback.BlockReason != "" {
+		return "", fmt.Errorf("%w: prompt blocked (%s)", ErrContentBlocked, r.PromptFeedback.BlockReason)
+	}
+	if len(r.Candidates) == 0 {
+		return "", fmt.Errorf("no candidates returned")
 	}
 
-	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
-		return "", types.Usage{}, fmt.Errorf("no candidates returned")
+	cand := r.Candidates[0]
+	var text string
+	if len(cand.Content.Parts) > 0 {
+		text = cand.Content.Parts[0].Text
 	}
 
-	return geminiResp.Candidates[0].Content.Parts[0].Text, geminiToUsage(geminiResp.UsageMetadata), nil
+	switch cand.FinishReason {
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII":
+		return "", fmt.Errorf("%w: finish reason %s", ErrContentBlocked, cand.FinishReason)
+	case "MAX_TOKENS":
+		return text, fmt.Errorf("%w: increase max tokens", ErrTruncated)
+	}
+
+	if len(cand.Content.Parts) == 0 {
+		return "", fmt.Errorf("no candidates returned")
+	}
+	return text, nil
 }
 
 // StreamComplete implements StreamCompleter for streaming responses.
@@ -184,7 +230,7 @@ func (c *geminiCompleter) StreamComplete(ctx context.Context, messages []Message
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, true)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -192,7 +238,7 @@ func (c *geminiCompleter) StreamComplete(ctx context.Context, messages []Message
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("api error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, string(body))
 	}
 
 	ch := make(chan StreamDelta, 16)
@@ -233,10 +279,8 @@ func (c *geminiCompleter) StreamComplete(ctx context.Context, messages []Message
 			}
 		}
 
-		select {
-		case ch <- StreamDelta{Done: true, Usage: usage}:
-		case <-ctx.Done():
-		}
+		// Gemini has no end-of-stream marker, so only read errors signal truncation.
+		endStream(ctx, ch, usage, scanner.Err())
 	}()
 
 	return ch, nil
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestGeminiResponseText(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		wantText string
+		wantErr  error
+	}{
+		{
+			name:     "normal",
+			json:     `{"candidates":[{"content":{"role":"model","parts":[{"text":"你好"}]},"finishReason":"STOP"}]}`,
+			wantText: "你好",
+		},
+		{
+			name:    "prompt blocked",
+			json:    `{"promptFeedback":{"blockReason":"SAFETY"}}`,
+			wantErr: ErrContentBlocked,
+		},
+		{
+			name:    "candidate blocked",
+			json:    `{"candidates":[{"content":{"role":"model"},"finishReason":"SAFETY"}]}`,
+			wantErr: ErrContentBlocked,
+		},
+		{
+			name:     "truncated keeps partial text",
+			json:     `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello wor"}]},"finishReason":"MAX_TOKENS"}]}`,
+			wantText: "Hello wor",
+			wantErr:  ErrTruncated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp geminiResponse
+			if err := json.Unmarshal([]byte(tt.json), &resp); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+
+			text, err := resp.text()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
@@ -15,10 +15,12 @@ import (
 
 const defaultBaseURL = "https://api.openai.com/v1/chat/completions"
 
-// openaiCompleter implements Completer for OpenAI and compatible APIs.
+// openaiCompleter implements Completer for OpenAI and compatible APIs,
+// including Azure OpenAI, where the model names the deployment.
 type openaiCompleter struct {
 	cfg          completerConfig
 	isCompatible bool
+	isAzure      bool
 }
 
 // OpenAI request/response types
@@ -71,8 +73,11 @@ type openaiStreamDelta struct {
 
 // baseURL returns the configured or default base URL.
 func (c *openaiCompleter) baseURL() string {
+	if c.isAzure {
+		return AzureChatCompletionsURL(c.cfg.baseURL, c.cfg.model, c.cfg.apiVersion)
+	}
 	if c.isCompatible && c.cfg.baseURL != "" {
-		return c.cfg.baseURL
+		return ChatCompletionsURL(c.cfg.baseURL)
 	}
 	return defaultBaseURL
 }
@@ -86,7 +91,9 @@ func (c *openaiCompleter) buildRequest(messages []Message, stream bool) openaiRe
 		Temperature: c.cfg.temperature,
 		Stream:      stream,
 	}
-	if stream {
+	// Real OpenAI always accepts stream_options; only compatible gateways
+	// and older Azure API versions may opt out.
+	if stream && !((c.isCompatible || c.isAzure) && c.cfg.omitStreamOptions) {
 		req.StreamOptions = &openaiStreamOpts{IncludeUsage: true}
 	}
 	return req
@@ -99,7 +106,17 @@ func (c *openaiCompleter) newRequest(ctx context.Context, body []byte) (*http.Re
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.cfg.apiKey)
+	if c.isAzure {
+		req.Header.Set("api-key", c.cfg.apiKey)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.apiKey)
+	}
+	if c.cfg.orgID != "" {
+		req.Header.Set("OpenAI-Organization", c.cfg.orgID)
+	}
+	if c.cfg.projectID != "" {
+		req.Header.Set("OpenAI-Project", c.cfg.projectID)
+	}
 	return req, nil
 }
 
@@ -116,7 +133,7 @@ func (c *openaiCompleter) Complete(ctx context.Context, messages []Message) (str
 		return "", types.Usage{}, err
 	}
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, false)
 	if err != nil {
 		return "", types.Usage{}, fmt.Errorf("do request: %w", err)
 	}
@@ -128,7 +145,7 @@ func (c *openaiCompleter) Complete(ctx context.Context, messages []Message) (str
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", types.Usage{}, fmt.Errorf("api error: %d - %s", resp.StatusCode, string(body))
+		return "", types.Usage{}, newAPIError(resp, string(body))
 	}
 
 	var chatResp openaiResponse
@@ -158,7 +175,7 @@ func (c *openaiCompleter) StreamComplete(ctx context.Context, messages []Message
 	}
 	req.Header.Set("Accept", "text/event-stream")
 
-	resp, err := c.cfg.http.Do(req)
+	resp, err := c.cfg.send(req, true)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
 	}
@@ -166,7 +183,7 @@ func (c *openaiCompleter) StreamComplete(ctx context.Context, messages []Message
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("api error: %d - %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, string(body))
 	}
 
 	ch := make(chan StreamDelta, 16)
@@ -190,10 +207,7 @@ func (c *openaiCompleter) StreamComplete(ctx context.Context, messages []Message
 
 			// Check for stream end
 			if data == "[DONE]" {
-				select {
-				case ch <- StreamDelta{Done: true, Usage: usage}:
-				case <-ctx.Done():
-				}
+				endStream(ctx, ch, usage, nil)
 				return
 			}
 
@@ -215,10 +229,12 @@ func (c *openaiCompleter) StreamComplete(ctx context.Context, messages []Message
 			}
 		}
 
-		select {
-		case ch <- StreamDelta{Done: true, Usage: usage}:
-		case <-ctx.Done():
+		// The stream must end with [DONE]; anything else was cut short.
+		err := scanner.Err()
+		if err == nil {
+			err = io.ErrUnexpectedEOF
 		}
+		endStream(ctx, ch, usage, err)
 	}()
 
 	return ch, nil
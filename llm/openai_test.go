@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIBuildRequestStreamOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiType    string
+		omit       bool
+		stream     bool
+		wantOption bool
+	}{
+		{"openai stream", "openai", false, true, true},
+		{"openai ignores omit", "openai", true, true, true},
+		{"compatible stream", "openai-compatible", false, true, true},
+		{"compatible omit", "openai-compatible", true, true, false},
+		{"azure stream", "azure-openai", false, true, true},
+		{"azure omit", "azure-openai", true, true, false},
+		{"no stream", "openai", false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompleter(tt.apiType, "key", "http://localhost", "model", Options{OmitStreamOptions: tt.omit}).(*openaiCompleter)
+
+			body, err := json.Marshal(c.buildRequest(nil, tt.stream))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if got := strings.Contains(string(body), `"stream_options"`); got != tt.wantOption {
+				t.Errorf("stream_options present = %v, want %v: %s", got, tt.wantOption, body)
+			}
+		})
+	}
+}
+
+func TestOpenAIRequestScopeHeaders(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        Options
+		wantOrg     string
+		wantProject string
+	}{
+		{"unset", Options{}, "", ""},
+		{"org and project", Options{OrgID: "org-1", ProjectID: "proj-1"}, "org-1", "proj-1"},
+		{"project only", Options{ProjectID: "proj-1"}, "", "proj-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompleter("openai", "key", "", "model", tt.opts).(*openaiCompleter)
+			req, err := c.newRequest(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("newRequest() error = %v", err)
+			}
+			if got := req.Header.Get("OpenAI-Organization"); got != tt.wantOrg {
+				t.Errorf("OpenAI-Organization = %q, want %q", got, tt.wantOrg)
+			}
+			if got := req.Header.Get("OpenAI-Project"); got != tt.wantProject {
+				t.Errorf("OpenAI-Project = %q, want %q", got, tt.wantProject)
+			}
+			if _, ok := req.Header["Openai-Organization"]; ok != (tt.wantOrg != "") {
+				t.Errorf("OpenAI-Organization present = %v, want %v", ok, tt.wantOrg != "")
+			}
+		})
+	}
+}
+
+func TestAzureRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiType    string
+		wantAPIKey string
+		wantAuth   string
+		wantURL    string
+	}{
+		{
+			name:       "azure uses api-key and deployment url",
+			apiType:    "azure-openai",
+			wantAPIKey: "secret",
+			wantURL:    "https://res.openai.azure.com/openai/deployments/gpt4o/chat/completions?api-version=2024-10-21",
+		},
+		{
+			name:     "openai uses bearer token",
+			apiType:  "openai",
+			wantAuth: "Bearer secret",
+			wantURL:  defaultBaseURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCompleter(tt.apiType, "secret", "https://res.openai.azure.com", "gpt4o", Options{APIVersion: "2024-10-21"}).(*openaiCompleter)
+			req, err := c.newRequest(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("newRequest() error = %v", err)
+			}
+			if got := req.Header.Get("api-key"); got != tt.wantAPIKey {
+				t.Errorf("api-key = %q, want %q", got, tt.wantAPIKey)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+			}
+			if got := req.URL.String(); got != tt.wantURL {
+				t.Errorf("URL = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
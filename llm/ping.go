@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PingTimeout bounds a Ping, so a wrong endpoint fails fast in settings.
+const PingTimeout = 10 * time.Second
+
+// Errors returned by Ping, wrapping the provider's message.
+var (
+	ErrUnauthorized = errors.New("api key rejected")
+	ErrForbidden    = errors.New("api key lacks access")
+	ErrNotFound     = errors.New("model or endpoint not found")
+	ErrUnreachable  = errors.New("endpoint unreachable")
+)
+
+// pingMaxTokens keeps the test completion minimal while leaving room for
+// models that refuse very small limits.
+const pingMaxTokens = 16
+
+// Ping checks that apiKey can use model at baseURL by requesting a tiny
+// completion, with no retries. It returns nil on success, or an error
+// wrapping ErrUnauthorized, ErrForbidden, ErrNotFound or ErrUnreachable
+// when the failure is one of those. Options other than the provider
+// headers and api version are ignored.
+func Ping(ctx context.Context, apiType, apiKey, baseURL, model string, opts Options) error {
+	c := NewCompleter(apiType, apiKey, baseURL, model, Options{
+		MaxTokens:         pingMaxTokens,
+		DisableThinking:   true,
+		OmitStreamOptions: opts.OmitStreamOptions,
+		OrgID:             opts.OrgID,
+		ProjectID:         opts.ProjectID,
+		APIVersion:        opts.APIVersion,
+		Timeout:           PingTimeout,
+		MaxRetries:        -1,
+		Debug:             opts.Debug,
+	})
+	_, _, err := c.Complete(ctx, []Message{{Role: "user", Content: "Reply with OK."}})
+	return pingError(err)
+}
+
+// pingError maps a Complete error to a descriptive Ping error.
+func pingError(err error) error {
+	if err == nil || errors.Is(err, ErrTruncated) {
+		// A truncated reply still reached the model.
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized,
+			// Gemini answers a bad key with 400 INVALID_ARGUMENT.
+			apiErr.StatusCode == http.StatusBadRequest && strings.Contains(apiErr.Message, "API key"):
+			return fmt.Errorf("%w: %w", ErrUnauthorized, apiErr)
+		case apiErr.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("%w: %w", ErrForbidden, apiErr)
+		case apiErr.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%w: %w", ErrNotFound, apiErr)
+		}
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	if errors.Is(err, ErrTimeout) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	// The url.Error would repeat the request URL, which for Gemini holds
+	// the key; report only the underlying cause.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, urlErr.Err)
+	}
+	return err
+}
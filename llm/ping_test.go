@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiType string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{"ok", "openai-compatible", http.StatusOK, chatResponse, nil},
+		{"claude ok", "claude", http.StatusOK, `{"content":[{"type":"text","text":"OK"}],"usage":{"input_tokens":3,"output_tokens":1}}`, nil},
+		{"bad key", "openai-compatible", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`, ErrUnauthorized},
+		{"gemini bad key", "gemini", http.StatusBadRequest, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key."}}`, ErrUnauthorized},
+		{"forbidden", "claude", http.StatusForbidden, `{"type":"error","error":{"type":"permission_error","message":"no access"}}`, ErrForbidden},
+		{"unknown model", "openai-compatible", http.StatusNotFound, `{"error":{"message":"model not found"}}`, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			err := Ping(context.Background(), tt.apiType, "sk-test", srv.URL, "model", Options{})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Ping() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Ping() error = %v, want %v", err, tt.wantErr)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("Ping() error = %v, want the provider's %d response kept", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestPingOtherErrors(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	err := Ping(context.Background(), "openai-compatible", "sk-test", srv.URL, "model", Options{})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || errors.Is(err, ErrUnreachable) {
+		t.Errorf("Ping() on 500 error = %v, want the plain APIError", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Ping() made %d attempts, want no retries", attempts)
+	}
+
+	// A closed server refuses the connection. The key in Gemini's query
+	// string must not show up in the error.
+	srv.Close()
+	err = Ping(context.Background(), "gemini", "secret-key", srv.URL, "model", Options{})
+	if !errors.Is(err, ErrUnreachable) {
+		t.Errorf("Ping() on closed server error = %v, want ErrUnreachable", err)
+	}
+	if err != nil && strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("Ping() error %q leaks the API key", err)
+	}
+}
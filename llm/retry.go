@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults for Options.Timeout and Options.MaxRetries.
+const (
+	DefaultTimeout    = 30 * time.Second
+	DefaultMaxRetries = 2
+)
+
+// maxRetryAfter caps how long a Retry-After header can make a request wait.
+const maxRetryAfter = time.Minute
+
+// retryBackoff is the delay before the first retry; it doubles per attempt.
+var retryBackoff = 500 * time.Millisecond
+
+// ErrTimeout indicates a provider did not respond within Options.Timeout.
+var ErrTimeout = errors.New("request timed out")
+
+// send performs req, retrying rate limits, server errors and timeouts up to
+// maxRetries times with exponential backoff or the provider's Retry-After.
+// Waits end early when req's context is cancelled. The timeout bounds each
+// attempt: the whole exchange, or for streams only the wait for response
+// headers, since a stream may legitimately run long.
+//
+// The returned response may still have a retryable status once retries are
+// exhausted; callers handle it like any other error response.
+func (c *completerConfig) send(req *http.Request, stream bool) (*http.Response, error) {
+	ctx := req.Context()
+	delay := retryBackoff
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			r, err := rewind(req)
+			if err != nil {
+				return nil, err
+			}
+			req = r
+		}
+
+		resp, err := c.attempt(req, stream)
+		last := attempt >= c.maxRetries
+		wait := delay
+		switch {
+		case err != nil:
+			if last || !retryableErr(ctx, err) {
+				return nil, err
+			}
+		case retryableStatus(resp.StatusCode) && !last:
+			if d, ok := retryAfter(resp.Header); ok {
+				wait = d
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			err = fmt.Errorf("status %d", resp.StatusCode)
+		default:
+			return resp, nil
+		}
+
+		slog.Warn("llm request failed, retrying", "attempt", attempt+1, "delay", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// attempt performs req once, enforcing the timeout.
+func (c *completerConfig) attempt(req *http.Request, stream bool) (*http.Response, error) {
+	if c.timeout <= 0 {
+		return c.http.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	timer := time.AfterFunc(c.timeout, cancel)
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		fired := !timer.Stop()
+		cancel()
+		if fired && req.Context().Err() == nil {
+			return nil, fmt.Errorf("%w after %s", ErrTimeout, c.timeout)
+		}
+		return nil, err
+	}
+	if stream {
+		timer.Stop()
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: func() {
+		timer.Stop()
+		cancel()
+	}}
+	return resp, nil
+}
+
+// cancelBody releases the attempt's context when the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel func()
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rewind returns a copy of req with a fresh body for another attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewind request body: %w", err)
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryableErr reports whether a failed attempt is worth retrying: a
+// timeout, unless the caller's ctx itself is done.
+func retryableErr(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	return errors.Is(err, ErrTimeout) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// retryAfter parses a Retry-After header in seconds or as an HTTP date,
+// capped at maxRetryAfter.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	} else {
+		return 0, false
+	}
+	return min(max(d, 0), maxRetryAfter), true
+}
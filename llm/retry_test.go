@@ -0,0 +1,194 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastBackoff shortens retry delays for the duration of a test.
+func fastBackoff(t *testing.T) {
+	old := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = old })
+}
+
+// statusServer replies with statuses in order, then with the last one.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		status := statuses[min(n, len(statuses)-1)]
+		if status != http.StatusOK {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"try again"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestCompleterRetry(t *testing.T) {
+	fastBackoff(t)
+
+	tests := []struct {
+		name       string
+		statuses   []int
+		maxRetries int
+		wantCalls  int32
+		wantStatus int // 0 for success
+	}{
+		{"rate limited then ok", []int{429, 200}, 0, 2, 0},
+		{"server errors then ok", []int{500, 503, 200}, 0, 3, 0},
+		{"retries exhausted", []int{503}, 0, 3, 503},
+		{"retries disabled", []int{429, 200}, -1, 1, 429},
+		{"client error not retried", []int{400, 200}, 0, 1, 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := statusServer(t, tt.statuses...)
+			c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{MaxRetries: tt.maxRetries})
+
+			text, _, err := c.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+			if tt.wantStatus == 0 {
+				if err != nil || text != "hi" {
+					t.Errorf("Complete() = %q, %v; want hi", text, err)
+				}
+			} else {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantStatus {
+					t.Errorf("Complete() error = %v, want status %d", err, tt.wantStatus)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCompleterRetryStream(t *testing.T) {
+	fastBackoff(t)
+	srv, calls := statusServer(t, 429, 200)
+	c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{}).(StreamCompleter)
+
+	if _, err := c.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}); err != nil {
+		t.Fatalf("StreamComplete() error = %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestCompleterRetryCancel(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{MaxRetries: 5})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := c.Complete(ctx, []Message{{Role: "user", Content: "hi"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Complete() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancel took %v", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestCompleterTimeout(t *testing.T) {
+	fastBackoff(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-release // Hang past the client timeout
+			return
+		}
+		w.Write([]byte(chatResponse))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{Timeout: 50 * time.Millisecond, MaxRetries: 1})
+	text, _, err := c.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err != nil || text != "hi" {
+		t.Errorf("Complete() = %q, %v; want hi after retrying the timeout", text, err)
+	}
+
+	calls.Store(0) // Hang again
+	c = NewCompleter("openai-compatible", "key", srv.URL, "model", Options{Timeout: 50 * time.Millisecond, MaxRetries: -1})
+	if _, _, err := c.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}}); !errors.Is(err, ErrTimeout) {
+		t.Errorf("Complete() error = %v, want ErrTimeout", err)
+	}
+}
+
+func TestStreamTimeoutOnlyBoundsHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond) // Longer than the timeout
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\ndata: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	c := NewCompleter("openai-compatible", "key", srv.URL, "model", Options{Timeout: 30 * time.Millisecond}).(StreamCompleter)
+	ch, err := c.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+	if err != nil {
+		t.Fatalf("StreamComplete() error = %v", err)
+	}
+	var text string
+	for d := range ch {
+		if d.Err != nil {
+			t.Fatalf("stream error = %v", d.Err)
+		}
+		text += d.Text
+	}
+	if text != "hi" {
+		t.Errorf("streamed %q, want hi", text)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, true},
+		{"3600", maxRetryAfter, true},
+		{"soon", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(h)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
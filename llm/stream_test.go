@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// sseServer streams events and then either ends cleanly or drops the
+// connection without terminating the response.
+func sseServer(t *testing.T, events []string, drop bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprintf(w, "data: %s\n\n", e)
+		}
+		w.(http.Flusher).Flush()
+		if !drop {
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// collect drains a stream, returning the text and the final delta.
+func collect(t *testing.T, ch <-chan StreamDelta) (string, StreamDelta) {
+	t.Helper()
+	var text string
+	var last StreamDelta
+	for d := range ch {
+		text += d.Text
+		last = d
+	}
+	if !last.Done {
+		t.Fatal("stream closed without a final delta")
+	}
+	return text, last
+}
+
+func TestStreamCompleteTruncated(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiType string
+		events  []string
+		drop    bool
+		wantErr bool
+	}{
+		{
+			name:    "openai dropped",
+			apiType: "openai-compatible",
+			events:  []string{`{"choices":[{"delta":{"content":"Hel"}}]}`},
+			drop:    true,
+			wantErr: true,
+		},
+		{
+			name:    "openai missing done",
+			apiType: "openai-compatible",
+			events:  []string{`{"choices":[{"delta":{"content":"Hel"}}]}`},
+			wantErr: true,
+		},
+		{
+			name:    "openai complete",
+			apiType: "openai-compatible",
+			events:  []string{`{"choices":[{"delta":{"content":"Hel"}}]}`, "[DONE]"},
+		},
+		{
+			name:    "claude dropped",
+			apiType: "claude",
+			events:  []string{`{"type":"content_block_delta","delta":{"text":"Hel"}}`},
+			drop:    true,
+			wantErr: true,
+		},
+		{
+			name:    "claude complete",
+			apiType: "claude",
+			events:  []string{`{"type":"content_block_delta","delta":{"text":"Hel"}}`, `{"type":"message_stop"}`},
+		},
+		{
+			name:    "gemini dropped",
+			apiType: "gemini",
+			events:  []string{`{"candidates":[{"content":{"parts":[{"text":"Hel"}]}}]}`},
+			drop:    true,
+			wantErr: true,
+		},
+		{
+			name:    "gemini complete",
+			apiType: "gemini",
+			events:  []string{`{"candidates":[{"content":{"parts":[{"text":"Hel"}]}}]}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := sseServer(t, tt.events, tt.drop)
+			c := NewCompleter(tt.apiType, "key", srv.URL, "model", Options{}).(StreamCompleter)
+
+			ch, err := c.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}})
+			if err != nil {
+				t.Fatalf("StreamComplete() error = %v", err)
+			}
+
+			text, last := collect(t, ch)
+			if text != "Hel" {
+				t.Errorf("text = %q, want %q", text, "Hel")
+			}
+			if got := errors.Is(last.Err, ErrStreamTruncated); got != tt.wantErr {
+				t.Errorf("final Err = %v, want truncated %v", last.Err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -64,8 +64,11 @@ func main() {
 		window.Hide()
 	})
 
+	// Dismiss on focus loss unless pinned on top
 	window.RegisterHook(events.Mac.WindowDidResignKey, func(e *application.WindowEvent) {
-		window.Hide()
+		if !service.IsAlwaysOnTop() {
+			window.Hide()
+		}
 	})
 
 	service.Init(wailsApp, window)
@@ -0,0 +1,42 @@
+package ocr
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// recognize performs single-image OCR. Replaced in tests.
+var recognize = RecognizeText
+
+// RecognizeTextBatch performs OCR on several images concurrently, running at
+// most maxConcurrency recognitions at once (GOMAXPROCS if <= 0). Results are
+// in input order; images that fail yield "" and their errors are joined.
+func RecognizeTextBatch(paths []string, maxConcurrency int) ([]string, error) {
+	if maxConcurrency <= 0 {
+		maxConcurrency = runtime.GOMAXPROCS(0)
+	}
+
+	texts := make([]string, len(paths))
+	errs := make([]error, len(paths))
+	sem := make(chan struct{}, maxConcurrency)
+
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			text, err := recognize(path)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", path, err)
+				return
+			}
+			texts[i] = text
+		})
+	}
+	wg.Wait()
+
+	return texts, errors.Join(errs...)
+}
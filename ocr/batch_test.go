@@ -0,0 +1,53 @@
+package ocr
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecognizeTextBatch(t *testing.T) {
+	var mu sync.Mutex
+	active, peak := 0, 0
+	recognize = func(path string) (string, error) {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+
+		// Finish out of order so ordering is actually exercised.
+		time.Sleep(time.Duration(5-len(path)) * time.Millisecond)
+		if strings.HasPrefix(path, "bad") {
+			return "", errors.New("unreadable")
+		}
+		return "text of " + path, nil
+	}
+	t.Cleanup(func() { recognize = RecognizeText })
+
+	paths := []string{"a", "bb", "bad", "dddd"}
+	texts, err := RecognizeTextBatch(paths, 2)
+
+	want := []string{"text of a", "text of bb", "", "text of dddd"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Errorf("RecognizeTextBatch() texts = %q, want %q", texts, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "bad: unreadable") {
+		t.Errorf("RecognizeTextBatch() error = %v, want error naming bad", err)
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+
+	texts, err = RecognizeTextBatch(nil, 0)
+	if err != nil || len(texts) != 0 {
+		t.Errorf("RecognizeTextBatch(nil) = %q, %v; want empty, nil", texts, err)
+	}
+}
@@ -0,0 +1,70 @@
+package ocr
+
+import (
+	"slices"
+	"strings"
+)
+
+// minConfidence is the confidence below which a recognized line is dropped
+// as noise, such as icons or textures read as stray characters.
+const minConfidence = 0.3
+
+// RecognizeText performs OCR on the image at the given path with a broad
+// default set of recognition languages.
+// It returns the recognized text or an error.
+func RecognizeText(imagePath string) (string, error) {
+	return RecognizeTextWithLangs(imagePath, nil)
+}
+
+// visionCodes maps app language codes to Vision recognition languages.
+// Chinese covers both scripts, since a screenshot may use either.
+var visionCodes = map[string][]string{
+	"zh": {"zh-Hans", "zh-Hant"},
+	"en": {"en-US"},
+	"ja": {"ja-JP"},
+	"ko": {"ko-KR"},
+	"fr": {"fr-FR"},
+	"de": {"de-DE"},
+	"es": {"es-ES"},
+	"ru": {"ru-RU"},
+	"it": {"it-IT"},
+	"pt": {"pt-BR"},
+	"ar": {"ar-SA"},
+}
+
+// IsSupported reports whether lang is a language code with a Vision
+// mapping, such as "ja", or a Vision recognition language, such as "zh-Hant".
+func IsSupported(lang string) bool {
+	lang = strings.TrimSpace(lang)
+	if _, ok := visionCodes[strings.ToLower(lang)]; ok {
+		return true
+	}
+	for _, codes := range visionCodes {
+		if slices.Contains(codes, lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// visionLanguages converts language codes to Vision recognition languages,
+// most preferred first. Codes without a mapping, such as "zh-Hant", are
+// passed through; empty and repeated ones are dropped.
+func visionLanguages(langs []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, lang := range langs {
+		lang = strings.TrimSpace(lang)
+		codes, ok := visionCodes[strings.ToLower(lang)]
+		if !ok {
+			codes = []string{lang}
+		}
+		for _, code := range codes {
+			if code != "" && !seen[code] {
+				seen[code] = true
+				out = append(out, code)
+			}
+		}
+	}
+	return out
+}
@@ -7,21 +7,28 @@ package ocr
 #include <stdlib.h>
 
 // Declaration of the Objective-C function implemented in ocr_darwin.m
-extern char* recognizeText(const char* imagePath);
+extern char* recognizeText(const char* imagePath, const char* languages, float minConfidence);
 */
 import "C"
 import (
 	"fmt"
+	"strings"
 	"unsafe"
 )
 
-// RecognizeText performs OCR on the image at the given path.
-// It returns the recognized text or an error.
-func RecognizeText(imagePath string) (string, error) {
+// RecognizeTextWithLangs performs OCR on the image at the given path,
+// recognizing the given languages, most preferred first. Codes are app
+// language codes such as "zh" or "ja", or Vision identifiers such as
+// "zh-Hant"; ones Vision doesn't support are skipped. No languages
+// selects a broad default set. Lines recognized with low confidence are
+// dropped. It returns the recognized text or an error.
+func RecognizeTextWithLangs(imagePath string, langs []string) (string, error) {
 	cPath := C.CString(imagePath)
 	defer C.free(unsafe.Pointer(cPath))
+	cLangs := C.CString(strings.Join(visionLanguages(langs), ","))
+	defer C.free(unsafe.Pointer(cLangs))
 
-	cResult := C.recognizeText(cPath)
+	cResult := C.recognizeText(cPath, cLangs, C.float(minConfidence))
 	if cResult == nil {
 		return "", fmt.Errorf("OCR failed to recognize text or load image")
 	}
@@ -5,10 +5,38 @@
 #import <CoreImage/CoreImage.h>
 #include <stdlib.h>
 
+// Default recognition languages: Chinese (Simplified/Traditional), English, Japanese, Korean, etc.
+static NSArray<NSString *> *defaultLanguages(void) {
+    return @[@"zh-Hans", @"zh-Hant", @"en-US", @"ja-JP", @"ko-KR", @"de-DE", @"fr-FR", @"es-ES"];
+}
+
+// Parse the comma-separated languages, keeping those the request supports.
+// Falls back to the defaults if none remain.
+static NSArray<NSString *> *recognitionLanguages(VNRecognizeTextRequest *request, const char* languages) {
+    NSString *list = [NSString stringWithUTF8String:languages];
+    if (list.length == 0) {
+        return defaultLanguages();
+    }
+    NSArray<NSString *> *supported = nil;
+    if (@available(macOS 12.0, *)) {
+        supported = [request supportedRecognitionLanguagesAndReturnError:nil];
+    }
+    NSMutableArray<NSString *> *result = [NSMutableArray array];
+    for (NSString *lang in [list componentsSeparatedByString:@","]) {
+        if (lang.length > 0 && (supported == nil || [supported containsObject:lang])) {
+            [result addObject:lang];
+        }
+    }
+    return result.count > 0 ? result : defaultLanguages();
+}
+
 // Recognize text from image at path using Vision framework.
+// languages is a comma-separated list of recognition languages, most
+// preferred first; empty uses the defaults. Lines whose top candidate has
+// a confidence below minConfidence are dropped.
 // Returns a C string containing the recognized text, joined by newlines.
 // The caller is responsible for freeing the returned string.
-char* recognizeText(const char* imagePath) {
+char* recognizeText(const char* imagePath, const char* languages, float minConfidence) {
     @autoreleasepool {
         NSString *path = [NSString stringWithUTF8String:imagePath];
         NSURL *imageURL = [NSURL fileURLWithPath:path];
@@ -25,12 +53,13 @@ char* recognizeText(const char* imagePath) {
         VNRecognizeTextRequest *request = [[VNRecognizeTextRequest alloc] initWithCompletionHandler:nil];
         request.recognitionLevel = VNRequestTextRecognitionLevelAccurate;
         request.usesLanguageCorrection = YES;
-        // automaticallyDetectsLanguage requires macOS 13.0+
+        // Detect the language only when none were chosen, so a user's
+        // choice isn't overridden. automaticallyDetectsLanguage requires
+        // macOS 13.0+
         if (@available(macOS 13.0, *)) {
-            request.automaticallyDetectsLanguage = YES;
+            request.automaticallyDetectsLanguage = (languages[0] == '\0');
         }
-        // Prioritize common languages: Chinese (Simplified/Traditional), English, Japanese, Korean, etc.
-        request.recognitionLanguages = @[@"zh-Hans", @"zh-Hant", @"en-US", @"ja-JP", @"ko-KR", @"de-DE", @"fr-FR", @"es-ES"];
+        request.recognitionLanguages = recognitionLanguages(request, languages);
 
         NSError *error = nil;
         [handler performRequests:@[request] error:&error];
@@ -42,7 +71,7 @@ char* recognizeText(const char* imagePath) {
         NSMutableString *resultText = [NSMutableString string];
         for (VNRecognizedTextObservation *observation in request.results) {
             VNRecognizedText *text = [observation topCandidates:1].firstObject;
-            if (text) {
+            if (text && text.confidence >= minConfidence) {
                 if (resultText.length > 0) {
                     [resultText appendString:@"\n"];
                 }
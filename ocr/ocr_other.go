@@ -2,8 +2,8 @@
 
 package ocr
 
-// RecognizeText performs OCR on the image at the given path.
-// It returns the recognized text or an error.
-func RecognizeText(imagePath string) (string, error) {
+// RecognizeTextWithLangs performs OCR on the image at the given path.
+// OCR is only available on macOS; elsewhere it returns no text.
+func RecognizeTextWithLangs(imagePath string, langs []string) (string, error) {
 	return "", nil
 }
@@ -0,0 +1,85 @@
+package ocr
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestVisionLanguages(t *testing.T) {
+	tests := []struct {
+		name  string
+		langs []string
+		want  []string
+	}{
+		{"none", nil, nil},
+		{"chinese covers both scripts", []string{"zh", "en"}, []string{"zh-Hans", "zh-Hant", "en-US"}},
+		{"order kept", []string{"ja", "ko"}, []string{"ja-JP", "ko-KR"}},
+		{"vision identifier passed through", []string{"zh-Hant", "zh"}, []string{"zh-Hant", "zh-Hans"}},
+		{"case and space", []string{" JA ", "ja"}, []string{"ja-JP"}},
+		{"empty dropped", []string{"", " "}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := visionLanguages(tt.langs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("visionLanguages(%q) = %q, want %q", tt.langs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSupported(t *testing.T) {
+	tests := []struct {
+		lang string
+		want bool
+	}{
+		{"zh", true},
+		{" JA ", true},
+		{"zh-Hant", true},
+		{"en-US", true},
+		{"zh-hant", false},
+		{"xx", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsSupported(tt.lang); got != tt.want {
+			t.Errorf("IsSupported(%q) = %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestRecognizeTextWithLangs(t *testing.T) {
+	if runtime.GOOS != "darwin" {
+		t.Skip("OCR requires macOS Vision")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	path := filepath.Join(t.TempDir(), "blank.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, langs := range [][]string{nil, {"ja", "en"}, {"zh-Hant"}, {"xx-unsupported"}} {
+		text, err := RecognizeTextWithLangs(path, langs)
+		if err != nil || text != "" {
+			t.Errorf("RecognizeTextWithLangs(blank, %q) = %q, %v; want \"\", nil", langs, text, err)
+		}
+	}
+
+	if _, err := RecognizeTextWithLangs(filepath.Join(t.TempDir(), "missing.png"), []string{"en"}); err == nil {
+		t.Error("RecognizeTextWithLangs(missing file) error = nil, want error")
+	}
+}
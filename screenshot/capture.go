@@ -0,0 +1,60 @@
+// Package screenshot captures screen regions for OCR.
+package screenshot
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// ErrCaptureCancelled is returned when the user dismisses the interactive
+// capture (e.g. presses Escape) without selecting a region.
+var ErrCaptureCancelled = errors.New("screenshot cancelled")
+
+// Region is a screen rectangle in points, with the origin at the top left
+// of the main display.
+type Region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Empty reports whether r has no area.
+func (r Region) Empty() bool {
+	return r.Width <= 0 || r.Height <= 0
+}
+
+// Overlaps reports whether r and o share any area. Touching edges do not
+// overlap.
+func (r Region) Overlaps(o Region) bool {
+	if r.Empty() || o.Empty() {
+		return false
+	}
+	return r.X < o.X+o.Width && o.X < r.X+r.Width &&
+		r.Y < o.Y+o.Height && o.Y < r.Y+r.Height
+}
+
+// captureTo runs the capture tool via run, asking it to write a PNG into dir.
+// It returns the image path, ErrCaptureCancelled if the tool exited without
+// writing a file, or the underlying error if the tool could not run. A tool
+// killed because its context was cancelled counts as dismissed.
+func captureTo(dir string, run func(path string) error) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("transy_screenshot_%d.png", time.Now().UnixNano()))
+
+	err := run(path)
+	if _, statErr := os.Stat(path); statErr == nil {
+		return path, nil
+	}
+
+	// A tool that started and exited without output was dismissed by the
+	// user; failing to start at all is a real error.
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return "", ErrCaptureCancelled
+	}
+	return "", fmt.Errorf("screencapture failed: %w", err)
+}
@@ -0,0 +1,67 @@
+package screenshot
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCaptureTo(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	if exitErr == nil {
+		t.Fatal("expected exit error from helper command")
+	}
+
+	tests := []struct {
+		name      string
+		run       func(path string) error
+		wantFile  bool
+		wantErr   error
+		wantOther bool
+	}{
+		{
+			name:     "captured",
+			run:      func(path string) error { return os.WriteFile(path, []byte("png"), 0o600) },
+			wantFile: true,
+		},
+		{
+			name:    "cancelled with clean exit",
+			run:     func(string) error { return nil },
+			wantErr: ErrCaptureCancelled,
+		},
+		{
+			name:    "cancelled with exit status",
+			run:     func(string) error { return exitErr },
+			wantErr: ErrCaptureCancelled,
+		},
+		{
+			name:      "tool missing",
+			run:       func(string) error { return exec.ErrNotFound },
+			wantOther: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := captureTo(t.TempDir(), tt.run)
+			switch {
+			case tt.wantFile:
+				if err != nil {
+					t.Fatalf("captureTo() error = %v", err)
+				}
+				if _, statErr := os.Stat(path); statErr != nil {
+					t.Errorf("captured file missing: %v", statErr)
+				}
+			case tt.wantOther:
+				if err == nil || errors.Is(err, ErrCaptureCancelled) {
+					t.Errorf("captureTo() error = %v, want a non-cancellation error", err)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) || path != "" {
+					t.Errorf("captureTo() = %q, %v; want %v", path, err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
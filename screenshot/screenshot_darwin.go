@@ -24,11 +24,10 @@ void requestScreenRecordingPermission() {
 */
 import "C"
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"time"
 )
 
 // HasPermission checks if the app has screen recording permission.
@@ -42,26 +41,25 @@ func RequestPermission() {
 }
 
 // CaptureInteractive launches the interactive screenshot tool and saves the image to a temp file.
-// Returns the path to the saved image file.
-func CaptureInteractive() (string, error) {
-	// Create a temporary file path
-	tmpDir := os.TempDir()
-	fileName := fmt.Sprintf("transy_screenshot_%d.png", time.Now().UnixNano())
-	filePath := filepath.Join(tmpDir, fileName)
-
-	// Command: screencapture -i <path>
-	// -i: capture interactively (selection)
-	// -x: do not play sound
-	cmd := exec.Command("screencapture", "-i", "-x", filePath)
-
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("screencapture failed: %w", err)
-	}
+// Returns the path to the saved image file, or ErrCaptureCancelled if the user dismissed it
+// or ctx was cancelled.
+func CaptureInteractive(ctx context.Context) (string, error) {
+	return captureTo(os.TempDir(), func(path string) error {
+		// -i: capture interactively (selection)
+		// -x: do not play sound
+		return exec.CommandContext(ctx, "screencapture", "-i", "-x", path).Run()
+	})
+}
 
-	// Check if file exists (user might have cancelled)
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return "", fmt.Errorf("screenshot cancelled or failed to save")
+// CaptureRegion captures region without user interaction and saves the
+// image to a temp file. Returns the path to the saved image file.
+func CaptureRegion(ctx context.Context, region Region) (string, error) {
+	if region.Empty() {
+		return "", fmt.Errorf("empty capture region")
 	}
-
-	return filePath, nil
+	return captureTo(os.TempDir(), func(path string) error {
+		// -R: capture the given rectangle
+		rect := fmt.Sprintf("%d,%d,%d,%d", region.X, region.Y, region.Width, region.Height)
+		return exec.CommandContext(ctx, "screencapture", "-x", "-R", rect, path).Run()
+	})
 }
@@ -2,6 +2,15 @@
 
 package screenshot
 
+import (
+	"context"
+	"errors"
+)
+
+// errUnsupported is returned by captures on platforms without a screenshot
+// implementation.
+var errUnsupported = errors.New("screen capture is not supported on this platform")
+
 // HasPermission checks if the app has screen recording permission.
 func HasPermission() bool {
 	return false
@@ -12,6 +21,12 @@ func RequestPermission() {}
 
 // CaptureInteractive launches the interactive screenshot tool and saves the image to a temp file.
 // Returns the path to the saved image file.
-func CaptureInteractive() (string, error) {
-	return "", nil
+func CaptureInteractive(ctx context.Context) (string, error) {
+	return "", errUnsupported
+}
+
+// CaptureRegion captures region without user interaction and saves the
+// image to a temp file. Returns the path to the saved image file.
+func CaptureRegion(ctx context.Context, region Region) (string, error) {
+	return "", errUnsupported
 }
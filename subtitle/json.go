@@ -0,0 +1,140 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// Transcript is the JSON export of a live session (transcript.json).
+type Transcript struct {
+	Session  Session   `json:"session"`
+	Segments []Segment `json:"segments"`
+}
+
+// Session is the metadata of an exported live session.
+type Session struct {
+	Provider string `json:"provider,omitempty"` // Live provider name
+	Model    string `json:"model,omitempty"`    // Transcription model
+	Start    int64  `json:"start,omitempty"`    // Unix milliseconds; 0 if unknown
+	Duration int64  `json:"duration"`           // Milliseconds
+}
+
+// Segment is one transcript of a JSON export. Times are as in
+// types.LiveTranscript.
+type Segment struct {
+	ID         string   `json:"id"`
+	SourceText string   `json:"sourceText"`
+	TargetText string   `json:"targetText"`
+	SourceLang string   `json:"sourceLang"`
+	TargetLang string   `json:"targetLang"`
+	StartTime  int64    `json:"startTime"`
+	EndTime    int64    `json:"endTime"`
+	Timestamp  int64    `json:"timestamp"`
+	IsFinal    bool     `json:"isFinal"`
+	Confidence *float64 `json:"confidence,omitempty"` // Set with Options.Confidence
+	Speaker    string   `json:"speaker,omitempty"`    // Diarization label, if any
+}
+
+// Validate reports whether seg is well formed: it needs an ID, and its
+// times may not be negative or end before they start. An EndTime of zero
+// means the end is unknown.
+func (seg Segment) Validate() error {
+	switch {
+	case seg.ID == "":
+		return fmt.Errorf("missing id")
+	case seg.StartTime < 0 || seg.EndTime < 0:
+		return fmt.Errorf("negative time")
+	case seg.EndTime != 0 && seg.EndTime < seg.StartTime:
+		return fmt.Errorf("ends before it starts")
+	case seg.Confidence != nil && (*seg.Confidence < 0 || *seg.Confidence > 1):
+		return fmt.Errorf("confidence %v outside 0-1", *seg.Confidence)
+	}
+	return nil
+}
+
+// WriteJSON writes transcripts and session metadata as an indented UTF-8
+// JSON document. Unlike the subtitle formats it keeps both texts and
+// languages of every segment, including untranslated ones, so ReadJSON can
+// restore them. A zero session Start or Duration is derived from the
+// transcripts. Only opts.Confidence applies.
+func WriteJSON(w io.Writer, session Session, transcripts []types.LiveTranscript, opts Options) error {
+	doc := Transcript{Session: session, Segments: make([]Segment, 0, len(transcripts))}
+	for _, t := range transcripts {
+		if doc.Session.Start == 0 {
+			doc.Session.Start = t.SessionStart
+		}
+		if session.Duration == 0 {
+			doc.Session.Duration = max(doc.Session.Duration, t.EndTime)
+		}
+		seg := Segment{
+			ID:         t.ID,
+			SourceText: t.SourceText,
+			TargetText: t.TargetText,
+			SourceLang: t.SourceLang,
+			TargetLang: t.TargetLang,
+			StartTime:  t.StartTime,
+			EndTime:    t.EndTime,
+			Timestamp:  t.Timestamp,
+			IsFinal:    t.IsFinal,
+			Speaker:    t.Speaker,
+		}
+		if opts.Confidence {
+			seg.Confidence = &t.Confidence
+		}
+		doc.Segments = append(doc.Segments, seg)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return nil
+}
+
+// ReadJSON parses a document written by WriteJSON, rejecting documents
+// without a segments array and segments that fail Segment.Validate.
+func ReadJSON(r io.Reader) (Session, []types.LiveTranscript, error) {
+	var doc Transcript
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return Session{}, nil, fmt.Errorf("read transcript: %w", err)
+	}
+	if doc.Segments == nil {
+		return Session{}, nil, fmt.Errorf("read transcript: missing segments")
+	}
+	seen := make(map[string]bool, len(doc.Segments))
+	for i, seg := range doc.Segments {
+		if err := seg.Validate(); err != nil {
+			return Session{}, nil, fmt.Errorf("read transcript: segment %d: %w", i, err)
+		}
+		if seen[seg.ID] {
+			return Session{}, nil, fmt.Errorf("read transcript: segment %d: duplicate id %q", i, seg.ID)
+		}
+		seen[seg.ID] = true
+	}
+
+	transcripts := make([]types.LiveTranscript, 0, len(doc.Segments))
+	for _, seg := range doc.Segments {
+		t := types.LiveTranscript{
+			ID:           seg.ID,
+			SourceText:   seg.SourceText,
+			TargetText:   seg.TargetText,
+			SourceLang:   seg.SourceLang,
+			TargetLang:   seg.TargetLang,
+			StartTime:    seg.StartTime,
+			EndTime:      seg.EndTime,
+			Timestamp:    seg.Timestamp,
+			IsFinal:      seg.IsFinal,
+			Speaker:      seg.Speaker,
+			SessionStart: doc.Session.Start,
+		}
+		if seg.Confidence != nil {
+			t.Confidence = *seg.Confidence
+		}
+		transcripts = append(transcripts, t)
+	}
+	return doc.Session, transcripts, nil
+}
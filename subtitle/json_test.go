@@ -0,0 +1,147 @@
+package subtitle
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+func TestWriteJSONConfidence(t *testing.T) {
+	transcripts := []types.LiveTranscript{
+		{ID: "1", SourceText: "你好", TargetText: "Hello", SourceLang: "zh", TargetLang: "en", EndTime: 1500, IsFinal: true, Confidence: 0.87},
+		{ID: "2", SourceText: "再见", SourceLang: "zh", TargetLang: "en", StartTime: 2000, Confidence: 0},
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantFields bool
+	}{
+		{"default omits confidence", Options{}, false},
+		{"confidence included", Options{Confidence: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, Session{}, transcripts, tt.opts); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+
+			var raw struct {
+				Segments []map[string]any `json:"segments"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+			}
+			if len(raw.Segments) != len(transcripts) {
+				t.Fatalf("got %d segments, want %d", len(raw.Segments), len(transcripts))
+			}
+			for i, seg := range raw.Segments {
+				c, ok := seg["confidence"]
+				if ok != tt.wantFields {
+					t.Fatalf("segment %d has confidence = %v, want present %v", i, ok, tt.wantFields)
+				}
+				// A zero confidence is still written when requested.
+				if ok && c != transcripts[i].Confidence {
+					t.Errorf("segment %d confidence = %v, want %v", i, c, transcripts[i].Confidence)
+				}
+			}
+			if got := raw.Segments[1]["targetText"]; got != "" {
+				t.Errorf("untranslated segment targetText = %v, want empty", got)
+			}
+		})
+	}
+}
+
+func TestWriteSRTIgnoresConfidence(t *testing.T) {
+	var plain, withConf bytes.Buffer
+	if err := WriteSRT(&plain, sample, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSRT(&withConf, sample, Options{Confidence: true}); err != nil {
+		t.Fatal(err)
+	}
+	if plain.String() != withConf.String() {
+		t.Errorf("SRT changed with Confidence:\n%q\nwant\n%q", withConf.String(), plain.String())
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	transcripts := []types.LiveTranscript{
+		{ID: "1", SourceText: "你好", TargetText: "Hello", SourceLang: "zh", TargetLang: "en", EndTime: 1500, Timestamp: 1_760_000_001_500, IsFinal: true, Confidence: 0.87, SessionStart: 1_760_000_000_000, Speaker: "A"},
+		{ID: "2", SourceText: "再见", SourceLang: "zh", TargetLang: "en", StartTime: 2000, EndTime: 3200, Timestamp: 1_760_000_003_200, IsFinal: true, Confidence: 0.5, SessionStart: 1_760_000_000_000},
+	}
+
+	tests := []struct {
+		name        string
+		session     Session
+		wantSession Session
+	}{
+		{
+			name:        "explicit metadata",
+			session:     Session{Provider: "openai", Model: "gpt-4o-transcribe", Start: 1_760_000_000_000, Duration: 60_000},
+			wantSession: Session{Provider: "openai", Model: "gpt-4o-transcribe", Start: 1_760_000_000_000, Duration: 60_000},
+		},
+		{
+			name:        "derived start and duration",
+			session:     Session{Provider: "openai"},
+			wantSession: Session{Provider: "openai", Start: 1_760_000_000_000, Duration: 3200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, tt.session, transcripts, Options{Confidence: true}); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+
+			session, got, err := ReadJSON(&buf)
+			if err != nil {
+				t.Fatalf("ReadJSON() error = %v", err)
+			}
+			if session != tt.wantSession {
+				t.Errorf("session = %+v, want %+v", session, tt.wantSession)
+			}
+			if !reflect.DeepEqual(got, transcripts) {
+				t.Errorf("transcripts = %+v\nwant %+v", got, transcripts)
+			}
+		})
+	}
+}
+
+func TestReadJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"srt", "1\n00:00:00,000 --> 00:00:01,000\nHello"},
+		{"missing segments", `{"session":{"duration":0}}`},
+		{"segments not an array", `{"segments":{}}`},
+		{"missing id", `{"segments":[{"sourceText":"hi"}]}`},
+		{"duplicate id", `{"segments":[{"id":"1"},{"id":"1"}]}`},
+		{"negative time", `{"segments":[{"id":"1","startTime":-5}]}`},
+		{"ends before start", `{"segments":[{"id":"1","startTime":2000,"endTime":1000}]}`},
+		{"confidence out of range", `{"segments":[{"id":"1","confidence":1.5}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ReadJSON(strings.NewReader(tt.data)); err == nil {
+				t.Errorf("ReadJSON(%s) accepted invalid input", tt.data)
+			}
+		})
+	}
+}
+
+func TestReadJSONEmptySession(t *testing.T) {
+	_, got, err := ReadJSON(strings.NewReader(`{"segments":[]}`))
+	if err != nil || len(got) != 0 {
+		t.Errorf("ReadJSON(empty) = %v, %v, want no segments", got, err)
+	}
+}
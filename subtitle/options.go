@@ -0,0 +1,117 @@
+// Package subtitle writes live translation transcripts as subtitle files or JSON.
+package subtitle
+
+import "fmt"
+
+// LineEnding selects the line terminator of the output file.
+type LineEnding string
+
+const (
+	LF   LineEnding = "lf"
+	CRLF LineEnding = "crlf"
+)
+
+// Encoding selects the character encoding of the output file.
+type Encoding string
+
+const (
+	UTF8    Encoding = "utf-8"
+	UTF16LE Encoding = "utf-16le"
+)
+
+// Text selects which transcript text appears in each cue.
+type Text string
+
+const (
+	TextSource Text = "source"
+	TextTarget Text = "target"
+	TextBoth   Text = "both"
+)
+
+// Order selects which line comes first in bilingual cues.
+type Order string
+
+const (
+	TargetFirst Order = "target-first"
+	SourceFirst Order = "source-first"
+)
+
+// Options controls how subtitles are written.
+// Empty fields take the values from DefaultOptions.
+type Options struct {
+	LineEnding LineEnding `json:"lineEnding,omitempty"`
+	Encoding   Encoding   `json:"encoding,omitempty"`
+	BOM        bool       `json:"bom,omitempty"` // Write a byte order mark
+	Text       Text       `json:"text,omitempty"`
+	Order      Order      `json:"order,omitempty"` // Only used when Text is TextBoth
+
+	// WallClock writes cue times as local time of day, aligned with external
+	// recordings, instead of offsets from the session start. Transcripts
+	// without a session start keep their offsets.
+	WallClock bool `json:"wallClock,omitempty"`
+
+	// Confidence adds each segment's recognition confidence to JSON output.
+	// SRT has no place for it and ignores it, staying standard-compliant.
+	// Only meaningful for providers that report real confidence.
+	Confidence bool `json:"confidence,omitempty"`
+}
+
+// DefaultOptions returns LF line endings, UTF-8 without BOM and bilingual
+// cues with the translation first.
+func DefaultOptions() Options {
+	return Options{
+		LineEnding: LF,
+		Encoding:   UTF8,
+		Text:       TextBoth,
+		Order:      TargetFirst,
+	}
+}
+
+// withDefaults fills empty fields from DefaultOptions.
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.LineEnding == "" {
+		o.LineEnding = d.LineEnding
+	}
+	if o.Encoding == "" {
+		o.Encoding = d.Encoding
+	}
+	if o.Text == "" {
+		o.Text = d.Text
+	}
+	if o.Order == "" {
+		o.Order = d.Order
+	}
+	return o
+}
+
+// Validate reports whether the options, after defaults, form a usable combination.
+func (o Options) Validate() error {
+	o = o.withDefaults()
+	switch o.LineEnding {
+	case LF, CRLF:
+	default:
+		return fmt.Errorf("invalid line ending: %q", o.LineEnding)
+	}
+	switch o.Encoding {
+	case UTF8:
+	case UTF16LE:
+		// Players cannot tell UTF-16 from UTF-8 without a BOM.
+		if !o.BOM {
+			return fmt.Errorf("%s output requires a BOM", o.Encoding)
+		}
+	default:
+		return fmt.Errorf("invalid encoding: %q", o.Encoding)
+	}
+	switch o.Text {
+	case TextSource, TextTarget, TextBoth:
+	default:
+		return fmt.Errorf("invalid text selection: %q", o.Text)
+	}
+	switch o.Order {
+	case TargetFirst, SourceFirst:
+	default:
+		return fmt.Errorf("invalid order: %q", o.Order)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Subtitle file formats recognized by Parse.
+const (
+	FormatSRT = "srt"
+	FormatVTT = "vtt"
+)
+
+// Cue is a block of a parsed subtitle file. Timing and identifiers are kept
+// verbatim so the file can be written back with only the text changed.
+// Blocks without timing, such as the WebVTT header and NOTE or STYLE
+// blocks, hold their raw lines in Text and are written back unchanged.
+type Cue struct {
+	ID     string // SRT index or optional WebVTT identifier
+	Timing string // "start --> end" line, including WebVTT cue settings
+	Text   string // Lines joined by "\n"
+}
+
+// IsCue reports whether c is a timed cue rather than a verbatim block.
+func (c Cue) IsCue() bool {
+	return c.Timing != ""
+}
+
+// File is a parsed SRT or WebVTT file.
+type File struct {
+	Format string
+	Cues   []Cue
+}
+
+// Parse reads an SRT or WebVTT file. A leading BOM and CRLF line endings
+// are accepted.
+func Parse(data []byte) (*File, error) {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	f := &File{Format: FormatSRT}
+	if strings.HasPrefix(text, "WEBVTT") {
+		f.Format = FormatVTT
+	}
+
+	for i, block := range strings.Split(strings.Trim(text, "\n"), "\n\n") {
+		block = strings.Trim(block, "\n")
+		if block == "" {
+			continue
+		}
+		lines := strings.Split(block, "\n")
+
+		timing := -1
+		for j, l := range lines[:min(len(lines), 2)] {
+			if strings.Contains(l, "-->") {
+				timing = j
+				break
+			}
+		}
+		if timing < 0 {
+			if f.Format == FormatSRT {
+				return nil, fmt.Errorf("parse subtitles: block %d has no timing line", i+1)
+			}
+			f.Cues = append(f.Cues, Cue{Text: block})
+			continue
+		}
+
+		cue := Cue{Timing: lines[timing], Text: strings.Join(lines[timing+1:], "\n")}
+		if timing == 1 {
+			cue.ID = lines[0]
+		}
+		f.Cues = append(f.Cues, cue)
+	}
+
+	if !hasCue(f.Cues) {
+		return nil, fmt.Errorf("parse subtitles: no cues found")
+	}
+	return f, nil
+}
+
+func hasCue(cues []Cue) bool {
+	for _, c := range cues {
+		if c.IsCue() {
+			return true
+		}
+	}
+	return false
+}
+
+// Bytes writes f back in its format with LF line endings.
+func (f *File) Bytes() []byte {
+	var b strings.Builder
+	for _, c := range f.Cues {
+		if !c.IsCue() {
+			b.WriteString(c.Text + "\n\n")
+			continue
+		}
+		if c.ID != "" {
+			b.WriteString(c.ID + "\n")
+		}
+		b.WriteString(c.Timing + "\n")
+		if c.Text != "" {
+			b.WriteString(c.Text + "\n")
+		}
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,69 @@
+package subtitle
+
+import (
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format string
+		cues   int // Timed cues
+		want   string
+	}{
+		{
+			name:   "srt",
+			input:  "1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:03,000 --> 00:00:04,500\n<i>Two</i>\nlines\n\n",
+			format: FormatSRT,
+			cues:   2,
+			want:   "1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:03,000 --> 00:00:04,500\n<i>Two</i>\nlines\n\n",
+		},
+		{
+			name:   "srt with bom and crlf",
+			input:  "\xEF\xBB\xBF1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\n",
+			format: FormatSRT,
+			cues:   1,
+			want:   "1\n00:00:01,000 --> 00:00:02,000\nHello\n\n",
+		},
+		{
+			name:   "vtt with header, note and settings",
+			input:  "WEBVTT - sample\n\nNOTE kept as is\n\nintro\n00:01.000 --> 00:02.000 align:start\nHello\n\n00:03.000 --> 00:04.000\nBye\n",
+			format: FormatVTT,
+			cues:   2,
+			want:   "WEBVTT - sample\n\nNOTE kept as is\n\nintro\n00:01.000 --> 00:02.000 align:start\nHello\n\n00:03.000 --> 00:04.000\nBye\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if f.Format != tt.format {
+				t.Errorf("Format = %q, want %q", f.Format, tt.format)
+			}
+			n := 0
+			for _, c := range f.Cues {
+				if c.IsCue() {
+					n++
+				}
+			}
+			if n != tt.cues {
+				t.Errorf("got %d cues, want %d", n, tt.cues)
+			}
+			if got := string(f.Bytes()); got != tt.want {
+				t.Errorf("Bytes() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{"", "just some text", "1\nHello\n\n"} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) accepted invalid input", input)
+		}
+	}
+}
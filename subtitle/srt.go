@@ -0,0 +1,154 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// minCueDuration is used for cues whose end time is missing or not after
+// their start, e.g. segments still in progress when the session stopped.
+const minCueDuration = 2000 // ms
+
+// WriteSRT writes transcripts as SubRip (.srt) cues. Transcripts with no
+// text for the selected Text option are skipped; cues are numbered from 1.
+func WriteSRT(w io.Writer, transcripts []types.LiveTranscript, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("subtitle options: %w", err)
+	}
+	opts = opts.withDefaults()
+
+	var buf bytes.Buffer
+	for i, c := range buildCues(transcripts, opts) {
+		fmt.Fprintf(&buf, "%d\n%s --> %s\n%s\n\n", i+1, srtTime(c.start), srtTime(c.end), c.text)
+	}
+
+	return encode(w, buf.String(), opts)
+}
+
+// cue is a subtitle cue with times in milliseconds.
+type cue struct {
+	start, end int64
+	text       string
+}
+
+// buildCues returns the cues for transcripts, skipping those with no text
+// for opts. A cue that runs into the next one is cut short at its start,
+// so players don't stack them, unless that would leave it no duration.
+func buildCues(transcripts []types.LiveTranscript, opts Options) []cue {
+	var cues []cue
+	for _, t := range transcripts {
+		text := cueText(t, opts)
+		if text == "" {
+			continue
+		}
+		start, end := cueTiming(t, opts.WallClock)
+		if n := len(cues); n > 0 {
+			if prev := &cues[n-1]; prev.end > start && start > prev.start {
+				prev.end = start
+			}
+		}
+		cues = append(cues, cue{start: start, end: end, text: text})
+	}
+	return cues
+}
+
+// cueText returns the lines for t selected by opts, joined by "\n".
+func cueText(t types.LiveTranscript, opts Options) string {
+	source := normalizeNewlines(t.SourceText)
+	target := normalizeNewlines(t.TargetText)
+
+	var lines []string
+	switch opts.Text {
+	case TextSource:
+		lines = []string{source}
+	case TextTarget:
+		lines = []string{target}
+	default:
+		if opts.Order == SourceFirst {
+			lines = []string{source, target}
+		} else {
+			lines = []string{target, source}
+		}
+	}
+
+	nonEmpty := lines[:0]
+	for _, l := range lines {
+		if l != "" {
+			nonEmpty = append(nonEmpty, l)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+// cueTiming returns the start and end of t in milliseconds, from the
+// session start or, with wallClock, from local midnight of that day.
+func cueTiming(t types.LiveTranscript, wallClock bool) (start, end int64) {
+	start, end = max(t.StartTime, 0), t.EndTime
+	if end <= start {
+		end = start + minCueDuration
+	}
+	if wallClock && t.SessionStart != 0 {
+		base := t.WallTime(0).Local()
+		midnight := time.Date(base.Year(), base.Month(), base.Day(), 0, 0, 0, 0, base.Location())
+		offset := base.Sub(midnight).Milliseconds()
+		start, end = start+offset, end+offset
+	}
+	return start, end
+}
+
+// srtTime formats ms as HH:MM:SS,mmm.
+func srtTime(ms int64) string {
+	h := ms / 3_600_000
+	m := ms / 60_000 % 60
+	s := ms / 1000 % 60
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", h, m, s, ms%1000)
+}
+
+// normalizeNewlines trims text and converts any line endings to "\n",
+// dropping blank lines that would otherwise end the cue early.
+func normalizeNewlines(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if l = strings.TrimSpace(l); l != "" {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// encode writes s to w with the line ending, encoding and BOM in opts.
+func encode(w io.Writer, s string, opts Options) error {
+	if opts.LineEnding == CRLF {
+		s = strings.ReplaceAll(s, "\n", "\r\n")
+	}
+
+	var out []byte
+	switch opts.Encoding {
+	case UTF16LE:
+		enc := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+		b, err := enc.Bytes([]byte(s))
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", opts.Encoding, err)
+		}
+		out = b
+	default:
+		if opts.BOM {
+			out = append(out, 0xEF, 0xBB, 0xBF)
+		}
+		out = append(out, s...)
+	}
+
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("write subtitles: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package subtitle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.aimuz.me/transy/internal/types"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var sample = []types.LiveTranscript{
+	{SourceText: "你好", TargetText: "Hello", StartTime: 0, EndTime: 1500},
+	{SourceText: "再见", TargetText: "", StartTime: 3_661_001, EndTime: 0},
+}
+
+// liveSession covers the awkward cases of a real session: a segment
+// overlapping the next, one with no end time and one with no translation.
+var liveSession = []types.LiveTranscript{
+	{SourceText: "Good morning, everyone.", TargetText: "大家早上好。", StartTime: 500, EndTime: 2_800},
+	{SourceText: "Today: Q3 results & <plans>.", TargetText: "今天：第三季度业绩与计划。", StartTime: 2_400, EndTime: 5_100},
+	{SourceText: "Let's begin", TargetText: "我们开始吧", StartTime: 6_000, EndTime: 0},
+	{SourceText: "Any questions?", StartTime: 65_250, EndTime: 67_000},
+}
+
+// checkGolden compares got with the file testdata/name.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	want, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("output =\n%s\nwant (%s)\n%s", got, name, want)
+	}
+}
+
+func TestWriteSRTGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSRT(&buf, liveSession, Options{Order: SourceFirst}); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+	checkGolden(t, "live.srt", buf.String())
+}
+
+func TestWriteSRT(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "defaults",
+			opts: Options{},
+			want: "1\n00:00:00,000 --> 00:00:01,500\nHello\n你好\n\n" +
+				"2\n01:01:01,001 --> 01:01:03,001\n再见\n\n",
+		},
+		{
+			name: "source first",
+			opts: Options{Order: SourceFirst},
+			want: "1\n00:00:00,000 --> 00:00:01,500\n你好\nHello\n\n" +
+				"2\n01:01:01,001 --> 01:01:03,001\n再见\n\n",
+		},
+		{
+			name: "target only skips untranslated",
+			opts: Options{Text: TextTarget},
+			want: "1\n00:00:00,000 --> 00:00:01,500\nHello\n\n",
+		},
+		{
+			name: "source only",
+			opts: Options{Text: TextSource},
+			want: "1\n00:00:00,000 --> 00:00:01,500\n你好\n\n" +
+				"2\n01:01:01,001 --> 01:01:03,001\n再见\n\n",
+		},
+		{
+			name: "crlf",
+			opts: Options{LineEnding: CRLF, Text: TextTarget},
+			want: "1\r\n00:00:00,000 --> 00:00:01,500\r\nHello\r\n\r\n",
+		},
+		{
+			name: "utf-8 bom",
+			opts: Options{BOM: true, Text: TextTarget},
+			want: "\xEF\xBB\xBF1\n00:00:00,000 --> 00:00:01,500\nHello\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSRT(&buf, sample, tt.opts); err != nil {
+				t.Fatalf("WriteSRT() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteSRT() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSRTUTF16(t *testing.T) {
+	var buf bytes.Buffer
+	opts := Options{Encoding: UTF16LE, BOM: true, LineEnding: CRLF, Text: TextTarget}
+	if err := WriteSRT(&buf, sample, opts); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+
+	got := buf.Bytes()
+	if !bytes.HasPrefix(got, []byte{0xFF, 0xFE}) {
+		t.Fatalf("missing UTF-16LE BOM: % x", got[:min(len(got), 4)])
+	}
+	dec := unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()
+	text, err := dec.Bytes(got)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := "1\r\n00:00:00,000 --> 00:00:01,500\r\nHello\r\n\r\n"; string(text) != want {
+		t.Errorf("decoded = %q, want %q", text, want)
+	}
+}
+
+func TestWriteSRTMultilineText(t *testing.T) {
+	in := []types.LiveTranscript{{SourceText: "line one\r\n\r\nline two ", StartTime: 100, EndTime: 50}}
+
+	var buf bytes.Buffer
+	if err := WriteSRT(&buf, in, Options{Text: TextSource}); err != nil {
+		t.Fatalf("WriteSRT() error = %v", err)
+	}
+	want := "1\n00:00:00,100 --> 00:00:02,100\nline one\nline two\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteSRT() = %q, want %q", got, want)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"zero value", Options{}, false},
+		{"defaults", DefaultOptions(), false},
+		{"utf-16 with bom", Options{Encoding: UTF16LE, BOM: true}, false},
+		{"utf-16 without bom", Options{Encoding: UTF16LE}, true},
+		{"bad line ending", Options{LineEnding: "cr"}, true},
+		{"bad encoding", Options{Encoding: "latin1"}, true},
+		{"bad text", Options{Text: "neither"}, true},
+		{"bad order", Options{Order: "random"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := WriteSRT(&bytes.Buffer{}, sample, Options{Encoding: UTF16LE}); err == nil {
+		t.Error("WriteSRT() with invalid options: want error")
+	}
+}
+
+func TestCueTimingWallClock(t *testing.T) {
+	start := time.Date(2025, 3, 14, 9, 30, 0, 0, time.Local)
+	tr := types.LiveTranscript{StartTime: 1500, EndTime: 4000, SessionStart: start.UnixMilli()}
+
+	if got := tr.WallTime(tr.StartTime); !got.Equal(start.Add(1500 * time.Millisecond)) {
+		t.Errorf("WallTime(StartTime) = %v, want %v", got, start.Add(1500*time.Millisecond))
+	}
+	if got := (types.LiveTranscript{}).WallTime(0); !got.IsZero() {
+		t.Errorf("WallTime() without session start = %v, want zero", got)
+	}
+
+	tests := []struct {
+		name      string
+		tr        types.LiveTranscript
+		wallClock bool
+		wantStart string
+		wantEnd   string
+	}{
+		{"relative", tr, false, "00:00:01,500", "00:00:04,000"},
+		{"wall clock", tr, true, "09:30:01,500", "09:30:04,000"},
+		{"wall clock without session start", types.LiveTranscript{StartTime: 1500, EndTime: 4000}, true, "00:00:01,500", "00:00:04,000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, e := cueTiming(tt.tr, tt.wallClock)
+			if srtTime(s) != tt.wantStart || srtTime(e) != tt.wantEnd {
+				t.Errorf("cueTiming() = %s --> %s, want %s --> %s", srtTime(s), srtTime(e), tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
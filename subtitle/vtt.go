@@ -0,0 +1,40 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.aimuz.me/transy/internal/types"
+)
+
+// WriteVTT writes transcripts as WebVTT (.vtt) cues, with the same cue
+// selection and timing as WriteSRT. WebVTT files are always UTF-8, so
+// UTF-16 output is rejected.
+func WriteVTT(w io.Writer, transcripts []types.LiveTranscript, opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("subtitle options: %w", err)
+	}
+	opts = opts.withDefaults()
+	if opts.Encoding != UTF8 {
+		return fmt.Errorf("WebVTT requires %s, not %s", UTF8, opts.Encoding)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n\n")
+	for _, c := range buildCues(transcripts, opts) {
+		fmt.Fprintf(&buf, "%s --> %s\n%s\n\n", vttTime(c.start), vttTime(c.end), escapeVTT(c.text))
+	}
+
+	return encode(w, buf.String(), opts)
+}
+
+// vttTime formats ms as HH:MM:SS.mmm.
+func vttTime(ms int64) string {
+	return strings.Replace(srtTime(ms), ",", ".", 1)
+}
+
+// escapeVTT escapes the characters WebVTT cue text reserves for markup.
+// "-->" can't occur once ">" is escaped.
+var escapeVTT = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
@@ -0,0 +1,21 @@
+package subtitle
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteVTTGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVTT(&buf, liveSession, Options{Order: SourceFirst}); err != nil {
+		t.Fatalf("WriteVTT() error = %v", err)
+	}
+	checkGolden(t, "live.vtt", buf.String())
+}
+
+func TestWriteVTTRejectsUTF16(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteVTT(&buf, liveSession, Options{Encoding: UTF16LE, BOM: true}); err == nil {
+		t.Error("WriteVTT() with UTF-16 succeeded, want error")
+	}
+}
@@ -0,0 +1,101 @@
+// Package transport builds the HTTP transports used for outbound API calls
+// so every client honors the same proxy setting.
+//
+// The proxy is the one set with SetProxy, or else HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY from the environment. Loopback hosts, such as local models,
+// are never proxied.
+//
+// Live translation media is WebRTC over UDP, which an HTTP proxy cannot
+// carry. Only its session setup and SDP exchange go through the proxy; the
+// media still needs direct UDP to OpenAI.
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+var (
+	mu      sync.RWMutex
+	proxyFn = environment() // Chooses the proxy per request URL
+)
+
+// ParseProxy validates a proxy URL such as "http://proxy.corp:3128" or
+// "socks5://127.0.0.1:1080". An empty string is valid and means no
+// configured proxy.
+func ParseProxy(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy url %q: scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q: host required", raw)
+	}
+	return u, nil
+}
+
+// SetProxy sets the proxy for all outbound requests. An empty raw restores
+// the environment's proxy settings. NO_PROXY still applies either way.
+// Connections already open keep their route until they go idle.
+func SetProxy(raw string) error {
+	u, err := ParseProxy(raw)
+	if err != nil {
+		return err
+	}
+	fn := environment()
+	if u != nil {
+		fn = configured(u.String())
+	}
+	mu.Lock()
+	proxyFn = fn
+	mu.Unlock()
+	return nil
+}
+
+// Proxy returns the proxy for req, or nil for a direct connection. It is
+// meant for http.Transport.Proxy.
+func Proxy(req *http.Request) (*url.URL, error) {
+	if req.URL == nil {
+		return nil, errors.New("transport: request has no url")
+	}
+	mu.RLock()
+	fn := proxyFn
+	mu.RUnlock()
+	return fn(req.URL)
+}
+
+// New returns a clone of the default transport that routes through Proxy.
+func New() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = Proxy
+	return t
+}
+
+// environment reads the proxy settings from the environment.
+func environment() func(*url.URL) (*url.URL, error) {
+	return httpproxy.FromEnvironment().ProxyFunc()
+}
+
+// configured routes both http and https through proxy, keeping NO_PROXY
+// from the environment.
+func configured(proxy string) func(*url.URL) (*url.URL, error) {
+	env := httpproxy.FromEnvironment()
+	return (&httpproxy.Config{
+		HTTPProxy:  proxy,
+		HTTPSProxy: proxy,
+		NoProxy:    env.NoProxy,
+	}).ProxyFunc()
+}